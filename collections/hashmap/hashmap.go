@@ -70,9 +70,9 @@ func (m HashMap[K, V]) ValuesMut() iter.Iter[*V] {
 func (m HashMap[K, V]) Iter() iter.Iter[Pair[*K, *V]] {
 	values := make([]Pair[*K, *V], 0, len(m.keys))
 	for i, k := range m.keys {
-		k := &k
-		v := m.values[i]
-		values = append(values, Pair[*K, *V]{Key: k, Value: v.OrDefault()})
+		// every pair gets its own copy of the key, writing through it mustn't break the order of the keys
+		key := k
+		values = append(values, Pair[*K, *V]{Key: &key, Value: m.values[i].OrDefault()})
 	}
 	return iter.New(values)
 }
@@ -128,25 +128,27 @@ func (m *HashMap[K, V]) Entry(key K) *Entry[K, V] {
 }
 
 func (m HashMap[K, V]) Get(k K) shepard.Option[*V] {
-	e := m.Entry(k)
-	if e.IsOccupied() {
-		return shepard.Some(e.Value())
+	i, ok := m.keyIndex(k)
+	if ok && m.values[i].IsOccupied() {
+		return shepard.Some(m.values[i].Value())
 	}
 	return shepard.None[*V]()
 }
 
 // GetKeyValue returns the key-value Pair[*K, *V] corresponding to the supplied key.
 func (m HashMap[K, V]) GetKeyValue(k K) shepard.Option[Pair[*K, *V]] {
-	e := m.Entry(k)
-	if e.IsOccupied() {
-		return shepard.Some(Pair[*K, *V]{Key: e.Key(), Value: e.Value()})
+	i, ok := m.keyIndex(k)
+	if ok && m.values[i].IsOccupied() {
+		// the key is copied, writing through the pointer mustn't break the order of the keys
+		key := m.keys[i]
+		return shepard.Some(Pair[*K, *V]{Key: &key, Value: m.values[i].Value()})
 	}
 	return shepard.None[Pair[*K, *V]]()
 }
 
 // ContainsKey returns true if the map contains a value for the specified key.
 func (m HashMap[K, V]) ContainsKey(k K) bool {
-	return m.Entry(k).IsOccupied()
+	return m.Get(k).IsSome()
 }
 
 // insertEntry inserts an entry into the map and sorts the keys in ascending order.
@@ -51,6 +51,17 @@ func TestHashMap_Iter(t *testing.T) {
 		iter.Map[hashmap.Pair[*string, *int], int](iter.New[hashmap.Pair[*string, *int]](expected), func(v hashmap.Pair[*string, *int]) int { return *v.Value }),
 		iter.Map[hashmap.Pair[*string, *int], int](m.Iter(), func(v hashmap.Pair[*string, *int]) int { return *v.Value }),
 	)
+	assert.EqualValues(
+		t,
+		iter.New([]string{"a", "b", "c"}),
+		iter.Map[hashmap.Pair[*string, *int], string](m.Iter(), func(v hashmap.Pair[*string, *int]) string { return *v.Key }),
+	)
+
+	// the keys are copies
+	m.Iter().Foreach(func(_ int, p hashmap.Pair[*string, *int]) {
+		*p.Key = "z"
+	})
+	assert.Equal(t, 2, *m.Get("b").Unwrap())
 }
 
 func TestHashMap_Len(t *testing.T) {
@@ -105,6 +116,10 @@ func TestHashMap_GetKeyValue(t *testing.T) {
 	expectedValue := "a"
 	assert.True(t, m.GetKeyValue(1).Equal(shepard.Some[hashmap.Pair[*int, *string]](hashmap.Pair[*int, *string]{&expectedKey, &expectedValue})))
 	assert.True(t, m.GetKeyValue(2).Equal(shepard.None[hashmap.Pair[*int, *string]]()))
+
+	// the key is a copy
+	*m.GetKeyValue(1).Unwrap().Key = 5
+	assert.Equal(t, "a", *m.Get(1).Unwrap())
 }
 
 func TestHashMap_Insert(t *testing.T) {
@@ -137,6 +152,7 @@ func TestHashMap_ContainsKey(t *testing.T) {
 	m.Insert(1, "a")
 	assert.True(t, m.ContainsKey(1))
 	assert.False(t, m.ContainsKey(2))
+	assert.Equal(t, 1, m.Len())
 }
 
 func BenchmarkHashMap_Get(b *testing.B) {
@@ -0,0 +1,89 @@
+package future
+
+import (
+	"github.com/marlaone/shepard"
+)
+
+// Pair holds the values of two joined Future`s.
+type Pair[T any, U any] struct {
+	First  T
+	Second U
+}
+
+// settled sends the index of every Future in futures to the returned channel as soon as it settled.
+func settled[T any, E any](futures []*Future[T, E]) <-chan int {
+	ch := make(chan int, len(futures))
+	for i, f := range futures {
+		go func(i int, f *Future[T, E]) {
+			<-f.done
+			ch <- i
+		}(i, f)
+	}
+	return ch
+}
+
+func cancellers[T any, E any](futures []*Future[T, E]) []canceller {
+	c := make([]canceller, 0, len(futures))
+	for _, f := range futures {
+		c = append(c, f)
+	}
+	return c
+}
+
+// All resolves to the shepard.Ok values of all futures, in the order they were passed, once every Future resolved to shepard.Ok.
+//
+// If any Future resolves to shepard.Err, All resolves to the first error and cancels the remaining futures.
+// An empty list of futures resolves to an empty slice immediately.
+func All[T any, E any](futures ...*Future[T, E]) *Future[[]T, E] {
+	fut := newFuture[[]T, E](cancellers(futures)...)
+	if len(futures) == 0 {
+		fut.resolve(shepard.Ok[[]T, E]([]T{}))
+		return fut
+	}
+	go func() {
+		values := make([]T, len(futures))
+		ch := settled(futures)
+		for range futures {
+			i := <-ch
+			res := futures[i].result
+			if res.IsErr() {
+				fut.resolve(shepard.Err[[]T, E](res.UnwrapErr()))
+				return
+			}
+			values[i] = res.Unwrap()
+		}
+		fut.resolve(shepard.Ok[[]T, E](values))
+	}()
+	return fut
+}
+
+// Join waits for two futures of different types and resolves to a Pair of their shepard.Ok values.
+//
+// If either Future resolves to shepard.Err, Join resolves to the first error and cancels the other Future.
+func Join[T any, U any, E any](a *Future[T, E], b *Future[U, E]) *Future[Pair[T, U], E] {
+	fut := newFuture[Pair[T, U], E](a, b)
+	go func() {
+		var pair Pair[T, U]
+		aDone, bDone := a.Done(), b.Done()
+		for aDone != nil || bDone != nil {
+			select {
+			case <-aDone:
+				aDone = nil
+				if a.result.IsErr() {
+					fut.resolve(shepard.Err[Pair[T, U], E](a.result.UnwrapErr()))
+					return
+				}
+				pair.First = a.result.Unwrap()
+			case <-bDone:
+				bDone = nil
+				if b.result.IsErr() {
+					fut.resolve(shepard.Err[Pair[T, U], E](b.result.UnwrapErr()))
+					return
+				}
+				pair.Second = b.result.Unwrap()
+			}
+		}
+		fut.resolve(shepard.Ok[Pair[T, U], E](pair))
+	}()
+	return fut
+}
@@ -0,0 +1,46 @@
+package future_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/future"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	f := future.All(
+		after(30*time.Millisecond, shepard.Ok[int, error](1)),
+		after(10*time.Millisecond, shepard.Ok[int, error](2)),
+		future.Ok[int, error](3),
+	)
+	assert.Equal(t, []int{1, 2, 3}, f.Await().Unwrap())
+
+	assert.Equal(t, []int{}, future.All[int, error]().Await().Unwrap())
+}
+
+func TestAll_Err(t *testing.T) {
+	err := errors.New("failed")
+	slow := after(time.Second, shepard.Ok[int, error](1))
+	f := future.All(slow, after(time.Millisecond, shepard.Err[int, error](err)))
+
+	assert.Equal(t, err, f.Await().UnwrapErr())
+	assert.ErrorIs(t, slow.Await().UnwrapErr(), context.Canceled)
+}
+
+func TestJoin(t *testing.T) {
+	f := future.Join(
+		after(10*time.Millisecond, shepard.Ok[int, error](1)),
+		future.Ok[string, error]("one"),
+	)
+	assert.Equal(t, future.Pair[int, string]{First: 1, Second: "one"}, f.Await().Unwrap())
+
+	err := errors.New("failed")
+	slow := after(time.Second, shepard.Ok[string, error]("one"))
+	f2 := future.Join(future.Err[int, error](err), slow)
+	assert.Equal(t, err, f2.Await().UnwrapErr())
+	assert.ErrorIs(t, slow.Await().UnwrapErr(), context.Canceled)
+}
@@ -0,0 +1,125 @@
+package future
+
+import (
+	"context"
+
+	"github.com/marlaone/shepard"
+)
+
+type SpawnFunc[T any, E any] func(ctx context.Context) shepard.Result[T, E]
+
+type canceller interface {
+	Cancel()
+}
+
+// Future is a shepard.Result[T, E] that is computed asynchronously and becomes available once the computation settles.
+type Future[T any, E any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	result shepard.Result[T, E]
+}
+
+// newFuture creates an unresolved Future which cancels the given sources as soon as it settles or gets cancelled.
+func newFuture[T any, E any](sources ...canceller) *Future[T, E] {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Future[T, E]{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	if len(sources) > 0 {
+		go func() {
+			<-ctx.Done()
+			for _, s := range sources {
+				s.Cancel()
+			}
+		}()
+	}
+	return f
+}
+
+// Spawn runs f on a new goroutine and returns a Future resolving to its shepard.Result.
+//
+// The context passed to f is derived from ctx and is cancelled when ctx is cancelled, when Cancel is called or when f returns.
+// f is responsible for honouring the cancellation, the Future only settles once f returns.
+func Spawn[T any, E any](ctx context.Context, f SpawnFunc[T, E]) *Future[T, E] {
+	ctx, cancel := context.WithCancel(ctx)
+	fut := &Future[T, E]{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		fut.resolve(f(ctx))
+	}()
+	return fut
+}
+
+// Ready returns an already settled Future containing res.
+func Ready[T any, E any](res shepard.Result[T, E]) *Future[T, E] {
+	f := newFuture[T, E]()
+	f.resolve(res)
+	return f
+}
+
+// Ok returns an already settled Future containing the success value val.
+func Ok[T any, E any](val T) *Future[T, E] {
+	return Ready(shepard.Ok[T, E](val))
+}
+
+// Err returns an already settled Future containing the error value err.
+func Err[T any, E any](err E) *Future[T, E] {
+	return Ready(shepard.Err[T, E](err))
+}
+
+func (f *Future[T, E]) resolve(res shepard.Result[T, E]) {
+	f.result = res
+	close(f.done)
+	f.cancel()
+}
+
+// Await blocks until the Future has settled and returns its shepard.Result.
+func (f *Future[T, E]) Await() shepard.Result[T, E] {
+	<-f.done
+	return f.result
+}
+
+// AwaitContext blocks until the Future has settled or ctx is done.
+//
+// Returns shepard.None if ctx is done before the Future has settled. The Future itself is not cancelled.
+func (f *Future[T, E]) AwaitContext(ctx context.Context) shepard.Option[shepard.Result[T, E]] {
+	select {
+	case <-f.done:
+		return shepard.Some(f.result)
+	case <-ctx.Done():
+		return shepard.None[shepard.Result[T, E]]()
+	}
+}
+
+// Poll returns the shepard.Result of the Future without blocking, or shepard.None if it has not settled yet.
+func (f *Future[T, E]) Poll() shepard.Option[shepard.Result[T, E]] {
+	select {
+	case <-f.done:
+		return shepard.Some(f.result)
+	default:
+		return shepard.None[shepard.Result[T, E]]()
+	}
+}
+
+// IsReady returns true if the Future has settled.
+func (f *Future[T, E]) IsReady() bool {
+	return f.Poll().IsSome()
+}
+
+// Done returns a channel that is closed once the Future has settled.
+func (f *Future[T, E]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel cancels the context of the computation behind the Future.
+//
+// Calling Cancel on a settled Future has no effect.
+func (f *Future[T, E]) Cancel() {
+	f.cancel()
+}
@@ -0,0 +1,80 @@
+package future_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/future"
+	"github.com/stretchr/testify/assert"
+)
+
+// after returns a Future resolving to res after d, or to the context error if cancelled before.
+func after[T any](d time.Duration, res shepard.Result[T, error]) *future.Future[T, error] {
+	return future.Spawn(context.Background(), func(ctx context.Context) shepard.Result[T, error] {
+		select {
+		case <-time.After(d):
+			return res
+		case <-ctx.Done():
+			return shepard.Err[T, error](ctx.Err())
+		}
+	})
+}
+
+func TestSpawn(t *testing.T) {
+	f := future.Spawn(context.Background(), func(ctx context.Context) shepard.Result[int, error] {
+		return shepard.Ok[int, error](42)
+	})
+	assert.True(t, f.Await().Equal(shepard.Ok[int, error](42)))
+	assert.True(t, f.IsReady())
+}
+
+func TestSpawn_ParentContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := future.Spawn(ctx, func(ctx context.Context) shepard.Result[int, error] {
+		<-ctx.Done()
+		return shepard.Err[int, error](ctx.Err())
+	})
+	cancel()
+	assert.ErrorIs(t, f.Await().UnwrapErr(), context.Canceled)
+}
+
+func TestFuture_Cancel(t *testing.T) {
+	f := after(time.Second, shepard.Ok[int, error](1))
+	f.Cancel()
+	assert.ErrorIs(t, f.Await().UnwrapErr(), context.Canceled)
+}
+
+func TestFuture_Poll(t *testing.T) {
+	release := make(chan struct{})
+	f := future.Spawn(context.Background(), func(ctx context.Context) shepard.Result[int, error] {
+		<-release
+		return shepard.Ok[int, error](1)
+	})
+	assert.True(t, f.Poll().IsNone())
+	close(release)
+	f.Await()
+	assert.True(t, f.Poll().Equal(shepard.Some(shepard.Ok[int, error](1))))
+}
+
+func TestFuture_AwaitContext(t *testing.T) {
+	f := after(time.Second, shepard.Ok[int, error](1))
+	defer f.Cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.True(t, f.AwaitContext(ctx).IsNone())
+	assert.False(t, f.IsReady())
+
+	assert.True(t, future.Ok[int, error](2).AwaitContext(context.Background()).IsSome())
+}
+
+func TestReady(t *testing.T) {
+	assert.True(t, future.Ok[int, error](1).Await().Equal(shepard.Ok[int, error](1)))
+
+	err := errors.New("failed")
+	assert.Equal(t, err, future.Err[int, error](err).Await().UnwrapErr())
+}
@@ -0,0 +1,47 @@
+package future
+
+import (
+	"github.com/marlaone/shepard"
+)
+
+type MapFunc[T any, U any] func(value T) U
+type ThenFunc[T any, U any, E any] func(value T) *Future[U, E]
+
+// Map maps a Future[T, E] to Future[U, E] by applying a function to a contained shepard.Ok value, leaving a shepard.Err value untouched.
+//
+// Cancelling the returned Future cancels f.
+func Map[T any, U any, E any](f *Future[T, E], op MapFunc[T, U]) *Future[U, E] {
+	fut := newFuture[U, E](f)
+	go func() {
+		res := f.Await()
+		if res.IsErr() {
+			fut.resolve(shepard.Err[U, E](res.UnwrapErr()))
+			return
+		}
+		fut.resolve(shepard.Ok[U, E](op(res.Unwrap())))
+	}()
+	return fut
+}
+
+// Then calls op with the shepard.Ok value of f once it settled and resolves to the Future returned by op.
+// If f resolves to shepard.Err, op is not called and the error is passed through.
+//
+// Cancelling the returned Future cancels f and the Future returned by op.
+func Then[T any, U any, E any](f *Future[T, E], op ThenFunc[T, U, E]) *Future[U, E] {
+	fut := newFuture[U, E](f)
+	go func() {
+		res := f.Await()
+		if res.IsErr() {
+			fut.resolve(shepard.Err[U, E](res.UnwrapErr()))
+			return
+		}
+		next := op(res.Unwrap())
+		select {
+		case <-next.done:
+		case <-fut.ctx.Done():
+			next.Cancel()
+		}
+		fut.resolve(next.Await())
+	}()
+	return fut
+}
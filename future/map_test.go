@@ -0,0 +1,57 @@
+package future_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/future"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	f := future.Map(future.Ok[int, error](2), func(v int) string { return strconv.Itoa(v * v) })
+	assert.True(t, f.Await().Equal(shepard.Ok[string, error]("4")))
+
+	err := errors.New("failed")
+	f2 := future.Map(future.Err[int, error](err), func(v int) string { return strconv.Itoa(v * v) })
+	assert.Equal(t, err, f2.Await().UnwrapErr())
+}
+
+func TestMap_Cancel(t *testing.T) {
+	source := after(time.Second, shepard.Ok[int, error](1))
+	f := future.Map(source, func(v int) int { return v + 1 })
+	f.Cancel()
+	assert.ErrorIs(t, f.Await().UnwrapErr(), context.Canceled)
+	assert.ErrorIs(t, source.Await().UnwrapErr(), context.Canceled)
+}
+
+func TestThen(t *testing.T) {
+	f := future.Then(future.Ok[int, error](2), func(v int) *future.Future[string, error] {
+		return after(time.Millisecond, shepard.Ok[string, error](strconv.Itoa(v)))
+	})
+	assert.True(t, f.Await().Equal(shepard.Ok[string, error]("2")))
+
+	called := false
+	err := errors.New("failed")
+	f2 := future.Then(future.Err[int, error](err), func(v int) *future.Future[string, error] {
+		called = true
+		return future.Ok[string, error]("unreachable")
+	})
+	assert.Equal(t, err, f2.Await().UnwrapErr())
+	assert.False(t, called)
+}
+
+func TestThen_Cancel(t *testing.T) {
+	next := after(time.Second, shepard.Ok[string, error]("slow"))
+	f := future.Then(future.Ok[int, error](1), func(v int) *future.Future[string, error] {
+		return next
+	})
+	time.Sleep(10 * time.Millisecond)
+	f.Cancel()
+	assert.ErrorIs(t, f.Await().UnwrapErr(), context.Canceled)
+	assert.ErrorIs(t, next.Await().UnwrapErr(), context.Canceled)
+}
@@ -0,0 +1,45 @@
+package future
+
+import (
+	"github.com/marlaone/shepard"
+)
+
+// Race resolves to the shepard.Result of the first Future that settles, regardless of whether it is shepard.Ok or shepard.Err.
+// The remaining futures are cancelled.
+//
+// Panics if no futures are given.
+func Race[T any, E any](futures ...*Future[T, E]) *Future[T, E] {
+	if len(futures) == 0 {
+		panic("future.Race called without futures")
+	}
+	fut := newFuture[T, E](cancellers(futures)...)
+	go func() {
+		i := <-settled(futures)
+		fut.resolve(futures[i].result)
+	}()
+	return fut
+}
+
+// Select resolves to the first Future that resolves to shepard.Ok and cancels the remaining futures.
+//
+// If every Future resolves to shepard.Err, Select resolves to the error of the Future that settled last.
+//
+// Panics if no futures are given.
+func Select[T any, E any](futures ...*Future[T, E]) *Future[T, E] {
+	if len(futures) == 0 {
+		panic("future.Select called without futures")
+	}
+	fut := newFuture[T, E](cancellers(futures)...)
+	go func() {
+		var last shepard.Result[T, E]
+		ch := settled(futures)
+		for range futures {
+			last = futures[<-ch].result
+			if last.IsOk() {
+				break
+			}
+		}
+		fut.resolve(last)
+	}()
+	return fut
+}
@@ -0,0 +1,44 @@
+package future_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/future"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRace(t *testing.T) {
+	slow := after(time.Second, shepard.Ok[int, error](1))
+	f := future.Race(slow, after(time.Millisecond, shepard.Ok[int, error](2)))
+	assert.True(t, f.Await().Equal(shepard.Ok[int, error](2)))
+	assert.ErrorIs(t, slow.Await().UnwrapErr(), context.Canceled)
+
+	err := errors.New("failed")
+	f2 := future.Race(after(time.Second, shepard.Ok[int, error](1)), future.Err[int, error](err))
+	assert.Equal(t, err, f2.Await().UnwrapErr())
+
+	assert.Panics(t, func() { future.Race[int, error]() })
+}
+
+func TestSelect(t *testing.T) {
+	err := errors.New("failed")
+	f := future.Select(
+		future.Err[int, error](err),
+		after(10*time.Millisecond, shepard.Ok[int, error](2)),
+		after(time.Second, shepard.Ok[int, error](3)),
+	)
+	assert.True(t, f.Await().Equal(shepard.Ok[int, error](2)))
+
+	last := errors.New("last")
+	f2 := future.Select(
+		future.Err[int, error](err),
+		after(10*time.Millisecond, shepard.Err[int, error](last)),
+	)
+	assert.Equal(t, last, f2.Await().UnwrapErr())
+
+	assert.Panics(t, func() { future.Select[int, error]() })
+}
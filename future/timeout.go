@@ -0,0 +1,23 @@
+package future
+
+import (
+	"time"
+
+	"github.com/marlaone/shepard"
+)
+
+// WithTimeout resolves to the shepard.Result of f if it settles within d, otherwise it resolves to shepard.Err(err) and cancels f.
+func WithTimeout[T any, E any](f *Future[T, E], d time.Duration, err E) *Future[T, E] {
+	fut := newFuture[T, E](f)
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-f.done:
+			fut.resolve(f.result)
+		case <-timer.C:
+			fut.resolve(shepard.Err[T, E](err))
+		}
+	}()
+	return fut
+}
@@ -0,0 +1,24 @@
+package future_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/future"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeout(t *testing.T) {
+	errTimeout := errors.New("timeout")
+
+	f := future.WithTimeout(after(time.Millisecond, shepard.Ok[int, error](1)), time.Second, errTimeout)
+	assert.True(t, f.Await().Equal(shepard.Ok[int, error](1)))
+
+	slow := after(time.Second, shepard.Ok[int, error](1))
+	f2 := future.WithTimeout(slow, 10*time.Millisecond, errTimeout)
+	assert.Equal(t, errTimeout, f2.Await().UnwrapErr())
+	assert.ErrorIs(t, slow.Await().UnwrapErr(), context.Canceled)
+}
@@ -1,12 +1,14 @@
 module github.com/marlaone/shepard
 
-go 1.19
+go 1.21
 
-require github.com/stretchr/testify v1.8.1
+require (
+	github.com/stretchr/testify v1.8.1
+	golang.org/x/exp v0.0.0-20221031165847-c99f073a8326
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/exp v0.0.0-20221031165847-c99f073a8326 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// APIKeyVerifier returns the subject owning key, or shepard.None if the key is unknown.
+type APIKeyVerifier func(ctx context.Context, key string) shepard.Option[string]
+
+// StaticAPIKeys returns an APIKeyVerifier for the fixed keys, mapped to their subjects. Keys are compared in constant time.
+func StaticAPIKeys(keys map[string]string) APIKeyVerifier {
+	return func(_ context.Context, key string) shepard.Option[string] {
+		subject := shepard.None[string]()
+		// all keys are compared, so the position of a key can't be told by timing
+		for candidate, owner := range keys {
+			if secretEqual(key, candidate) {
+				subject = shepard.Some(owner)
+			}
+		}
+		return subject
+	}
+}
+
+// APIKeyOptions configures API key authentication.
+type APIKeyOptions struct {
+	// Realm is sent in the WWW-Authenticate challenge.
+	Realm string
+	// Header is the request header carrying the key, "X-API-Key" by default.
+	Header string
+	// Query is the query parameter carrying the key if the header is missing, keys aren't read from the query if it's empty.
+	// Query strings end up in access logs, prefer the header.
+	Query string
+	// Verify checks the key.
+	Verify APIKeyVerifier
+}
+
+func (o APIKeyOptions) Default() APIKeyOptions {
+	return APIKeyOptions{
+		Header: "X-API-Key",
+	}
+}
+
+// APIKey returns a middleware authenticating requests with an API key, read from a header or the query.
+//
+// The principal of an authenticated request has the subject returned by opts.Verify.
+func APIKey(opts APIKeyOptions) http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		key := ""
+		if opts.Header != "" {
+			key = req.Headers.Value(opts.Header)
+		}
+		if key == "" && opts.Query != "" {
+			if value := req.URL.Query().Get(opts.Query).First(); value.IsSome() {
+				key = *value.Unwrap()
+			}
+		}
+		if key == "" {
+			return unauthorized(ErrMissingCredentials, SchemeAPIKey, "realm", opts.Realm, "header", opts.Header)
+		}
+
+		subject := opts.Verify(req.Context, key)
+		if subject.IsNone() {
+			return unauthorized(ErrInvalidCredentials, SchemeAPIKey, "realm", opts.Realm, "header", opts.Header)
+		}
+		return authenticated(req, next, Principal{Subject: subject.Unwrap(), Scheme: SchemeAPIKey})
+	}
+}
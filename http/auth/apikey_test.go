@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := APIKeyOptions{}.Default()
+	opts.Realm = "api"
+	opts.Verify = StaticAPIKeys(map[string]string{"key-1": "billing", "key-2": "reporting"})
+	r := newAuthRouter(APIKey(opts))
+
+	res := r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"x-api-key": "key-2"})).Unwrap()
+	assert.Equal("APIKey reporting", testhttp.ReadBody(res))
+
+	// the query is only read if enabled
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/?api_key=key-1", nil)).Unwrap()
+	assert.Equal(http.StatusCodeUnauthorized, res.StatusCode())
+	assert.Equal(slice.Init(`APIKey realm="api", header="X-API-Key"`), res.Headers().Get("WWW-Authenticate"))
+
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"X-API-Key": "key-3"})).Unwrap()
+	assert.Equal(http.StatusCodeUnauthorized, res.StatusCode())
+
+	opts.Query = "api_key"
+	r = newAuthRouter(APIKey(opts))
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/?api_key=key-1", nil)).Unwrap()
+	assert.Equal("APIKey billing", testhttp.ReadBody(res))
+
+	// the header takes precedence
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/?api_key=key-1", map[string]string{"X-API-Key": "key-2"})).Unwrap()
+	assert.Equal("APIKey reporting", testhttp.ReadBody(res))
+
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/?api_key=", nil)).Unwrap()
+	assert.Equal(http.StatusCodeUnauthorized, res.StatusCode())
+}
@@ -0,0 +1,104 @@
+// Package auth implements authentication middleware for Basic auth, Bearer JWTs and API keys.
+//
+// The middleware stores the authenticated Principal in the context of the request, see PrincipalFromContext.
+// Requests failing authentication are answered with 401 and a WWW-Authenticate challenge.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// Authentication schemes of a Principal.
+const (
+	SchemeBasic  = "Basic"
+	SchemeBearer = "Bearer"
+	SchemeAPIKey = "APIKey"
+)
+
+var (
+	// ErrMissingCredentials is the cause of a 401 for requests without credentials.
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials is the cause of a 401 for requests with wrong credentials.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated client.
+type Principal struct {
+	// Subject identifies the client, e.g. the user name, the "sub" claim of a JWT or the owner of an API key.
+	Subject string
+	// Scheme is the scheme the client authenticated with, e.g. SchemeBasic.
+	Scheme string
+	// Claims are the claims of a JWT, nil for other schemes.
+	Claims Claims
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying principal.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx by the authentication middleware.
+func PrincipalFromContext(ctx context.Context) shepard.Option[Principal] {
+	if ctx == nil {
+		return shepard.None[Principal]()
+	}
+	if principal, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return shepard.Some(principal)
+	}
+	return shepard.None[Principal]()
+}
+
+// authenticated stores principal in the context of req and invokes next.
+func authenticated(req *http.Request[http.RequestBody], next http.Next, principal Principal) shepard.Result[http.Response[http.Body], error] {
+	req.Context = ContextWithPrincipal(req.Context, principal)
+	return next()
+}
+
+// unauthorized returns a 401 problem response with err as detail and the WWW-Authenticate challenge of scheme with params,
+// pairs of names and values.
+func unauthorized(err error, scheme string, params ...string) shepard.Result[http.Response[http.Body], error] {
+	res := http.Error(http.StatusCodeUnauthorized, err)
+	res.SetHeader("WWW-Authenticate", challenge(scheme, params...))
+	return shepard.Ok[http.Response[http.Body], error](res)
+}
+
+// challenge formats a WWW-Authenticate challenge, e.g. `Basic realm="api"`. Empty params are left out.
+func challenge(scheme string, params ...string) string {
+	parts := []string{}
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] != "" {
+			parts = append(parts, params[i]+"="+strconv.Quote(params[i+1]))
+		}
+	}
+	if len(parts) == 0 {
+		return scheme
+	}
+	return scheme + " " + strings.Join(parts, ", ")
+}
+
+// credentials returns the credentials of the Authorization header of req if it uses scheme, which is compared case-insensitively.
+func credentials(req *http.Request[http.RequestBody], scheme string) shepard.Option[string] {
+	authorization := req.Headers.Value("Authorization")
+	name, value, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(name, scheme) {
+		return shepard.None[string]()
+	}
+	return shepard.Some(strings.TrimSpace(value))
+}
+
+// secretEqual compares secrets in constant time.
+func secretEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/marlaone/shepard/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAuthRouter returns a router responding with the subject and scheme of the principal on "/".
+func newAuthRouter(mw http.Middleware) *http.Router {
+	return http.NewRouter().Use(mw).Route(http.Get("/", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		principal := PrincipalFromContext(req.Context).Unwrap()
+		return http.Text(http.StatusCodeOk, principal.Scheme+" "+principal.Subject)
+	}))
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	assert.True(t, PrincipalFromContext(context.Background()).IsNone())
+	assert.True(t, PrincipalFromContext(nil).IsNone())
+
+	ctx := ContextWithPrincipal(nil, Principal{Subject: "alice", Scheme: SchemeBasic})
+	assert.Equal(t, "alice", PrincipalFromContext(ctx).Unwrap().Subject)
+}
+
+func TestChallenge(t *testing.T) {
+	assert.Equal(t, "Bearer", challenge(SchemeBearer, "realm", ""))
+	assert.Equal(t, `Basic realm="api", charset="UTF-8"`, challenge(SchemeBasic, "realm", "api", "charset", "UTF-8"))
+	assert.Equal(t, `Bearer error_description="quote \" inside"`, challenge(SchemeBearer, "error_description", `quote " inside`))
+}
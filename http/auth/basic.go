@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// BasicVerifier returns true if password is the password of username, e.g. by checking a password hash in a database.
+type BasicVerifier func(ctx context.Context, username, password string) bool
+
+// BasicUsers returns a BasicVerifier for the fixed passwords of users, which are compared in constant time.
+func BasicUsers(users map[string]string) BasicVerifier {
+	return func(_ context.Context, username, password string) bool {
+		expected, ok := users[username]
+		// the comparison is done for unknown users as well, so they can't be told apart by timing
+		return secretEqual(password, expected) && ok
+	}
+}
+
+// Basic returns a middleware authenticating requests with the Basic scheme of RFC 7617, whose credentials are checked by verify.
+//
+// The principal of an authenticated request has the user name as subject.
+func Basic(realm string, verify BasicVerifier) http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		encoded := credentials(req, SchemeBasic)
+		if encoded.IsNone() {
+			return unauthorized(ErrMissingCredentials, SchemeBasic, "realm", realm, "charset", "UTF-8")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded.Unwrap())
+		if err != nil {
+			return unauthorized(ErrInvalidCredentials, SchemeBasic, "realm", realm, "charset", "UTF-8")
+		}
+		username, password, found := strings.Cut(string(decoded), ":")
+		if !found || !verify(req.Context, username, password) {
+			return unauthorized(ErrInvalidCredentials, SchemeBasic, "realm", realm, "charset", "UTF-8")
+		}
+		return authenticated(req, next, Principal{Subject: username, Scheme: SchemeBasic})
+	}
+}
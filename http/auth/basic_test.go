@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func TestBasic(t *testing.T) {
+	assert := assert.New(t)
+	r := newAuthRouter(Basic("admin", BasicUsers(map[string]string{"alice": "s3cr:et", "bob": ""})))
+
+	res := r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"Authorization": basicAuth("alice", "s3cr:et")})).Unwrap()
+	assert.Equal(http.StatusCodeOk, res.StatusCode())
+	assert.Equal("Basic alice", testhttp.ReadBody(res))
+
+	// the scheme is case-insensitive
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"authorization": "basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3cr:et"))})).Unwrap()
+	assert.Equal(http.StatusCodeOk, res.StatusCode())
+
+	for _, authorization := range []string{
+		"",
+		basicAuth("alice", "wrong"),
+		basicAuth("mallory", ""),
+		"Basic not-base64",
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("alice")),
+		"Bearer token",
+	} {
+		headers := map[string]string{}
+		if authorization != "" {
+			headers["Authorization"] = authorization
+		}
+		res := r.Serve(testhttp.NewRequest(http.MethodGet, "/", headers)).Unwrap()
+		assert.Equal(http.StatusCodeUnauthorized, res.StatusCode(), authorization)
+		assert.Equal(slice.Init(`Basic realm="admin", charset="UTF-8"`), res.Headers().Get("WWW-Authenticate"), authorization)
+	}
+
+	// an empty password is a password
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"Authorization": basicAuth("bob", "")})).Unwrap()
+	assert.Equal("Basic bob", testhttp.ReadBody(res))
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/marlaone/shepard"
+)
+
+// jwk is a JSON Web Key of RFC 7517.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// N and E are the modulus and the exponent of an RSA key
+	N string `json:"n"`
+	E string `json:"e"`
+	// K is the secret of a symmetric key
+	K string `json:"k"`
+}
+
+// LoadJWKS reads the keys of the JSON Web Key Set file at path, see ParseJWKS.
+func LoadJWKS(path string) shepard.Result[[]Key, error] {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return shepard.Err[[]Key, error](fmt.Errorf("[auth.LoadJWKS] read %q failed: %w", path, err))
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS returns the keys of a JSON Web Key Set, e.g. {"keys": [{"kty": "RSA", "kid": "1", "n": "...", "e": "AQAB"}]}.
+//
+// RSA keys verify RS256 and symmetric "oct" keys HS256 signatures. Keys of other types or algorithms and encryption keys
+// are skipped.
+func ParseJWKS(data []byte) shepard.Result[[]Key, error] {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return shepard.Err[[]Key, error](fmt.Errorf("[auth.ParseJWKS] decode key set failed: %w", err))
+	}
+
+	keys := []Key{}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch {
+		case k.KeyType == "RSA" && (k.Algorithm == "" || k.Algorithm == AlgorithmRS256):
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return shepard.Err[[]Key, error](fmt.Errorf("[auth.ParseJWKS] key %d: decode modulus failed: %w", i, err))
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil || !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+				return shepard.Err[[]Key, error](fmt.Errorf("[auth.ParseJWKS] key %d: invalid exponent %q", i, k.E))
+			}
+			keys = append(keys, RSAKey(k.KeyID, &rsa.PublicKey{N: n, E: int(e.Int64())}))
+		case k.KeyType == "oct" && (k.Algorithm == "" || k.Algorithm == AlgorithmHS256):
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return shepard.Err[[]Key, error](fmt.Errorf("[auth.ParseJWKS] key %d: invalid secret", i))
+			}
+			keys = append(keys, HMACKey(k.KeyID, secret))
+		}
+	}
+	return shepard.Ok[[]Key, error](keys)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
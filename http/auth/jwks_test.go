@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"encoding/base64"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadJWKS(t *testing.T) {
+	assert := assert.New(t)
+
+	rsaKey := generateRSAKey(t)
+	n := base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())
+	k := base64.RawURLEncoding.EncodeToString(testSecret)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(os.WriteFile(path, []byte(`{"keys": [
+		{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig", "n": "`+n+`", "e": "`+e+`"},
+		{"kty": "oct", "kid": "hmac-1", "k": "`+k+`"},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "`+n+`", "e": "`+e+`"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "", "y": ""}
+	]}`), 0o600))
+
+	keys := LoadJWKS(path).Unwrap()
+	assert.Len(keys, 2)
+	assert.Equal("rsa-1", keys[0].ID)
+	assert.Equal(AlgorithmRS256, keys[0].Algorithm)
+	assert.Equal("hmac-1", keys[1].ID)
+	assert.Equal(AlgorithmHS256, keys[1].Algorithm)
+
+	opts := JWTOptions{Keys: keys, Now: func() time.Time { return testNow }}
+	claims := map[string]any{"sub": "alice"}
+	assert.Equal("alice", VerifyJWT(signJWT(t, map[string]any{"alg": "RS256", "kid": "rsa-1"}, claims, rsaKey), opts).Unwrap().Subject())
+	assert.Equal("alice", VerifyJWT(signJWT(t, map[string]any{"alg": "HS256", "kid": "hmac-1"}, claims, testSecret), opts).Unwrap().Subject())
+
+	assert.True(LoadJWKS(filepath.Join(t.TempDir(), "missing.json")).IsErr())
+	assert.True(ParseJWKS([]byte(`{"keys": [{"kty": "RSA", "n": "", "e": "AQAB"}]}`)).IsErr())
+	assert.True(ParseJWKS([]byte(`{"keys": [{"kty": "oct", "k": ""}]}`)).IsErr())
+	assert.True(ParseJWKS([]byte(`[]`)).IsErr())
+}
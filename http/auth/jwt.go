@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// Supported JWT signature algorithms.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed tokens and tokens with an invalid signature.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for tokens whose "exp" claim has passed.
+	ErrTokenExpired = errors.New("token expired")
+	// ErrTokenNotYetValid is returned for tokens whose "nbf" claim hasn't been reached.
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	// ErrInvalidAudience is returned for tokens not issued for the expected audience.
+	ErrInvalidAudience = errors.New("invalid audience")
+	// ErrInvalidIssuer is returned for tokens not issued by the expected issuer.
+	ErrInvalidIssuer = errors.New("invalid issuer")
+)
+
+// Claims are the claims of a JWT. Numbers are json.Number.
+type Claims map[string]any
+
+// String returns the string claim name, or an empty string if it's missing or not a string.
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// Time returns the NumericDate claim name, e.g. "exp".
+func (c Claims) Time(name string) shepard.Option[time.Time] {
+	number, ok := c[name].(json.Number)
+	if !ok {
+		return shepard.None[time.Time]()
+	}
+	if seconds, err := number.Int64(); err == nil {
+		return shepard.Some(time.Unix(seconds, 0))
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return shepard.None[time.Time]()
+	}
+	return shepard.Some(time.Unix(0, int64(seconds*float64(time.Second))))
+}
+
+// Audience returns the "aud" claim, which is a string or an array of strings.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		audience := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+		return audience
+	}
+	return nil
+}
+
+// Key is a key verifying JWT signatures, either an HMAC secret or an RSA public key.
+type Key struct {
+	// ID is matched against the "kid" header of tokens, tokens without "kid" are tried with all keys.
+	ID string
+	// Algorithm is the only algorithm the key verifies, which prevents forging tokens with a public key as HMAC secret.
+	Algorithm string
+	secret    []byte
+	publicKey *rsa.PublicKey
+}
+
+// HMACKey returns a key verifying HS256 signatures with secret.
+func HMACKey(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: AlgorithmHS256, secret: secret}
+}
+
+// RSAKey returns a key verifying RS256 signatures with publicKey.
+func RSAKey(id string, publicKey *rsa.PublicKey) Key {
+	return Key{ID: id, Algorithm: AlgorithmRS256, publicKey: publicKey}
+}
+
+// verify returns true if signature is the signature of signed by the key.
+func (k Key) verify(signed, signature []byte) bool {
+	hash := sha256.Sum256(signed)
+	switch k.Algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(signed)
+		return len(k.secret) > 0 && hmac.Equal(mac.Sum(nil), signature)
+	case AlgorithmRS256:
+		return k.publicKey != nil && rsa.VerifyPKCS1v15(k.publicKey, crypto.SHA256, hash[:], signature) == nil
+	}
+	return false
+}
+
+// JWTOptions configures the validation of JWTs.
+type JWTOptions struct {
+	// Realm is sent in the WWW-Authenticate challenge.
+	Realm string
+	// Keys are the keys verifying signatures, e.g. loaded by LoadJWKS.
+	Keys []Key
+	// Audience is required to be in the "aud" claim, if it isn't empty.
+	Audience string
+	// Issuer is required to be the "iss" claim, if it isn't empty.
+	Issuer string
+	// Leeway is the tolerated clock skew for "exp" and "nbf".
+	Leeway time.Duration
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+func (o JWTOptions) Default() JWTOptions {
+	return JWTOptions{
+		Leeway: time.Minute,
+	}
+}
+
+// Bearer returns a middleware authenticating requests with a JWT in the Bearer scheme of RFC 6750, validated by VerifyJWT.
+//
+// The principal of an authenticated request has the "sub" claim as subject and all claims of the token.
+func Bearer(opts JWTOptions) http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		token := credentials(req, SchemeBearer)
+		if token.IsNone() || token.Unwrap() == "" {
+			return unauthorized(ErrMissingCredentials, SchemeBearer, "realm", opts.Realm)
+		}
+		claims := VerifyJWT(token.Unwrap(), opts)
+		if claims.IsErr() {
+			// the client learns why the token was rejected, not the details of the validation
+			err := tokenError(claims.UnwrapErr())
+			return unauthorized(err, SchemeBearer, "realm", opts.Realm, "error", "invalid_token", "error_description", err.Error())
+		}
+		return authenticated(req, next, Principal{Subject: claims.Unwrap().Subject(), Scheme: SchemeBearer, Claims: claims.Unwrap()})
+	}
+}
+
+// VerifyJWT returns the claims of the compact serialized JWT token, if it's signed by one of opts.Keys with HS256 or RS256
+// and valid according to its "exp", "nbf", "aud" and "iss" claims.
+func VerifyJWT(token string, opts JWTOptions) shepard.Result[Claims, error] {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return shepard.Err[Claims, error](fmt.Errorf("[auth.VerifyJWT] token has %d parts: %w", len(parts), ErrInvalidToken))
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return shepard.Err[Claims, error](fmt.Errorf("[auth.VerifyJWT] decode header failed: %s: %w", err, ErrInvalidToken))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return shepard.Err[Claims, error](fmt.Errorf("[auth.VerifyJWT] decode signature failed: %s: %w", err, ErrInvalidToken))
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range opts.Keys {
+		if key.Algorithm == header.Algorithm && (header.KeyID == "" || key.ID == header.KeyID) && key.verify(signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return shepard.Err[Claims, error](fmt.Errorf("[auth.VerifyJWT] no key verifies the %q signature: %w", header.Algorithm, ErrInvalidToken))
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return shepard.Err[Claims, error](fmt.Errorf("[auth.VerifyJWT] decode claims failed: %s: %w", err, ErrInvalidToken))
+	}
+	if err := validateClaims(claims, opts); err != nil {
+		return shepard.Err[Claims, error](fmt.Errorf("[auth.VerifyJWT] %w", err))
+	}
+	return shepard.Ok[Claims, error](claims)
+}
+
+// validateClaims checks the registered claims against opts.
+func validateClaims(claims Claims, opts JWTOptions) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	for _, name := range []string{"exp", "nbf"} {
+		if _, present := claims[name]; present && claims.Time(name).IsNone() {
+			return fmt.Errorf("claim %q isn't a number: %w", name, ErrInvalidToken)
+		}
+	}
+	if exp := claims.Time("exp"); exp.IsSome() && !now.Before(exp.Unwrap().Add(opts.Leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf := claims.Time("nbf"); nbf.IsSome() && now.Add(opts.Leeway).Before(nbf.Unwrap()) {
+		return ErrTokenNotYetValid
+	}
+	if opts.Audience != "" {
+		found := false
+		for _, aud := range claims.Audience() {
+			if aud == opts.Audience {
+				found = true
+			}
+		}
+		if !found {
+			return ErrInvalidAudience
+		}
+	}
+	if opts.Issuer != "" && claims.String("iss") != opts.Issuer {
+		return ErrInvalidIssuer
+	}
+	return nil
+}
+
+// tokenError returns the error of the validation causing err.
+func tokenError(err error) error {
+	for _, cause := range []error{ErrTokenExpired, ErrTokenNotYetValid, ErrInvalidAudience, ErrInvalidIssuer} {
+		if errors.Is(err, cause) {
+			return cause
+		}
+	}
+	return ErrInvalidToken
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token into v.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testNow    = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signJWT returns a compact JWT of header and claims, signed with an HMAC secret or an RSA private key.
+func signJWT(t *testing.T, header map[string]any, claims map[string]any, key any) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		hash := sha256.Sum256([]byte(signed))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyJWT(t *testing.T) {
+	assert := assert.New(t)
+
+	rsaKey := generateRSAKey(t)
+	opts := JWTOptions{}.Default()
+	opts.Keys = []Key{HMACKey("hmac", testSecret), RSAKey("rsa", &rsaKey.PublicKey)}
+	opts.Audience = "api"
+	opts.Issuer = "https://issuer.example.com"
+	opts.Now = func() time.Time { return testNow }
+
+	valid := map[string]any{
+		"sub": "alice",
+		"aud": []string{"web", "api"},
+		"iss": "https://issuer.example.com",
+		"exp": testNow.Add(time.Hour).Unix(),
+		"nbf": testNow.Add(-time.Hour).Unix(),
+	}
+	with := func(name string, value any) map[string]any {
+		claims := map[string]any{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	claims := VerifyJWT(signJWT(t, map[string]any{"alg": "HS256", "typ": "JWT"}, valid, testSecret), opts)
+	assert.NoError(claims.Err().UnwrapOr(nil))
+	assert.Equal("alice", claims.Unwrap().Subject())
+	assert.Equal([]string{"web", "api"}, claims.Unwrap().Audience())
+	assert.True(testNow.Add(time.Hour).Equal(claims.Unwrap().Time("exp").Unwrap()))
+
+	claims = VerifyJWT(signJWT(t, map[string]any{"alg": "RS256", "kid": "rsa"}, with("aud", "api"), rsaKey), opts)
+	assert.Equal("alice", claims.Unwrap().Subject())
+
+	testCases := []struct {
+		name   string
+		token  string
+		reason error
+	}{
+		{name: "malformed", token: "a.b", reason: ErrInvalidToken},
+		{name: "invalid header", token: "!!.e30.", reason: ErrInvalidToken},
+		{name: "wrong secret", token: signJWT(t, map[string]any{"alg": "HS256"}, valid, []byte("other")), reason: ErrInvalidToken},
+		{name: "wrong rsa key", token: signJWT(t, map[string]any{"alg": "RS256"}, valid, generateRSAKey(t)), reason: ErrInvalidToken},
+		{name: "unknown kid", token: signJWT(t, map[string]any{"alg": "HS256", "kid": "other"}, valid, testSecret), reason: ErrInvalidToken},
+		{name: "none algorithm", token: signJWT(t, map[string]any{"alg": "none"}, valid, nil), reason: ErrInvalidToken},
+		// the public key must not be usable as HMAC secret
+		{name: "algorithm confusion", token: signJWT(t, map[string]any{"alg": "HS256", "kid": "rsa"}, valid, x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)), reason: ErrInvalidToken},
+		{name: "expired", token: signJWT(t, map[string]any{"alg": "HS256"}, with("exp", testNow.Add(-2*time.Minute).Unix()), testSecret), reason: ErrTokenExpired},
+		{name: "not yet valid", token: signJWT(t, map[string]any{"alg": "HS256"}, with("nbf", testNow.Add(2*time.Minute).Unix()), testSecret), reason: ErrTokenNotYetValid},
+		{name: "invalid exp", token: signJWT(t, map[string]any{"alg": "HS256"}, with("exp", "tomorrow"), testSecret), reason: ErrInvalidToken},
+		{name: "other audience", token: signJWT(t, map[string]any{"alg": "HS256"}, with("aud", "web"), testSecret), reason: ErrInvalidAudience},
+		{name: "missing audience", token: signJWT(t, map[string]any{"alg": "HS256"}, with("aud", nil), testSecret), reason: ErrInvalidAudience},
+		{name: "other issuer", token: signJWT(t, map[string]any{"alg": "HS256"}, with("iss", "https://evil.example.com"), testSecret), reason: ErrInvalidIssuer},
+	}
+	for _, tc := range testCases {
+		assert.ErrorIs(VerifyJWT(tc.token, opts).UnwrapErr(), tc.reason, tc.name)
+	}
+
+	// the leeway tolerates clock skew
+	token := signJWT(t, map[string]any{"alg": "HS256"}, with("exp", testNow.Add(-30*time.Second).Unix()), testSecret)
+	assert.True(VerifyJWT(token, opts).IsOk())
+	opts.Leeway = 0
+	assert.ErrorIs(VerifyJWT(token, opts).UnwrapErr(), ErrTokenExpired)
+}
+
+func TestBearer(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := JWTOptions{}.Default()
+	opts.Realm = "api"
+	opts.Keys = []Key{HMACKey("", testSecret)}
+	r := newAuthRouter(Bearer(opts))
+
+	token := signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}, testSecret)
+	res := r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"Authorization": "Bearer " + token})).Unwrap()
+	assert.Equal("Bearer alice", testhttp.ReadBody(res))
+
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/", nil)).Unwrap()
+	assert.Equal(http.StatusCodeUnauthorized, res.StatusCode())
+	assert.Equal(slice.Init(`Bearer realm="api"`), res.Headers().Get("WWW-Authenticate"))
+
+	expired := signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()}, testSecret)
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"Authorization": "Bearer " + expired})).Unwrap()
+	assert.Equal(http.StatusCodeUnauthorized, res.StatusCode())
+	assert.Equal(slice.Init(`Bearer realm="api", error="invalid_token", error_description="token expired"`), res.Headers().Get("WWW-Authenticate"))
+	assert.Contains(testhttp.ReadBody(res), `"detail":"token expired"`)
+
+	res = r.Serve(testhttp.NewRequest(http.MethodGet, "/", map[string]string{"Authorization": "Bearer garbage"})).Unwrap()
+	assert.Equal(slice.Init(`Bearer realm="api", error="invalid_token", error_description="invalid token"`), res.Headers().Get("WWW-Authenticate"))
+}
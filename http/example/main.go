@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"runtime/pprof"
+	"time"
 
 	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/collections/slice"
@@ -25,14 +28,6 @@ func main() {
 		pprof.StartCPUProfile(f)
 	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		<-c
-		pprof.StopCPUProfile()
-		os.Exit(1)
-	}()
-
 	r := NewRouter().
 		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
 			// remove trailing slash
@@ -76,6 +71,12 @@ func main() {
 			res.Body().Close()
 			return res
 		})).
+		Route(Get("/hello/:name", func(req *Request[RequestBody]) Response[Body] {
+			res := NewResponseBuilder(NewHttpResponseBytes()).Status(200).Body(NewBytesBody()).Unwrap()
+			res.Body().Write(slice.Init[byte]([]byte("Hello " + req.Param("name").Unwrap() + "!")...))
+			res.Body().Close()
+			return res
+		})).
 		Route(Get("/hello.json", func(req *Request[RequestBody]) Response[Body] {
 			greetDefault := new(string)
 			*greetDefault = "world"
@@ -88,7 +89,28 @@ func main() {
 			return res
 		}))
 
-	if err := Serve(":8080", r); err != nil {
+	server := &Server{
+		Addr:         ":8080",
+		Handler:      r,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  time.Minute,
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	log.Println("Listening on http://localhost:8080")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, ErrServerClosed) {
 		log.Fatal(err)
 	}
+	pprof.StopCPUProfile()
 }
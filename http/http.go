@@ -1,86 +1,50 @@
 package http
 
 import (
-	"fmt"
-	"log"
-	"net"
+	"errors"
 	"strings"
-
-	"github.com/marlaone/shepard/collections/hashmap"
-	"github.com/marlaone/shepard/collections/slice"
 )
 
+// Serve listens on the TCP address addr and serves requests with r.
+//
+// Use a Server for timeouts, connection limits, graceful shutdown and a custom Logger.
 func Serve(addr string, r *Router) error {
-	l, err := net.Listen("tcp", addr)
-	if err != nil {
-		return fmt.Errorf("[http.Serve] listen failed: %w", err)
-	}
-	defer l.Close()
+	s := &Server{Addr: addr, Handler: r}
+	return s.ListenAndServe()
+}
 
-	host, port, _ := strings.Cut(addr, ":")
+// ServeTLS listens on the TCP address addr and serves HTTPS requests with r,
+// using the PEM encoded certificate and private key from certFile and keyFile.
+//
+// Use a Server for a custom tls.Config, timeouts, connection limits, graceful shutdown and a custom Logger.
+func ServeTLS(addr string, certFile string, keyFile string, r *Router) error {
+	s := &Server{Addr: addr, Handler: r}
+	return s.ListenAndServeTLS(certFile, keyFile)
+}
 
-	if host == "" {
-		host = "localhost"
+// parseErrorStatus returns the status line answering a request which failed to parse with err.
+// An empty string is returned if the client went away and no response should be written.
+func parseErrorStatus(err error) string {
+	switch {
+	case errors.Is(err, ErrHeaderTooLarge):
+		return "431 Request Header Fields Too Large"
+	case errors.Is(err, ErrHTTPVersionNotSupported):
+		return "505 HTTP Version Not Supported"
+	case errors.Is(err, ErrUnsupportedTransferEncoding):
+		return "501 Not Implemented"
+	case errors.Is(err, ErrMalformedRequest):
+		return "400 Bad Request"
+	default:
+		return ""
 	}
+}
 
-	log.Println("Listening on http://" + host + ":" + port)
-
-	for {
-		conn, err := l.Accept()
-		if err != nil {
-			return fmt.Errorf("[http.Serve] accept failed: %w", err)
+// hasToken returns true if one of the comma separated header values equals token, ignoring case.
+func hasToken(values []string, token string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, token) {
+			return true
 		}
-
-		go func(conn net.Conn) {
-			req := RequestFromConnection(conn)
-
-			if req.IsErr() {
-				conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
-				// TODO remove this log
-				log.Println(req.UnwrapErr())
-				return
-			}
-
-			potentialRes := HandleRequest(conn, req.Unwrap(), r)
-			if potentialRes.IsErr() {
-				conn.Write([]byte("HTTP/1.1 500 Internal Server Error\r\n\r\n"))
-				// TODO remove this log
-				log.Println(potentialRes.UnwrapErr())
-				return
-			}
-			res := potentialRes.Unwrap()
-
-			conn.Write([]byte("HTTP/" + res.Version().String() + " " + res.StatusCode().String() + "\r\n"))
-			res.Headers().Iter().Foreach(func(_ int, value hashmap.Pair[*string, *slice.Slice[string]]) {
-				headerValues := ""
-				value.Value.Iter().Foreach(func(i int, value string) {
-					if i > 0 {
-						headerValues += ", "
-					}
-					headerValues += value
-				})
-				conn.Write([]byte(*value.Key + ": " + headerValues + "\r\n"))
-			})
-			conn.Write([]byte("\r\n"))
-			buf := slice.New[byte]()
-			for {
-				res.Body().Read(&buf)
-
-				iter := buf.Iter()
-				next := iter.Next()
-				data := make([]byte, 0, buf.Len())
-				for next.IsSome() {
-					data = append(data, next.Unwrap())
-					next = iter.Next()
-				}
-
-				conn.Write(data)
-
-				if res.Body().Closed() {
-					break
-				}
-			}
-			conn.Close()
-		}(conn)
 	}
+	return false
 }
@@ -0,0 +1,501 @@
+package http
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/unit"
+)
+
+const (
+	// DefaultMaxBodyBytes is the default limit for the size of request bodies decoded by the binders.
+	DefaultMaxBodyBytes = unit.MiB
+	// DefaultMaxFileBytes is the default limit for the size of every file of a multipart form.
+	DefaultMaxFileBytes = 32 * unit.MiB
+	// DefaultMaxFiles is the default limit for the number of files of a multipart form.
+	DefaultMaxFiles = 100
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrBodyTooLarge         = errors.New("request body too large")
+)
+
+// BindOptions configures the binders.
+type BindOptions struct {
+	// MaxBytes limits the size of the body, DefaultMaxBodyBytes if zero.
+	// For multipart forms it limits the size of all values, files are limited by MaxFileBytes.
+	MaxBytes unit.ByteSize
+	// DisallowUnknownFields makes BindJSON fail for object keys which don't match a field of the target.
+	DisallowUnknownFields bool
+	// MaxFileBytes limits the size of every file of a multipart form, DefaultMaxFileBytes if zero.
+	MaxFileBytes unit.ByteSize
+	// MaxFiles limits the number of files of a multipart form, DefaultMaxFiles if zero.
+	MaxFiles int
+	// TempDir is the directory the files of multipart forms are stored in, os.TempDir() if empty.
+	TempDir string
+}
+
+func (o BindOptions) Default() BindOptions {
+	return BindOptions{
+		MaxBytes:     DefaultMaxBodyBytes,
+		MaxFileBytes: DefaultMaxFileBytes,
+		MaxFiles:     DefaultMaxFiles,
+	}
+}
+
+func (o BindOptions) maxBytes() unit.ByteSize {
+	if o.MaxBytes == 0 {
+		return DefaultMaxBodyBytes
+	}
+	return o.MaxBytes
+}
+
+func (o BindOptions) maxFileBytes() unit.ByteSize {
+	if o.MaxFileBytes == 0 {
+		return DefaultMaxFileBytes
+	}
+	return o.MaxFileBytes
+}
+
+func (o BindOptions) maxFiles() int {
+	if o.MaxFiles == 0 {
+		return DefaultMaxFiles
+	}
+	return o.MaxFiles
+}
+
+// BindError is the error of a binder. It is answered with Status, if returned by a middleware or a handler created by Bind.
+type BindError struct {
+	Status StatusCode
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Response returns the response answering the request the error occurred for.
+func (e *BindError) Response() Response[Body] {
+	res := NewResponseBuilder(NewHttpResponseBytes()).Status(e.Status).Header("Content-Type", "text/plain; charset=utf-8").Body(NewBytesBody()).Unwrap()
+	res.Body().Write(slice.Init([]byte(e.Err.Error())...))
+	res.Finish()
+	return res
+}
+
+// bindError wraps err in a BindError with a status matching it.
+func bindError(err error) error {
+	status := StatusCodeBadRequest
+	switch {
+	case errors.Is(err, ErrUnsupportedMediaType):
+		status = StatusCodeUnsupportedMediaType
+	case errors.Is(err, ErrBodyTooLarge):
+		status = StatusCodeRequestEntityTooLarge
+	}
+	return &BindError{Status: status, Err: err}
+}
+
+// Bind returns a Handler binding the request with bind before calling handler with the bound value.
+// Requests failing to bind are answered by the response of the BindError.
+//
+//	router.Route(Post("/users", Bind(BindJSON[User], createUser)))
+func Bind[T any](bind func(req *Request[RequestBody]) shepard.Result[T, error], handler func(req *Request[RequestBody], v T) Response[Body]) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		v := bind(req)
+		if v.IsErr() {
+			var bindErr *BindError
+			if !errors.As(v.UnwrapErr(), &bindErr) {
+				bindErr = bindError(v.UnwrapErr()).(*BindError)
+			}
+			return bindErr.Response()
+		}
+		return handler(req, v.Unwrap())
+	}
+}
+
+// BindJSON decodes the JSON body of req into a T, with the default BindOptions.
+func BindJSON[T any](req *Request[RequestBody]) shepard.Result[T, error] {
+	return BindJSONWith[T](req, BindOptions{}.Default())
+}
+
+// BindJSONWith decodes the JSON body of req into a T.
+//
+// The Content-Type must be application/json or a +json media type, the body must contain exactly one JSON value.
+func BindJSONWith[T any](req *Request[RequestBody], opts BindOptions) shepard.Result[T, error] {
+	var v T
+
+	mediaType, _ := contentType(req)
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindJSON] content type %q: %w", mediaType, ErrUnsupportedMediaType)))
+	}
+
+	data, err := readRequestBody(req, opts.maxBytes())
+	if err != nil {
+		return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindJSON] %w", err)))
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return shepard.Err[T, error](bindError(errors.New("[http.BindJSON] empty body")))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&v); err != nil {
+		return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindJSON] decode failed: %w", err)))
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return shepard.Err[T, error](bindError(errors.New("[http.BindJSON] body contains data after the JSON value")))
+	}
+	return shepard.Ok[T, error](v)
+}
+
+// BindForm maps the form values of req into the fields of a struct T, with the default BindOptions.
+func BindForm[T any](req *Request[RequestBody]) shepard.Result[T, error] {
+	return BindFormWith[T](req, BindOptions{}.Default())
+}
+
+// BindFormWith maps the form values of req into the fields of a struct T.
+//
+// The values are read from an application/x-www-form-urlencoded or multipart/form-data body, files of multipart forms are ignored.
+// Query parameters are used for fields without a value in the body, so requests without a body bind the query.
+//
+// A field is bound to the values of the name in its "form" tag, or of its name if it has none. Fields tagged with "-" are skipped,
+// embedded structs are flattened. Supported are strings, booleans, numbers, types implementing encoding.TextUnmarshaler,
+// and slices and pointers of them.
+func BindFormWith[T any](req *Request[RequestBody], opts BindOptions) shepard.Result[T, error] {
+	var v T
+	target := reflect.ValueOf(&v).Elem()
+	if target.Kind() != reflect.Struct {
+		return shepard.Err[T, error](fmt.Errorf("[http.BindForm] target must be a struct, got %s", target.Type()))
+	}
+
+	values := NewValues()
+	if hasBody(req) {
+		mediaType, _ := contentType(req)
+		switch mediaType {
+		case "application/x-www-form-urlencoded":
+			data, err := readRequestBody(req, opts.maxBytes())
+			if err != nil {
+				return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindForm] %w", err)))
+			}
+			parsed := ParseQuery(strings.TrimSpace(string(data)))
+			if parsed.IsErr() {
+				return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindForm] parse form failed: %w", parsed.UnwrapErr())))
+			}
+			*values = parsed.Unwrap()
+		case "multipart/form-data":
+			form := ParseMultipartForm(req, opts)
+			if form.IsErr() {
+				return shepard.Err[T, error](form.UnwrapErr())
+			}
+			form.Unwrap().RemoveAll()
+			values = form.Unwrap().Values
+		default:
+			return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindForm] content type %q: %w", mediaType, ErrUnsupportedMediaType)))
+		}
+	}
+
+	if err := decodeValues(values, req.URL.Query(), target); err != nil {
+		return shepard.Err[T, error](bindError(fmt.Errorf("[http.BindForm] %w", err)))
+	}
+	return shepard.Ok[T, error](v)
+}
+
+// FileHeader describes a file of a multipart form, which is stored at Path until MultipartForm.RemoveAll is called.
+type FileHeader struct {
+	Filename string
+	Header   map[string][]string
+	Size     int64
+	Path     string
+}
+
+// Open opens the stored file.
+func (f *FileHeader) Open() (*os.File, error) {
+	return os.Open(f.Path)
+}
+
+// MultipartForm is a parsed multipart/form-data body.
+type MultipartForm struct {
+	Values *Values
+	Files  map[string][]*FileHeader
+}
+
+// RemoveAll removes the stored files of the form.
+func (f *MultipartForm) RemoveAll() error {
+	var errs []error
+	for _, files := range f.Files {
+		for _, file := range files {
+			if err := os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("[http.MultipartForm.RemoveAll] %d files not removed: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// ParseMultipartForm reads the multipart/form-data body of req. Files are streamed to temporary files in opts.TempDir,
+// the caller is responsible to remove them with RemoveAll.
+func ParseMultipartForm(req *Request[RequestBody], opts BindOptions) shepard.Result[*MultipartForm, error] {
+	mediaType, params := contentType(req)
+	if mediaType != "multipart/form-data" {
+		return shepard.Err[*MultipartForm, error](bindError(fmt.Errorf("[http.ParseMultipartForm] content type %q: %w", mediaType, ErrUnsupportedMediaType)))
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return shepard.Err[*MultipartForm, error](bindError(errors.New("[http.ParseMultipartForm] no boundary")))
+	}
+
+	form := &MultipartForm{Values: NewValues(), Files: map[string][]*FileHeader{}}
+	valueBudget := int64(opts.maxBytes().Bytes())
+	fileBudget := opts.maxFiles()
+	reader := multipart.NewReader(req.body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return shepard.Ok[*MultipartForm, error](form)
+		}
+		if err != nil {
+			form.RemoveAll()
+			return shepard.Err[*MultipartForm, error](bindError(fmt.Errorf("[http.ParseMultipartForm] read part failed: %w", err)))
+		}
+
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			data, err := io.ReadAll(io.LimitReader(part, valueBudget+1))
+			part.Close()
+			if err == nil && int64(len(data)) > valueBudget {
+				err = fmt.Errorf("values exceed %s: %w", opts.maxBytes(), ErrBodyTooLarge)
+			}
+			if err != nil {
+				form.RemoveAll()
+				return shepard.Err[*MultipartForm, error](bindError(fmt.Errorf("[http.ParseMultipartForm] read value %q failed: %w", name, err)))
+			}
+			valueBudget -= int64(len(data))
+			form.Values.Add(name, string(data))
+			continue
+		}
+
+		if fileBudget == 0 {
+			part.Close()
+			form.RemoveAll()
+			return shepard.Err[*MultipartForm, error](bindError(fmt.Errorf("[http.ParseMultipartForm] more than %d files: %w", opts.maxFiles(), ErrBodyTooLarge)))
+		}
+		fileBudget--
+
+		file, err := storeFile(part, opts)
+		part.Close()
+		if err != nil {
+			form.RemoveAll()
+			return shepard.Err[*MultipartForm, error](bindError(fmt.Errorf("[http.ParseMultipartForm] store file %q failed: %w", part.FileName(), err)))
+		}
+		form.Files[name] = append(form.Files[name], file)
+	}
+}
+
+// storeFile streams the file part to a temporary file.
+func storeFile(part *multipart.Part, opts BindOptions) (*FileHeader, error) {
+	f, err := os.CreateTemp(opts.TempDir, "shepard-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	limit := opts.maxFileBytes()
+	size, err := io.Copy(f, io.LimitReader(part, int64(limit.Bytes())+1))
+	if err == nil && uint64(size) > limit.Bytes() {
+		err = fmt.Errorf("file exceeds %s: %w", limit, ErrBodyTooLarge)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return &FileHeader{
+		Filename: part.FileName(),
+		Header:   part.Header,
+		Size:     size,
+		Path:     f.Name(),
+	}, nil
+}
+
+// contentType returns the media type of the request body in lower case and its parameters.
+func contentType(req *Request[RequestBody]) (string, map[string]string) {
+	mediaType := req.Headers.ContentType()
+	if mediaType.IsNone() {
+		return "", nil
+	}
+	return mediaType.Unwrap().Type, mediaType.Unwrap().Params
+}
+
+// hasBody returns true if the request has a body, which may be empty.
+func hasBody(req *Request[RequestBody]) bool {
+	_, empty := req.body.(noBody)
+	return req.body != nil && !empty
+}
+
+// readRequestBody reads the body of req, which must not exceed limit.
+func readRequestBody(req *Request[RequestBody], limit unit.ByteSize) ([]byte, error) {
+	if !hasBody(req) {
+		return nil, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(req.body, int64(limit.Bytes())+1))
+	if err != nil {
+		return nil, fmt.Errorf("read body failed: %w", err)
+	}
+	if uint64(len(data)) > limit.Bytes() {
+		return nil, fmt.Errorf("body exceeds %s: %w", limit, ErrBodyTooLarge)
+	}
+	return data, nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// decodeValues sets the fields of the struct target to values, or to fallback for fields without values.
+func decodeValues(values *Values, fallback *Values, target reflect.Value) error {
+	t := target.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		// the exported fields of embedded structs are promoted, even if the struct type is unexported
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := decodeValues(values, fallback, target.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldValues := values.Get(name)
+		if fieldValues.IsEmpty() {
+			fieldValues = fallback.Get(name)
+		}
+		if fieldValues.IsEmpty() {
+			continue
+		}
+		if err := setField(target.Field(i), fieldValues); err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setField sets field to values, slices get all values, other types the first one.
+func setField(field reflect.Value, values slice.Slice[string]) error {
+	if field.Kind() == reflect.Slice && !field.Type().Implements(textUnmarshalerType) && field.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(field.Type(), values.Len(), values.Len())
+		var err error
+		values.Iter().Foreach(func(i int, value string) {
+			if err == nil {
+				err = setValue(s.Index(i), value)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		field.Set(s)
+		return nil
+	}
+	return setValue(field, *values.First().Unwrap())
+}
+
+// setValue parses value into v.
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		// a checked checkbox without a value is sent as "on"
+		b, err := strconv.ParseBool(value)
+		if value == "on" {
+			b, err = true, nil
+		}
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		return setNumber[int](v, value)
+	case reflect.Int8:
+		return setNumber[int8](v, value)
+	case reflect.Int16:
+		return setNumber[int16](v, value)
+	case reflect.Int32:
+		return setNumber[int32](v, value)
+	case reflect.Int64:
+		return setNumber[int64](v, value)
+	case reflect.Uint:
+		return setNumber[uint](v, value)
+	case reflect.Uint8:
+		return setNumber[uint8](v, value)
+	case reflect.Uint16:
+		return setNumber[uint16](v, value)
+	case reflect.Uint32:
+		return setNumber[uint32](v, value)
+	case reflect.Uint64:
+		return setNumber[uint64](v, value)
+	case reflect.Float32:
+		return setNumber[float32](v, value)
+	case reflect.Float64:
+		return setNumber[float64](v, value)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// setNumber parses value into the numeric v of kind T. Failures are *num.ParseError, telling whether the value was
+// empty, invalid or out of range.
+func setNumber[T num.Number](v reflect.Value, value string) error {
+	n := num.ParseString[T](value)
+	if n.IsErr() {
+		return n.UnwrapErr()
+	}
+	v.Set(reflect.ValueOf(n.Unwrap()).Convert(v.Type()))
+	return nil
+}
@@ -0,0 +1,269 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/unit"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// newBodyRequest returns a request with the body and the Content-Type header.
+func newBodyRequest(method Method, uri string, contentType string, body string) *Request[RequestBody] {
+	req := newTestRequest(method, uri)
+	if contentType != "" {
+		req.Headers.Set("Content-Type", contentType)
+	}
+	req.body = strings.NewReader(body)
+	return req
+}
+
+func bindStatus(err error) StatusCode {
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		return bindErr.Status
+	}
+	return 0
+}
+
+func TestBindJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	user := BindJSON[testUser](newBodyRequest(MethodPost, "/", "application/json; charset=utf-8", `{"name": "alice", "age": 42}`))
+	assert.Equal(shepard.Ok[testUser, error](testUser{Name: "alice", Age: 42}), user)
+
+	req := newBodyRequest(MethodPost, "/", "application/problem+json", `{"name": "bob", "unknown": true}`)
+	req.Headers = Headers{}.Default()
+	req.Headers.Set("content-type", "application/problem+json")
+	assert.Equal(testUser{Name: "bob"}, BindJSON[testUser](req).Unwrap())
+
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		body        string
+		opts        BindOptions
+		status      StatusCode
+	}{
+		{name: "missing content type", body: `{}`, status: StatusCodeUnsupportedMediaType},
+		{name: "wrong content type", contentType: "text/plain", body: `{}`, status: StatusCodeUnsupportedMediaType},
+		{name: "empty body", contentType: "application/json", body: ` `, status: StatusCodeBadRequest},
+		{name: "invalid json", contentType: "application/json", body: `{"name": }`, status: StatusCodeBadRequest},
+		{name: "wrong type", contentType: "application/json", body: `{"age": "old"}`, status: StatusCodeBadRequest},
+		{name: "trailing data", contentType: "application/json", body: `{} {}`, status: StatusCodeBadRequest},
+		{name: "unknown field", contentType: "application/json", body: `{"email": "a@b.c"}`, opts: BindOptions{DisallowUnknownFields: true}, status: StatusCodeBadRequest},
+		{name: "too large", contentType: "application/json", body: `{"name": "` + strings.Repeat("a", 64) + `"}`, opts: BindOptions{MaxBytes: 32}, status: StatusCodeRequestEntityTooLarge},
+	} {
+		res := BindJSONWith[testUser](newBodyRequest(MethodPost, "/", tc.contentType, tc.body), tc.opts)
+		assert.True(res.IsErr(), tc.name)
+		assert.Equal(tc.status, bindStatus(res.UnwrapErr()), tc.name)
+	}
+}
+
+type testForm struct {
+	Name     string        `form:"name"`
+	Age      uint8         `form:"age"`
+	Admin    bool          `form:"admin"`
+	Tags     []string      `form:"tag"`
+	Score    *float64      `form:"score"`
+	Timeout  time.Duration `form:"timeout"`
+	Quota    unit.ByteSize `form:"quota"`
+	Ignored  string        `form:"-"`
+	Page     int
+	internal string
+	testPaging
+}
+
+type testPaging struct {
+	Limit int `form:"limit"`
+}
+
+func TestBindForm(t *testing.T) {
+	assert := assert.New(t)
+
+	req := newBodyRequest(MethodPost, "/?limit=10&name=query&Page=2", "application/x-www-form-urlencoded",
+		"name=alice&age=42&admin=on&tag=a&tag=b&score=1.5&timeout=1500000000&quota=1KiB&Ignored=x&internal=x")
+	form := BindForm[testForm](req).Unwrap()
+
+	score := 1.5
+	assert.Equal(testForm{
+		Name:       "alice",
+		Age:        42,
+		Admin:      true,
+		Tags:       []string{"a", "b"},
+		Score:      &score,
+		Timeout:    1500 * time.Millisecond,
+		Quota:      unit.KiB,
+		Page:       2,
+		testPaging: testPaging{Limit: 10},
+	}, form)
+
+	// requests without a body bind the query
+	assert.Equal(testForm{Name: "bob"}, BindForm[testForm](newTestRequest(MethodGet, "/?name=bob")).Unwrap())
+
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		body        string
+		status      StatusCode
+	}{
+		{name: "wrong content type", contentType: "application/json", body: `{}`, status: StatusCodeUnsupportedMediaType},
+		{name: "overflow", contentType: "application/x-www-form-urlencoded", body: "age=256", status: StatusCodeBadRequest},
+		{name: "not a bool", contentType: "application/x-www-form-urlencoded", body: "admin=maybe", status: StatusCodeBadRequest},
+		{name: "invalid size", contentType: "application/x-www-form-urlencoded", body: "quota=lots", status: StatusCodeBadRequest},
+	} {
+		res := BindForm[testForm](newBodyRequest(MethodPost, "/", tc.contentType, tc.body))
+		assert.True(res.IsErr(), tc.name)
+		assert.Equal(tc.status, bindStatus(res.UnwrapErr()), tc.name)
+	}
+
+	// number errors name the field and the reason
+	for _, tc := range []struct {
+		body  string
+		field string
+		err   error
+	}{
+		{body: "age=256", field: "age", err: num.ErrOverflow},
+		{body: "age=", field: "age", err: num.ErrEmpty},
+		{body: "age=4x", field: "age", err: num.ErrSyntax},
+		{body: "score=1.5.1", field: "score", err: num.ErrSyntax},
+	} {
+		err := BindForm[testForm](newBodyRequest(MethodPost, "/", "application/x-www-form-urlencoded", tc.body)).UnwrapErr()
+		var parseErr *num.ParseError
+		assert.ErrorAs(err, &parseErr, tc.body)
+		assert.ErrorIs(err, tc.err, tc.body)
+		assert.Contains(err.Error(), `field "`+tc.field+`"`, tc.body)
+	}
+
+	assert.True(BindForm[string](newTestRequest(MethodGet, "/")).IsErr())
+}
+
+// multipartBody returns a multipart form with the values and a file per entry of files, and its content type.
+func multipartBody(values map[string]string, files map[string]string) (string, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, value := range values {
+		w.WriteField(name, value)
+	}
+	for name, content := range files {
+		part, _ := w.CreateFormFile(name, name+".txt")
+		io.WriteString(part, content)
+	}
+	w.Close()
+	return buf.String(), w.FormDataContentType()
+}
+
+func TestParseMultipartForm(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	body, contentType := multipartBody(map[string]string{"name": "alice"}, map[string]string{"avatar": "image data"})
+
+	form := ParseMultipartForm(newBodyRequest(MethodPost, "/", contentType, body), BindOptions{TempDir: dir}).Unwrap()
+	assert.Equal("alice", *form.Values.Get("name").First().Unwrap())
+	assert.Len(form.Files["avatar"], 1)
+
+	file := form.Files["avatar"][0]
+	assert.Equal("avatar.txt", file.Filename)
+	assert.Equal(int64(10), file.Size)
+	f, err := file.Open()
+	assert.NoError(err)
+	content, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal("image data", string(content))
+
+	assert.NoError(form.RemoveAll())
+	_, err = os.Stat(file.Path)
+	assert.True(os.IsNotExist(err))
+
+	// too large files are removed
+	res := ParseMultipartForm(newBodyRequest(MethodPost, "/", contentType, body), BindOptions{TempDir: dir, MaxFileBytes: 4})
+	assert.Equal(StatusCodeRequestEntityTooLarge, bindStatus(res.UnwrapErr()))
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(entries)
+
+	// too many files
+	many, manyContentType := multipartBody(nil, map[string]string{"a": "1", "b": "2", "c": "3"})
+	res = ParseMultipartForm(newBodyRequest(MethodPost, "/", manyContentType, many), BindOptions{TempDir: dir, MaxFiles: 2})
+	assert.Equal(StatusCodeRequestEntityTooLarge, bindStatus(res.UnwrapErr()))
+	entries, _ = os.ReadDir(dir)
+	assert.Empty(entries)
+	form = ParseMultipartForm(newBodyRequest(MethodPost, "/", manyContentType, many), BindOptions{TempDir: dir, MaxFiles: 3}).Unwrap()
+	assert.Len(form.Files, 3)
+	assert.NoError(form.RemoveAll())
+
+	// files are limited by default
+	assert.Equal(DefaultMaxFileBytes, BindOptions{}.maxFileBytes())
+	assert.Equal(DefaultMaxFileBytes, BindOptions{}.Default().MaxFileBytes)
+
+	res = ParseMultipartForm(newBodyRequest(MethodPost, "/", contentType, body), BindOptions{TempDir: dir, MaxBytes: 2})
+	assert.Equal(StatusCodeRequestEntityTooLarge, bindStatus(res.UnwrapErr()))
+
+	res = ParseMultipartForm(newBodyRequest(MethodPost, "/", "multipart/form-data", body), BindOptions{})
+	assert.Equal(StatusCodeBadRequest, bindStatus(res.UnwrapErr()))
+
+	res = ParseMultipartForm(newBodyRequest(MethodPost, "/", "text/plain", body), BindOptions{})
+	assert.Equal(StatusCodeUnsupportedMediaType, bindStatus(res.UnwrapErr()))
+
+	// forms bind multipart bodies
+	type upload struct {
+		Name string `form:"name"`
+	}
+	assert.Equal(upload{Name: "alice"}, BindFormWith[upload](newBodyRequest(MethodPost, "/", contentType, body), BindOptions{TempDir: dir}).Unwrap())
+	entries, _ = os.ReadDir(dir)
+	assert.Empty(entries)
+}
+
+func TestBind(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Post("/users", Bind(BindJSON[testUser], func(req *Request[RequestBody], user testUser) Response[Body] {
+		return textHandler("created " + user.Name)(req)
+	})))
+	r.Route(Post("/middleware", textHandler("unreachable")).Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		if res := BindJSON[testUser](req); res.IsErr() {
+			return shepard.Err[Response[Body], error](res.UnwrapErr())
+		}
+		return next()
+	}))
+
+	res := r.Serve(newBodyRequest(MethodPost, "/users", "application/json", `{"name": "alice"}`)).Unwrap()
+	assert.Equal("created alice", readBody(res))
+
+	res = r.Serve(newBodyRequest(MethodPost, "/users", "text/plain", `alice`)).Unwrap()
+	assert.Equal(StatusCodeUnsupportedMediaType, res.StatusCode())
+
+	res = r.Serve(newBodyRequest(MethodPost, "/users", "application/json", `{`)).Unwrap()
+	assert.Equal(StatusCodeBadRequest, res.StatusCode())
+	assert.Contains(readBody(res), "decode failed")
+
+	// bind errors of middleware are answered by the server
+	s := &Server{Handler: r}
+	addr, _ := startServer(t, s)
+	defer s.Shutdown(context.Background())
+	client := &Client{}
+	clientRes := client.Post("http://"+addr+"/middleware", "application/json", strings.NewReader(`[]`))
+	assert.Equal(StatusCodeBadRequest, clientRes.Unwrap().StatusCode())
+}
+
+func TestParseParams(t *testing.T) {
+	values := ParseParams(strings.NewReader("a=1&b=2\r\n")).Unwrap()
+	assert.Equal(t, "2", *values.Get("b").First().Unwrap())
+
+	values = ParseParams(strings.NewReader("")).Unwrap()
+	assert.False(t, values.Has("a"))
+}
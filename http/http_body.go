@@ -1,6 +1,8 @@
 package http
 
 import (
+	"sync"
+
 	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/collections/slice"
 	"github.com/marlaone/shepard/sync/io"
@@ -33,3 +35,84 @@ func (b *BytesBody) Write(bs slice.Slice[byte]) shepard.Result[int, error] {
 func (b *BytesBody) Closed() bool {
 	return b.rwc.Closed()
 }
+
+// StreamingBody is a Body which is written while the response is sent, e.g. by a goroutine started by the handler.
+//
+// The server sends the data written so far whenever Flush is called and finishes the response on Close.
+type StreamingBody interface {
+	Body
+	// Flush sends the data written since the last flush to the client.
+	Flush()
+	// Flushed receives a value after Flush was called.
+	Flushed() <-chan struct{}
+	// Done is closed when the body is closed.
+	Done() <-chan struct{}
+}
+
+// StreamBody is a StreamingBody buffering written data until it is flushed.
+//
+// Responses with a StreamBody are sent with "Transfer-Encoding: chunked", unless a Content-Length header is set.
+type StreamBody struct {
+	rwc     *io.ReaderWriterCloserBuffer
+	flushes chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+var _ StreamingBody = (*StreamBody)(nil)
+
+func NewStreamBody() *StreamBody {
+	return &StreamBody{
+		rwc:     io.NewReaderWriterCloser(),
+		flushes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (b *StreamBody) Close() shepard.Result[shepard.Nil, error] {
+	res := b.rwc.Close()
+	b.once.Do(func() {
+		close(b.done)
+	})
+	return res
+}
+
+func (b *StreamBody) Read(bs *slice.Slice[byte]) shepard.Result[int, error] {
+	return b.rwc.Read(bs)
+}
+
+func (b *StreamBody) Write(bs slice.Slice[byte]) shepard.Result[int, error] {
+	return b.rwc.Write(bs)
+}
+
+func (b *StreamBody) Closed() bool {
+	return b.rwc.Closed()
+}
+
+func (b *StreamBody) Flush() {
+	// a pending flush already covers the data written since
+	select {
+	case b.flushes <- struct{}{}:
+	default:
+	}
+}
+
+func (b *StreamBody) Flushed() <-chan struct{} {
+	return b.flushes
+}
+
+func (b *StreamBody) Done() <-chan struct{} {
+	return b.done
+}
+
+// ReadBody reads the data buffered in body. For a StreamingBody it's the data written since the last read, a body
+// which isn't streamed is read at once.
+func ReadBody(body Body) []byte {
+	buf := slice.New[byte]()
+	body.Read(&buf)
+	data := make([]byte, 0, buf.Len())
+	buf.Iter().Foreach(func(_ int, b byte) {
+		data = append(data, b)
+	})
+	return data
+}
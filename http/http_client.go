@@ -0,0 +1,573 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/num/unit"
+)
+
+const (
+	// DefaultMaxRedirects is the number of redirects a Client follows if Client.MaxRedirects is zero.
+	DefaultMaxRedirects = 10
+	// DefaultMaxIdleConnsPerHost is the number of idle connections a Client keeps per host if Client.MaxIdleConnsPerHost is zero.
+	DefaultMaxIdleConnsPerHost = 2
+	// DefaultIdleConnTimeout is the time an idle connection is kept if Client.IdleConnTimeout is zero.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+var (
+	ErrMalformedResponse = errors.New("malformed response")
+	ErrTooManyRedirects  = errors.New("too many redirects")
+	ErrResponseTooLarge  = errors.New("response too large")
+)
+
+// Client sends HTTP/1.1 requests. Connections are kept alive and reused for further requests to the same host.
+//
+// The body of a response is read completely before it is returned, the returned Body is closed.
+// The zero Client is ready to use and safe for concurrent use.
+type Client struct {
+	// Timeout limits the time of a request including redirects and reading the response body. Zero means no timeout.
+	// Deadlines and cancellation of the request's Context are respected in any case.
+	Timeout time.Duration
+	// MaxRedirects limits the number of redirects followed, DefaultMaxRedirects if zero. Redirects aren't followed if it is negative.
+	MaxRedirects int
+	// MaxIdleConnsPerHost limits the idle connections kept per host, DefaultMaxIdleConnsPerHost if zero.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the time an idle connection is kept, DefaultIdleConnTimeout if zero.
+	IdleConnTimeout time.Duration
+	// MaxHeaderBytes limits the size of the status line and headers of a response, DefaultMaxHeaderBytes if zero.
+	MaxHeaderBytes int
+	// MaxResponseBytes limits the size of a response body. Zero means no limit.
+	MaxResponseBytes unit.ByteSize
+
+	// TLSConfig configures the connections to https URLs.
+	TLSConfig *tls.Config
+	// DialContext opens the connections, net.Dialer.DialContext if nil.
+	DialContext func(ctx context.Context, network string, addr string) (net.Conn, error)
+
+	mu   sync.Mutex
+	idle map[string][]*clientConn
+}
+
+// clientConn is a connection of a Client.
+type clientConn struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	w         *bufio.Writer
+	idleSince time.Time
+}
+
+// Get sends a GET request to rawURL.
+func (c *Client) Get(rawURL string) shepard.Result[Response[Body], error] {
+	u := ParseURL(rawURL)
+	if u.IsErr() {
+		return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Get] parse url failed: %w", u.UnwrapErr()))
+	}
+	req := NewRequestBuilder[RequestBody]().Method(MethodGet).URL(u.Unwrap()).Body(noBody{}).Unwrap()
+	return c.Do(&req)
+}
+
+// Post sends a POST request with body of the given content type to rawURL.
+func (c *Client) Post(rawURL string, contentType string, body io.Reader) shepard.Result[Response[Body], error] {
+	u := ParseURL(rawURL)
+	if u.IsErr() {
+		return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Post] parse url failed: %w", u.UnwrapErr()))
+	}
+	req := NewRequestBuilder[RequestBody]().Method(MethodPost).URL(u.Unwrap()).Header("Content-Type", contentType).Body(body).Unwrap()
+	return c.Do(&req)
+}
+
+// Do sends req and returns the response, following redirects.
+//
+// req.URL must be absolute. Basic authentication is used if the URL contains user information and no Authorization header is set.
+// Requests are sent with a Content-Length if the body has a known length, e.g. a *bytes.Reader, otherwise chunked.
+// 307 and 308 redirects of requests with a body are only followed if the body implements io.Seeker, 301, 302 and 303 redirects
+// of requests other than GET and HEAD are followed with a GET request without a body. The Authorization, Proxy-Authorization
+// and Cookie headers aren't sent after a redirect to another host.
+func (c *Client) Do(req *Request[RequestBody]) shepard.Result[Response[Body], error] {
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	out := &outgoingRequest{
+		method:  req.Method,
+		url:     req.URL,
+		headers: &req.Headers,
+		body:    req.body,
+	}
+	if seeker, ok := out.body.(io.Seeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			out.seeker, out.offset = seeker, offset
+		}
+	}
+
+	maxRedirects := c.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	for redirects := 0; ; redirects++ {
+		res := c.roundTrip(ctx, out)
+		if res.IsErr() {
+			return res
+		}
+		location := res.Unwrap().Headers().Get("Location").First()
+		next, follow, err := out.redirect(res.Unwrap().StatusCode(), location)
+		if !follow || maxRedirects < 0 {
+			return res
+		}
+		if redirects == maxRedirects {
+			return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] %d redirects followed: %w", redirects, ErrTooManyRedirects))
+		}
+		if err != nil {
+			return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] invalid redirect: %w", err))
+		}
+		out = next
+	}
+}
+
+// CloseIdleConnections closes all idle connections of the client.
+func (c *Client) CloseIdleConnections() {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.mu.Unlock()
+	for _, conns := range idle {
+		for _, cc := range conns {
+			cc.conn.Close()
+		}
+	}
+}
+
+// outgoingRequest is a request sent by a Client, which changes while redirects are followed.
+type outgoingRequest struct {
+	method  Method
+	url     URL
+	headers *Headers
+	body    RequestBody
+	// seeker rewinds the body to offset before it is sent again
+	seeker io.Seeker
+	offset int64
+	// redirected is true once a redirect has been followed
+	redirected bool
+	// stripCredentials drops the Authorization, Proxy-Authorization and Cookie headers after a redirect to another host
+	stripCredentials bool
+}
+
+// hasBody returns true if the request has a body to send.
+func (o *outgoingRequest) hasBody() bool {
+	_, empty := o.body.(noBody)
+	return o.body != nil && !empty
+}
+
+// replayable returns true if the request can be sent again.
+func (o *outgoingRequest) replayable() bool {
+	return !o.hasBody() || o.seeker != nil
+}
+
+// rewind prepares the body to be sent again.
+func (o *outgoingRequest) rewind() error {
+	if o.seeker == nil {
+		return nil
+	}
+	_, err := o.seeker.Seek(o.offset, io.SeekStart)
+	return err
+}
+
+// redirect returns the request following a response with status and location, and false if the response isn't a followable redirect.
+func (o *outgoingRequest) redirect(status StatusCode, location shepard.Option[*string]) (*outgoingRequest, bool, error) {
+	next := *o
+	switch status {
+	case StatusCodeMovedPermanently, StatusCodeFound, StatusCodeSeeOther:
+		if status == StatusCodeSeeOther || (o.method != MethodGet && o.method != MethodHead) {
+			if o.method != MethodHead {
+				next.method = MethodGet
+			}
+			next.body = noBody{}
+			next.seeker = nil
+		}
+	case StatusCodeTemporaryRedirect, StatusCodePermanentRedirect:
+		if !o.replayable() {
+			return nil, false, nil
+		}
+	default:
+		return nil, false, nil
+	}
+	if location.IsNone() {
+		return nil, false, nil
+	}
+
+	base, err := url.Parse(o.url.String())
+	if err != nil {
+		return nil, true, err
+	}
+	ref, err := base.Parse(*location.Unwrap())
+	if err != nil {
+		return nil, true, err
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return nil, true, fmt.Errorf("unsupported scheme %q", ref.Scheme)
+	}
+	u := ParseURL(ref.String())
+	if u.IsErr() {
+		return nil, true, u.UnwrapErr()
+	}
+	next.url = u.Unwrap()
+	next.redirected = true
+	next.stripCredentials = o.stripCredentials || next.url.Host != o.url.Host || next.url.Port != o.url.Port
+	return &next, true, nil
+}
+
+// roundTrip sends out on a pooled or new connection and reads the response.
+// A request failing on a reused connection before a response arrived is retried once on a new connection if it can be replayed.
+func (c *Client) roundTrip(ctx context.Context, out *outgoingRequest) shepard.Result[Response[Body], error] {
+	if out.url.Scheme != "http" && out.url.Scheme != "https" {
+		return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] unsupported scheme %q", out.url.Scheme))
+	}
+	if out.url.Host == "" {
+		return shepard.Err[Response[Body], error](errors.New("[http.Client.Do] url has no host"))
+	}
+	port := out.url.Port
+	if port == 0 {
+		port = defaultPort(out.url.Scheme)
+	}
+	addr := net.JoinHostPort(out.url.Host, strconv.Itoa(int(port)))
+	key := out.url.Scheme + "://" + addr
+
+	for attempt := 0; ; attempt++ {
+		if err := out.rewind(); err != nil {
+			return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] rewind body failed: %w", err))
+		}
+		cc, reused := c.getConn(key)
+		if cc == nil {
+			var err error
+			cc, err = c.dial(ctx, out.url.Scheme, out.url.Host, addr)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					err = ctxErr
+				}
+				return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] dial %s failed: %w", addr, err))
+			}
+		}
+
+		res, keepAlive, received, err := c.exchange(ctx, cc, out)
+		if err != nil {
+			cc.conn.Close()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] %w", ctxErr))
+			}
+			// the server may have closed the idle connection in the meantime
+			if reused && !received && attempt == 0 && out.replayable() {
+				continue
+			}
+			return shepard.Err[Response[Body], error](fmt.Errorf("[http.Client.Do] %w", err))
+		}
+		if keepAlive {
+			c.putConn(key, cc)
+		} else {
+			cc.conn.Close()
+		}
+		return shepard.Ok[Response[Body], error](res)
+	}
+}
+
+// exchange writes out to cc and reads the response. received is true if any part of the response was received.
+func (c *Client) exchange(ctx context.Context, cc *clientConn, out *outgoingRequest) (res Response[Body], keepAlive bool, received bool, err error) {
+	// abort blocked reads and writes when ctx is done, after ctx.Err reports why
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			cc.conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+		cc.conn.SetDeadline(time.Time{})
+	}()
+
+	if err := c.writeRequest(cc.w, out); err != nil {
+		return nil, false, false, fmt.Errorf("write request failed: %w", err)
+	}
+
+	maxHeaderBytes := c.MaxHeaderBytes
+	if maxHeaderBytes <= 0 {
+		maxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	return readClientResponse(cc.r, out.method, maxHeaderBytes, c.MaxResponseBytes)
+}
+
+// writeRequest writes the request line, headers and body of out.
+func (c *Client) writeRequest(w *bufio.Writer, out *outgoingRequest) error {
+	w.WriteString(out.method.String() + " " + out.url.requestTarget() + " HTTP/1.1\r\n")
+
+	// headers computed for every request replace the ones of the request
+	skip := map[string]bool{"content-length": true, "transfer-encoding": true}
+	if out.redirected {
+		skip["host"] = true
+		if !out.hasBody() {
+			skip["content-type"] = true
+		}
+	}
+	if out.stripCredentials {
+		skip["authorization"] = true
+		skip["proxy-authorization"] = true
+		skip["cookie"] = true
+	}
+	hasHost, hasAuth := false, false
+	out.headers.Iter().Foreach(func(_ int, h hashmap.Pair[*string, *slice.Slice[string]]) {
+		name := strings.ToLower(*h.Key)
+		if skip[name] {
+			return
+		}
+		hasHost = hasHost || name == "host"
+		hasAuth = hasAuth || name == "authorization"
+		values := make([]string, 0, h.Value.Len())
+		h.Value.Iter().Foreach(func(_ int, v string) {
+			values = append(values, v)
+		})
+		w.WriteString(*h.Key + ": " + strings.Join(values, ", ") + "\r\n")
+	})
+	if !hasHost {
+		w.WriteString("Host: " + out.url.hostPort() + "\r\n")
+	}
+	if !hasAuth && out.url.User != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(out.url.User.Username + ":" + out.url.User.Password))
+		w.WriteString("Authorization: Basic " + credentials + "\r\n")
+	}
+
+	if !out.hasBody() {
+		if out.method == MethodPost || out.method == MethodPut || out.method == MethodPatch {
+			w.WriteString("Content-Length: 0\r\n")
+		}
+		w.WriteString("\r\n")
+		return w.Flush()
+	}
+
+	length := int64(-1)
+	if values := out.headers.Get("Content-Length"); values.Len() == 1 && !out.redirected {
+		if n, err := strconv.ParseInt(*values.First().Unwrap(), 10, 64); err == nil {
+			length = n
+		}
+	}
+	if sized, ok := out.body.(interface{ Len() int }); ok && length < 0 {
+		length = int64(sized.Len())
+	}
+
+	if length >= 0 {
+		w.WriteString("Content-Length: " + strconv.FormatInt(length, 10) + "\r\n\r\n")
+		if _, err := io.CopyN(w, out.body, length); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	w.WriteString("Transfer-Encoding: chunked\r\n\r\n")
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := out.body.Read(buf)
+		writeChunk(w, buf[:n], true)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	w.WriteString("0\r\n\r\n")
+	return w.Flush()
+}
+
+// readClientResponse reads a response to a request with method from r, skipping informational responses.
+// The body is read completely, up to maxBodyBytes if it isn't zero.
+func readClientResponse(r *bufio.Reader, method Method, maxHeaderBytes int, maxBodyBytes unit.ByteSize) (Response[Body], bool, bool, error) {
+	received := false
+	for {
+		budget := maxHeaderBytes
+		line, err := readLine(r, budget)
+		if err != nil {
+			return nil, false, received, fmt.Errorf("read status line failed: %w", err)
+		}
+		received = true
+		budget -= len(line) + 2
+
+		// status line: HTTP/1.1 200 OK
+		proto, rest, _ := strings.Cut(string(line), " ")
+		code, _, _ := strings.Cut(rest, " ")
+		version := strings.TrimPrefix(proto, "HTTP/")
+		status, err := strconv.Atoi(code)
+		if len(version) != 3 || version[0] != '1' || version[1] != '.' || !isDigit(version[2]) || len(code) != 3 || err != nil {
+			return nil, false, received, fmt.Errorf("invalid status line %q: %w", line, ErrMalformedResponse)
+		}
+
+		res := NewHttpResponseBytes()
+		res.SetStatusCode(StatusCode(status))
+		res.SetVersion(Version(version))
+
+		for {
+			line, err := readLine(r, budget)
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return nil, false, received, fmt.Errorf("read header line failed: %w", err)
+			}
+			budget -= len(line) + 2
+			if len(line) == 0 {
+				break
+			}
+			key, value, found := strings.Cut(string(line), string(headerKeySeparator))
+			if !found || key == "" || strings.ContainsAny(key, " \t") {
+				return nil, false, received, fmt.Errorf("invalid header line %q: %w", line, ErrMalformedResponse)
+			}
+			res.Headers().Add(key, strings.TrimSpace(value))
+		}
+
+		// informational responses precede the final response
+		if status >= 100 && status < 200 {
+			continue
+		}
+
+		headers := res.Headers()
+		keepAlive := !shouldClose(res.Version(), headers.List("Connection"))
+		transferEncoding := headers.List("Transfer-Encoding")
+
+		var body io.Reader = noBody{}
+		switch {
+		case method == MethodHead || !res.StatusCode().bodyAllowed():
+		case len(transferEncoding) > 0:
+			if !strings.EqualFold(transferEncoding[len(transferEncoding)-1], "chunked") {
+				return nil, false, received, fmt.Errorf("transfer encoding %q: %w", transferEncoding, ErrUnsupportedTransferEncoding)
+			}
+			body = &chunkedBody{r: r, maxTrailerBytes: maxHeaderBytes}
+		case headers.Has("Content-Length"):
+			n := headers.ContentLength()
+			if n.IsNone() {
+				return nil, false, received, fmt.Errorf("invalid content length %q: %w", headers.Value("Content-Length"), ErrMalformedResponse)
+			}
+			body = &lengthBody{r: r, remaining: n.Unwrap()}
+		default:
+			// the body ends when the server closes the connection
+			body = r
+			keepAlive = false
+		}
+
+		if maxBodyBytes > 0 {
+			body = io.LimitReader(body, int64(maxBodyBytes.Bytes())+1)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, false, received, fmt.Errorf("read body failed: %w", err)
+		}
+		if maxBodyBytes > 0 && uint64(len(data)) > maxBodyBytes.Bytes() {
+			return nil, false, received, fmt.Errorf("body exceeds %s: %w", maxBodyBytes, ErrResponseTooLarge)
+		}
+		res.Body().Write(slice.Init(data...))
+		res.Finish()
+		return res, keepAlive, received, nil
+	}
+}
+
+// dial opens a connection to addr, with a TLS handshake for the host if scheme is https.
+func (c *Client) dial(ctx context.Context, scheme string, host string, addr string) (*clientConn, error) {
+	dial := c.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheme == "https" {
+		config := &tls.Config{}
+		if c.TLSConfig != nil {
+			config = c.TLSConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+		if len(config.NextProtos) == 0 {
+			config.NextProtos = []string{"http/1.1"}
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	return &clientConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}, nil
+}
+
+// getConn returns the most recently used idle connection for key, and whether one was found.
+// Connections idle for longer than the idle timeout are closed.
+func (c *Client) getConn(key string) (*clientConn, bool) {
+	timeout := c.IdleConnTimeout
+	if timeout <= 0 {
+		timeout = DefaultIdleConnTimeout
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	conns := c.idle[key]
+	for len(conns) > 0 {
+		cc := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		if time.Since(cc.idleSince) > timeout {
+			cc.conn.Close()
+			continue
+		}
+		c.idle[key] = conns
+		return cc, true
+	}
+	delete(c.idle, key)
+	return nil, false
+}
+
+// putConn returns cc to the idle connections of key, or closes it if there are enough.
+func (c *Client) putConn(key string, cc *clientConn) {
+	limit := c.MaxIdleConnsPerHost
+	if limit <= 0 {
+		limit = DefaultMaxIdleConnsPerHost
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.idle[key]) >= limit {
+		cc.conn.Close()
+		return
+	}
+	if c.idle == nil {
+		c.idle = make(map[string][]*clientConn)
+	}
+	cc.idleSince = time.Now()
+	c.idle[key] = append(c.idle[key], cc)
+}
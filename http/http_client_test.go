@@ -0,0 +1,314 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/num/unit"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingListener counts the accepted connections.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+// startClientTestServer serves r on a random local port and returns its base URL and the listener counting connections.
+func startClientTestServer(t *testing.T, r *Router) (string, *countingListener, *Server) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: l}
+	s := &Server{Handler: r}
+	go s.Serve(counting)
+	t.Cleanup(func() {
+		s.Shutdown(context.Background())
+	})
+	return "http://" + l.Addr().String(), counting, s
+}
+
+// echoHandler responds with the method, the Authorization and Content-Type headers and the body of the request.
+func echoHandler(req *Request[RequestBody]) Response[Body] {
+	body, _ := io.ReadAll(req.body)
+	auth := req.Headers.Get("Authorization").First()
+	contentType := req.Headers.Get("Content-Type").First()
+	text := req.Method.String() + " " + req.URL.requestTarget()
+	if auth.IsSome() {
+		text += " auth=" + *auth.Unwrap()
+	}
+	if contentType.IsSome() {
+		text += " type=" + *contentType.Unwrap()
+	}
+	return textHandler(text + " body=" + string(body))(req)
+}
+
+func redirectHandler(status StatusCode, location string) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		res := textHandler("")(req)
+		res.SetStatusCode(status)
+		res.SetHeader("Location", location)
+		return res
+	}
+}
+
+func newClientRequest(method Method, rawURL string, body RequestBody) *Request[RequestBody] {
+	req := NewRequestBuilder[RequestBody]().Method(method).URL(ParseURL(rawURL).Unwrap()).Body(body).Unwrap()
+	return &req
+}
+
+func TestClient_KeepAlive(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/hello", textHandler("hello")))
+	r.Route(Get("/stream", streamHandler("a", "b")))
+	r.Route(Post("/echo", echoHandler))
+	base, l, _ := startClientTestServer(t, r)
+
+	client := &Client{}
+	for i := 0; i < 3; i++ {
+		res := client.Get(base + "/hello")
+		assert.NoError(res.Err().UnwrapOr(nil))
+		assert.Equal(StatusCodeOk, res.Unwrap().StatusCode())
+		assert.Equal(Version("1.1"), res.Unwrap().Version())
+		assert.Equal("hello", readBody(res.Unwrap()))
+		assert.True(res.Unwrap().Body().Closed())
+	}
+	assert.Equal("ab", readBody(client.Get(base+"/stream").Unwrap()))
+
+	// bodies with a known length and streamed bodies
+	res := client.Post(base+"/echo", "text/plain", strings.NewReader("sized"))
+	assert.Equal("POST /echo type=text/plain body=sized", readBody(res.Unwrap()))
+	res = client.Post(base+"/echo", "text/plain", iotest.OneByteReader(strings.NewReader("chunked")))
+	assert.Equal("POST /echo type=text/plain body=chunked", readBody(res.Unwrap()))
+
+	res = client.Do(newClientRequest(MethodHead, base+"/hello", noBody{}))
+	assert.Equal(slice.Init("5"), res.Unwrap().Headers().Get("Content-Length"))
+	assert.Equal("", readBody(res.Unwrap()))
+
+	assert.Equal(int32(1), l.accepted.Load())
+
+	client.CloseIdleConnections()
+	client.Get(base + "/hello")
+	assert.Equal(int32(2), l.accepted.Load())
+}
+
+func TestClient_StaleConnection(t *testing.T) {
+	assert := assert.New(t)
+
+	base, l, s := startClientTestServer(t, NewRouter().Route(Get("/", textHandler("ok"))))
+	s.IdleTimeout = 20 * time.Millisecond
+
+	client := &Client{}
+	assert.Equal("ok", readBody(client.Get(base+"/").Unwrap()))
+
+	// the server closed the idle connection, the request is retried on a new one
+	time.Sleep(100 * time.Millisecond)
+	res := client.Get(base + "/")
+	assert.True(res.IsOk(), res.Err().UnwrapOr(nil))
+	assert.Equal("ok", readBody(res.Unwrap()))
+	assert.Equal(int32(2), l.accepted.Load())
+}
+
+func TestClient_Redirects(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/a", redirectHandler(StatusCodeFound, "/b")))
+	r.Route(Get("/b", redirectHandler(StatusCodeMovedPermanently, "c?x=1")))
+	r.Route(Get("/c", echoHandler))
+	r.Route(Get("/loop", redirectHandler(StatusCodeFound, "/loop")))
+	r.Route(Post("/see-other", redirectHandler(StatusCodeSeeOther, "/c")))
+	r.Route(Post("/temporary", redirectHandler(StatusCodeTemporaryRedirect, "/echo")))
+	r.Route(Post("/echo", echoHandler))
+	base, _, _ := startClientTestServer(t, r)
+
+	client := &Client{}
+	assert.Equal("GET /c?x=1 body=", readBody(client.Get(base+"/a").Unwrap()))
+
+	res := client.Get(base + "/loop")
+	assert.ErrorIs(res.UnwrapErr(), ErrTooManyRedirects)
+
+	// redirects aren't followed
+	res = (&Client{MaxRedirects: -1}).Get(base + "/a")
+	assert.Equal(StatusCodeFound, res.Unwrap().StatusCode())
+	assert.Equal(slice.Init("/b"), res.Unwrap().Headers().Get("Location"))
+
+	res = (&Client{MaxRedirects: 1}).Get(base + "/a")
+	assert.ErrorIs(res.UnwrapErr(), ErrTooManyRedirects)
+
+	// 303 changes the method to GET and drops the body
+	res = client.Post(base+"/see-other", "text/plain", strings.NewReader("data"))
+	assert.Equal("GET /c body=", readBody(res.Unwrap()))
+
+	// 307 keeps the method and sends the body again
+	res = client.Post(base+"/temporary", "text/plain", bytes.NewReader([]byte("data")))
+	assert.Equal("POST /echo type=text/plain body=data", readBody(res.Unwrap()))
+
+	// a body which can't be sent again isn't redirected
+	res = client.Post(base+"/temporary", "text/plain", iotest.OneByteReader(strings.NewReader("data")))
+	assert.Equal(StatusCodeTemporaryRedirect, res.Unwrap().StatusCode())
+}
+
+func TestClient_Redirects_OtherHost(t *testing.T) {
+	assert := assert.New(t)
+
+	// credentialsHandler responds with the credential headers of the request
+	credentialsHandler := func(req *Request[RequestBody]) Response[Body] {
+		text := ""
+		for _, key := range []string{"Authorization", "Proxy-Authorization", "Cookie"} {
+			if value := req.Headers.Get(key).First(); value.IsSome() {
+				text += key + "=" + *value.Unwrap() + ";"
+			}
+		}
+		return textHandler(text)(req)
+	}
+	other := NewRouter()
+	other.Route(Get("/credentials", credentialsHandler))
+	otherBase, _, _ := startClientTestServer(t, other)
+
+	r := NewRouter()
+	r.Route(Get("/same", redirectHandler(StatusCodeFound, "/credentials")))
+	r.Route(Get("/other", redirectHandler(StatusCodeFound, otherBase+"/credentials")))
+	r.Route(Get("/credentials", credentialsHandler))
+	base, _, _ := startClientTestServer(t, r)
+
+	client := &Client{}
+	newCredentialsRequest := func(uri string) *Request[RequestBody] {
+		req := newClientRequest(MethodGet, uri, noBody{})
+		req.Headers.Set("Authorization", "Bearer token")
+		req.Headers.Set("Proxy-Authorization", "Basic cHJveHk=")
+		req.Headers.Set("Cookie", "session=1")
+		return req
+	}
+	assert.Equal("Authorization=Bearer token;Proxy-Authorization=Basic cHJveHk=;Cookie=session=1;",
+		readBody(client.Do(newCredentialsRequest(base+"/same")).Unwrap()))
+	assert.Equal("", readBody(client.Do(newCredentialsRequest(base+"/other")).Unwrap()))
+}
+
+func TestClient_BasicAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/", echoHandler))
+	base, _, _ := startClientTestServer(t, r)
+
+	client := &Client{}
+	withUser := strings.Replace(base, "http://", "http://alice:s3cret@", 1)
+	expected := "GET / auth=Basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3cret")) + " body="
+	assert.Equal(expected, readBody(client.Get(withUser+"/").Unwrap()))
+
+	// an explicit Authorization header wins
+	req := newClientRequest(MethodGet, withUser+"/", noBody{})
+	req.Headers.Set("Authorization", "Bearer token")
+	assert.Equal("GET / auth=Bearer token body=", readBody(client.Do(req).Unwrap()))
+}
+
+func TestClient_Timeouts(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	r := NewRouter()
+	r.Route(Get("/slow", func(req *Request[RequestBody]) Response[Body] {
+		<-release
+		return textHandler("slow")(req)
+	}))
+	base, _, _ := startClientTestServer(t, r)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := newClientRequest(MethodGet, base+"/slow", noBody{})
+	req.Context = ctx
+	start := time.Now()
+	res := (&Client{}).Do(req)
+	assert.ErrorIs(res.UnwrapErr(), context.DeadlineExceeded)
+	assert.Less(time.Since(start), time.Second)
+
+	// cancellation
+	ctx, cancel = context.WithCancel(context.Background())
+	req = newClientRequest(MethodGet, base+"/slow", noBody{})
+	req.Context = ctx
+	time.AfterFunc(20*time.Millisecond, cancel)
+	assert.ErrorIs((&Client{}).Do(req).UnwrapErr(), context.Canceled)
+
+	res = (&Client{Timeout: 50 * time.Millisecond}).Get(base + "/slow")
+	assert.ErrorIs(res.UnwrapErr(), context.DeadlineExceeded)
+}
+
+func TestClient_MaxResponseBytes(t *testing.T) {
+	base, _, _ := startClientTestServer(t, NewRouter().Route(Get("/", textHandler(strings.Repeat("a", 2048)))))
+
+	res := (&Client{MaxResponseBytes: unit.KiB}).Get(base + "/")
+	assert.ErrorIs(t, res.UnwrapErr(), ErrResponseTooLarge)
+
+	res = (&Client{MaxResponseBytes: 2 * unit.KiB}).Get(base + "/")
+	assert.Equal(t, 2048, len(readBody(res.Unwrap())))
+}
+
+func TestClient_TLS(t *testing.T) {
+	assert := assert.New(t)
+
+	certFile, keyFile, pool := selfSignedCert(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	s := &Server{Handler: NewRouter().Route(Get("/", urlHandler))}
+	go s.ServeTLS(l, certFile, keyFile)
+	defer s.Shutdown(context.Background())
+
+	port := l.Addr().(*net.TCPAddr).Port
+	client := &Client{TLSConfig: &tls.Config{RootCAs: pool}}
+	res := client.Get("https://localhost:" + strconv.Itoa(port) + "/")
+	assert.True(res.IsOk(), res.Err().UnwrapOr(nil))
+	assert.Equal("https://localhost:"+strconv.Itoa(port), readBody(res.Unwrap()))
+
+	// the certificate isn't trusted
+	res = (&Client{}).Get("https://localhost:" + strconv.Itoa(port) + "/")
+	assert.True(res.IsErr())
+}
+
+func TestClient_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &Client{}
+	assert.True(client.Get("ftp://example.com/").IsErr())
+	assert.True(client.Get("/relative").IsErr())
+
+	// malformed response
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Read(make([]byte, 1024))
+		conn.Write([]byte("HTTP/1.1 OK\r\n\r\n"))
+	}()
+	res := client.Get("http://" + l.Addr().String() + "/")
+	assert.ErrorIs(res.UnwrapErr(), ErrMalformedResponse)
+}
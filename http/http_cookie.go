@@ -0,0 +1,266 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard"
+)
+
+// ErrInvalidCookie is returned for cookies which can't be serialized or parsed.
+var ErrInvalidCookie = errors.New("invalid cookie")
+
+// SameSite is the SameSite attribute of a cookie, which controls whether it is sent with cross-site requests.
+type SameSite int
+
+const (
+	// SameSiteDefault omits the attribute, browsers treat the cookie as SameSiteLax.
+	SameSiteDefault SameSite = iota
+	SameSiteLax
+	SameSiteStrict
+	// SameSiteNone sends the cookie with cross-site requests, it requires Secure.
+	SameSiteNone
+)
+
+func (s SameSite) String() string {
+	switch s {
+	case SameSiteLax:
+		return "Lax"
+	case SameSiteStrict:
+		return "Strict"
+	case SameSiteNone:
+		return "None"
+	}
+	return ""
+}
+
+// Cookie is an HTTP cookie of RFC 6265, sent by clients in the Cookie header and set by servers with Set-Cookie.
+type Cookie struct {
+	Name  string
+	Value string
+
+	// Path and Domain restrict the requests the cookie is sent with, they are omitted if empty.
+	Path   string
+	Domain string
+	// Expires is the time the cookie expires, it is omitted if zero.
+	Expires time.Time
+	// MaxAge is the lifetime of the cookie in seconds, it is omitted if zero. A negative MaxAge deletes the cookie.
+	MaxAge int
+	// Secure restricts the cookie to https requests.
+	Secure bool
+	// HttpOnly hides the cookie from scripts.
+	HttpOnly bool
+	SameSite SameSite
+}
+
+// Valid returns an error wrapping ErrInvalidCookie if the cookie can't be serialized.
+func (c Cookie) Valid() error {
+	if !isToken(c.Name) {
+		return fmt.Errorf("[http.Cookie.Valid] invalid name %q: %w", c.Name, ErrInvalidCookie)
+	}
+	for i := 0; i < len(c.Value); i++ {
+		if !isCookieValueByte(c.Value[i]) && c.Value[i] != ' ' && c.Value[i] != ',' {
+			return fmt.Errorf("[http.Cookie.Valid] invalid value %q: %w", c.Value, ErrInvalidCookie)
+		}
+	}
+	if strings.ContainsAny(c.Path, ";\r\n") || strings.ContainsAny(c.Domain, "; \r\n") {
+		return fmt.Errorf("[http.Cookie.Valid] invalid path or domain: %w", ErrInvalidCookie)
+	}
+	if c.SameSite == SameSiteNone && !c.Secure {
+		return fmt.Errorf("[http.Cookie.Valid] SameSite=None requires Secure: %w", ErrInvalidCookie)
+	}
+	return nil
+}
+
+// String returns the cookie serialized for the Set-Cookie header, e.g. "id=a3fWa; Path=/; HttpOnly; SameSite=Lax".
+// Values containing spaces or commas are quoted.
+func (c Cookie) String() string {
+	var b strings.Builder
+	b.WriteString(c.Name + "=")
+	if strings.ContainsAny(c.Value, " ,") {
+		b.WriteString(`"` + c.Value + `"`)
+	} else {
+		b.WriteString(c.Value)
+	}
+	if c.Path != "" {
+		b.WriteString("; Path=" + c.Path)
+	}
+	if c.Domain != "" {
+		b.WriteString("; Domain=" + strings.TrimPrefix(c.Domain, "."))
+	}
+	if !c.Expires.IsZero() {
+		b.WriteString("; Expires=" + c.Expires.UTC().Format(TimeFormat))
+	}
+	if c.MaxAge > 0 {
+		b.WriteString("; Max-Age=" + strconv.Itoa(c.MaxAge))
+	} else if c.MaxAge < 0 {
+		b.WriteString("; Max-Age=0")
+	}
+	if c.Secure {
+		b.WriteString("; Secure")
+	}
+	if c.HttpOnly {
+		b.WriteString("; HttpOnly")
+	}
+	if c.SameSite != SameSiteDefault {
+		b.WriteString("; SameSite=" + c.SameSite.String())
+	}
+	return b.String()
+}
+
+// ParseCookies parses the value of a Cookie header, e.g. "id=a3fWa; theme=dark". Invalid pairs are skipped.
+func ParseCookies(header string) []Cookie {
+	cookies := []Cookie{}
+	for _, pair := range strings.Split(header, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || !isToken(name) {
+			continue
+		}
+		value, ok := parseCookieValue(value)
+		if !ok {
+			continue
+		}
+		cookies = append(cookies, Cookie{Name: name, Value: value})
+	}
+	return cookies
+}
+
+// ParseSetCookie parses the value of a Set-Cookie header. Unknown attributes are ignored.
+func ParseSetCookie(header string) shepard.Result[Cookie, error] {
+	parts := strings.Split(header, ";")
+	name, value, found := strings.Cut(strings.TrimSpace(parts[0]), "=")
+	if !found || !isToken(name) {
+		return shepard.Err[Cookie, error](fmt.Errorf("[http.ParseSetCookie] invalid name in %q: %w", header, ErrInvalidCookie))
+	}
+	value, ok := parseCookieValue(value)
+	if !ok {
+		return shepard.Err[Cookie, error](fmt.Errorf("[http.ParseSetCookie] invalid value in %q: %w", header, ErrInvalidCookie))
+	}
+
+	cookie := Cookie{Name: name, Value: value}
+	for _, part := range parts[1:] {
+		attr, attrValue, _ := strings.Cut(strings.TrimSpace(part), "=")
+		attrValue = strings.TrimSpace(attrValue)
+		switch strings.ToLower(strings.TrimSpace(attr)) {
+		case "path":
+			cookie.Path = attrValue
+		case "domain":
+			cookie.Domain = strings.TrimPrefix(attrValue, ".")
+		case "expires":
+			if expires := parseCookieTime(attrValue); expires.IsSome() {
+				cookie.Expires = expires.Unwrap()
+			}
+		case "max-age":
+			seconds, err := strconv.Atoi(attrValue)
+			if err != nil {
+				continue
+			}
+			cookie.MaxAge = seconds
+			if seconds <= 0 {
+				cookie.MaxAge = -1
+			}
+		case "secure":
+			cookie.Secure = true
+		case "httponly":
+			cookie.HttpOnly = true
+		case "samesite":
+			switch strings.ToLower(attrValue) {
+			case "lax":
+				cookie.SameSite = SameSiteLax
+			case "strict":
+				cookie.SameSite = SameSiteStrict
+			case "none":
+				cookie.SameSite = SameSiteNone
+			}
+		}
+	}
+	return shepard.Ok[Cookie, error](cookie)
+}
+
+// Cookies returns the cookies sent with the request.
+func (r *Request[T]) Cookies() []Cookie {
+	cookies := []Cookie{}
+	for _, header := range r.Headers.values("Cookie") {
+		cookies = append(cookies, ParseCookies(header)...)
+	}
+	return cookies
+}
+
+// Cookie returns the first cookie named name sent with the request.
+func (r *Request[T]) Cookie(name string) shepard.Option[Cookie] {
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == name {
+			return shepard.Some(cookie)
+		}
+	}
+	return shepard.None[Cookie]()
+}
+
+// SetCookie adds a Set-Cookie header for cookie to res. Each cookie is sent in its own header line.
+func SetCookie(res Response[Body], cookie Cookie) error {
+	if err := cookie.Valid(); err != nil {
+		return fmt.Errorf("[http.SetCookie] %w", err)
+	}
+	res.Headers().Add("Set-Cookie", cookie.String())
+	return nil
+}
+
+// ResponseCookies returns the cookies set by res, e.g. a response received by a Client. Invalid cookies are skipped.
+func ResponseCookies(res Response[Body]) []Cookie {
+	cookies := []Cookie{}
+	for _, header := range res.Headers().values("Set-Cookie") {
+		if cookie := ParseSetCookie(header); cookie.IsOk() {
+			cookies = append(cookies, cookie.Unwrap())
+		}
+	}
+	return cookies
+}
+
+// parseCookieValue strips the optional quotes of a cookie value and returns false if it contains invalid bytes.
+// Quoted values may contain spaces and commas, like the values serialized by Cookie.String.
+func parseCookieValue(value string) (string, bool) {
+	quoted := len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"'
+	if quoted {
+		value = value[1 : len(value)-1]
+	}
+	for i := 0; i < len(value); i++ {
+		if !isCookieValueByte(value[i]) && !(quoted && (value[i] == ' ' || value[i] == ',')) {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// cookieTimeFormats are the formats of Expires dates sent by servers.
+var cookieTimeFormats = []string{TimeFormat, "Mon, 02-Jan-2006 15:04:05 MST", "Monday, 02-Jan-06 15:04:05 MST", "Mon Jan _2 15:04:05 2006"}
+
+func parseCookieTime(value string) shepard.Option[time.Time] {
+	for _, format := range cookieTimeFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return shepard.Some(t.UTC())
+		}
+	}
+	return shepard.None[time.Time]()
+}
+
+// isCookieValueByte returns true for the cookie-octets of RFC 6265.
+func isCookieValueByte(c byte) bool {
+	return c == 0x21 || (c >= 0x23 && c <= 0x2b) || (c >= 0x2d && c <= 0x3a) || (c >= 0x3c && c <= 0x5b) || (c >= 0x5d && c <= 0x7e)
+}
+
+// isToken returns true if s is a non-empty token of RFC 9110.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
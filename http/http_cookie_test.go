@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCookie_String(t *testing.T) {
+	assert := assert.New(t)
+
+	expires := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	cookies := []struct {
+		cookie Cookie
+		want   string
+	}{
+		{cookie: Cookie{Name: "id", Value: "a3fWa"}, want: "id=a3fWa"},
+		{cookie: Cookie{Name: "id", Value: "a3fWa", Path: "/", Domain: ".example.com", Expires: expires, MaxAge: 60, Secure: true, HttpOnly: true, SameSite: SameSiteStrict},
+			want: "id=a3fWa; Path=/; Domain=example.com; Expires=Wed, 21 Oct 2015 07:28:00 GMT; Max-Age=60; Secure; HttpOnly; SameSite=Strict"},
+		{cookie: Cookie{Name: "id", Value: "", MaxAge: -1}, want: "id=; Max-Age=0"},
+		{cookie: Cookie{Name: "greeting", Value: "hello, world", SameSite: SameSiteLax}, want: `greeting="hello, world"; SameSite=Lax`},
+	}
+	for _, tc := range cookies {
+		assert.NoError(tc.cookie.Valid())
+		assert.Equal(tc.want, tc.cookie.String())
+		assert.Equal(tc.cookie.Value, ParseSetCookie(tc.cookie.String()).Unwrap().Value)
+	}
+
+	invalid := []Cookie{
+		{Name: "", Value: "a"},
+		{Name: "a b", Value: "a"},
+		{Name: "id", Value: "a;b"},
+		{Name: "id", Value: `"a"`},
+		{Name: "id", Value: "a", Path: "/;Secure"},
+		{Name: "id", Value: "a", SameSite: SameSiteNone},
+	}
+	for _, cookie := range invalid {
+		assert.ErrorIs(cookie.Valid(), ErrInvalidCookie, cookie)
+	}
+}
+
+func TestParseCookies(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]Cookie{{Name: "id", Value: "a3fWa"}, {Name: "theme", Value: "dark"}, {Name: "empty", Value: ""}, {Name: "quoted", Value: "a b"}},
+		ParseCookies(`id=a3fWa; theme=dark;empty=; invalid; a b=c; bad=a"b; quoted="a b"`))
+	assert.Empty(ParseCookies(""))
+
+	req := newTestRequest(MethodGet, "/")
+	req.Headers.Set("Cookie", "id=a3fWa; theme=dark")
+	req.Headers.Add("cookie", "theme=light")
+	assert.Len(req.Cookies(), 3)
+	assert.Equal("dark", req.Cookie("theme").Unwrap().Value)
+	assert.True(req.Cookie("missing").IsNone())
+}
+
+func TestParseSetCookie(t *testing.T) {
+	assert := assert.New(t)
+
+	cookie := ParseSetCookie("id=a3fWa; Expires=Wed, 21-Oct-2015 07:28:00 GMT; max-age=0; path=/docs; Domain=.example.com; secure; HttpOnly; SameSite=none; Unknown=1").Unwrap()
+	assert.Equal(Cookie{
+		Name:     "id",
+		Value:    "a3fWa",
+		Path:     "/docs",
+		Domain:   "example.com",
+		Expires:  time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC),
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: SameSiteNone,
+	}, cookie)
+
+	assert.ErrorIs(ParseSetCookie("a3fWa").UnwrapErr(), ErrInvalidCookie)
+	assert.ErrorIs(ParseSetCookie(`id=a"b`).UnwrapErr(), ErrInvalidCookie)
+}
+
+func TestSetCookie(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/", func(req *Request[RequestBody]) Response[Body] {
+		res := Text(StatusCodeOk, "ok")
+		assert.NoError(SetCookie(res, Cookie{Name: "id", Value: "a3fWa", Expires: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)}))
+		assert.NoError(SetCookie(res, Cookie{Name: "theme", Value: "dark", HttpOnly: true}))
+		assert.ErrorIs(SetCookie(res, Cookie{Name: "bad name"}), ErrInvalidCookie)
+		return res
+	}))
+
+	// each cookie is sent in its own header line
+	req := newTestRequest(MethodGet, "/")
+	raw, _ := writeTestResponse(req, r.Serve(req).Unwrap(), true)
+	assert.Contains(raw, "\r\nSet-Cookie: id=a3fWa; Expires=Wed, 21 Oct 2015 07:28:00 GMT\r\n")
+	assert.Contains(raw, "\r\nSet-Cookie: theme=dark; HttpOnly\r\n")
+
+	// the client keeps the cookies apart
+	base, _, _ := startClientTestServer(t, r)
+	res := (&Client{}).Get(base + "/").Unwrap()
+	cookies := ResponseCookies(res)
+	assert.Len(cookies, 2)
+	assert.Equal("id", cookies[0].Name)
+	assert.Equal(time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC), cookies[0].Expires)
+	assert.True(cookies[1].HttpOnly)
+
+	// so does net/http
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Len(recorder.Result().Cookies(), 2)
+}
@@ -0,0 +1,524 @@
+package http
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/textproto"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+)
+
+// FileServerOptions configures a file server.
+type FileServerOptions struct {
+	// Index is the file served for a directory, "index.html" by default.
+	Index string
+	// Fallback is the file served for missing paths without an extension, e.g. "index.html" for a single page application
+	// doing its own routing. Missing paths are answered with 404 if it's empty.
+	Fallback string
+	// Logger logs errors of reading files, which are answered with a 500 without details, log.Default() if nil.
+	Logger Logger
+}
+
+func (o FileServerOptions) Default() FileServerOptions {
+	return FileServerOptions{
+		Index: "index.html",
+	}
+}
+
+// FileServer returns a handler serving the files of fsys, e.g. an embed.FS or an os.DirFS, below the URL path prefix.
+//
+// Mount it on a wildcard route, e.g. Get("/static/*path", FileServer(fsys, "/static")).
+// Responses have a Content-Type derived from the extension or sniffed from the content, an ETag and the Last-Modified time
+// if fsys knows it. Conditional requests are answered with 304 and Range requests with 206 or 416.
+// Paths with ".." segments are rejected with 400.
+func FileServer(fsys fs.FS, prefix string) Handler {
+	return FileServerWith(fsys, prefix, FileServerOptions{}.Default())
+}
+
+// FileServerWith is FileServer with options.
+func FileServerWith(fsys fs.FS, prefix string, opts FileServerOptions) Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(req *Request[RequestBody]) Response[Body] {
+		if req.Method != MethodGet && req.Method != MethodHead {
+			res := Error(StatusCodeMethodNotAllowed, nil)
+			res.SetHeader("Allow", MethodGet.String(), MethodHead.String())
+			return res
+		}
+
+		urlPath := req.URL.Path
+		if !strings.HasPrefix(urlPath, prefix+"/") && urlPath != prefix {
+			return Error(StatusCodeNotFound, nil)
+		}
+		rel := strings.TrimPrefix(urlPath, prefix)
+		if !validFilePath(rel) {
+			return Error(StatusCodeBadRequest, errors.New("invalid path"))
+		}
+		name := strings.TrimPrefix(path.Clean("/"+rel), "/")
+		if name == "" {
+			name = "."
+		}
+
+		f, info, err := openFile(fsys, name)
+		if err == nil && info.IsDir() {
+			f.Close()
+			// relative references of the index resolve against the directory only with a trailing slash
+			if !strings.HasSuffix(urlPath, "/") {
+				return Redirect(StatusCodeMovedPermanently, urlPath+"/")
+			}
+			if opts.Index == "" {
+				return Error(StatusCodeNotFound, nil)
+			}
+			f, info, err = openFile(fsys, path.Join(name, opts.Index))
+			if err == nil && info.IsDir() {
+				f.Close()
+				err = fs.ErrNotExist
+			}
+		}
+		if errors.Is(err, fs.ErrNotExist) && opts.Fallback != "" && path.Ext(name) == "" {
+			f, info, err = openFile(fsys, opts.Fallback)
+		}
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return Error(StatusCodeNotFound, nil)
+		case errors.Is(err, fs.ErrPermission):
+			return Error(StatusCodeForbidden, nil)
+		case err != nil:
+			logf(opts.Logger, "[http.FileServer] open %q failed: %v", name, err)
+			return Error(StatusCodeInternalServerError, nil)
+		}
+		res, err := serveFile(req, f, info)
+		if err != nil {
+			logf(opts.Logger, "[http.FileServer] read %q failed: %v", name, err)
+			return Error(StatusCodeInternalServerError, nil)
+		}
+		return res
+	}
+}
+
+// validFilePath returns false for URL paths which could escape the served directory.
+func validFilePath(p string) bool {
+	if strings.ContainsAny(p, "\\\x00") {
+		return false
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func openFile(fsys fs.FS, name string) (fs.File, fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// serveFile returns the response for the file f, honoring the conditional and range headers of req.
+//
+// f is closed before serveFile returns, unless the response streams its content, then it's closed with the body.
+func serveFile(req *Request[RequestBody], f fs.File, info fs.FileInfo) (Response[Body], error) {
+	streamed := false
+	defer func() {
+		if !streamed {
+			f.Close()
+		}
+	}()
+
+	// the file is read at most once, ranges of seekable files are read separately
+	var content []byte
+	readAll := func() ([]byte, error) {
+		if content == nil {
+			data, err := io.ReadAll(f)
+			if err != nil {
+				return nil, err
+			}
+			content = data
+		}
+		return content, nil
+	}
+	if _, seekable := f.(io.Seeker); !seekable {
+		if _, err := readAll(); err != nil {
+			return nil, err
+		}
+	}
+
+	size := info.Size()
+	modTime := info.ModTime()
+	var etag string
+	if modTime.IsZero() || modTime.Unix() <= 0 {
+		// embedded files have no modification time, their ETag is derived from the content
+		data, err := readAll()
+		if err != nil {
+			return nil, err
+		}
+		h := fnv.New64a()
+		h.Write(data)
+		etag = fmt.Sprintf(`"%x"`, h.Sum64())
+		modTime = time.Time{}
+	} else {
+		etag = fmt.Sprintf(`"%x-%x"`, modTime.UnixNano(), size)
+	}
+	if content != nil {
+		size = int64(len(content))
+	}
+
+	headers := func(res Response[Body]) Response[Body] {
+		res.SetHeader("ETag", etag)
+		if !modTime.IsZero() {
+			res.SetHeader("Last-Modified", modTime.UTC().Format(TimeFormat))
+		}
+		res.SetHeader("Accept-Ranges", "bytes")
+		return res
+	}
+
+	if notModified(req, etag, modTime) {
+		return headers(bytesResponse(StatusCodeNotModified, "", nil)), nil
+	}
+
+	sniff := content
+	if sniff == nil {
+		head, err := readRange(f, 0, 512)
+		if err != nil {
+			return nil, err
+		}
+		sniff = head
+	}
+	contentType := fileContentType(info.Name(), sniff)
+
+	// serve returns the response with the bytes of r, streamed from the file unless its content is loaded already.
+	// HEAD responses only describe the content.
+	serve := func(status StatusCode, r byteRange) (Response[Body], error) {
+		var res Response[Body]
+		switch {
+		case req.Method == MethodHead:
+			res = bytesResponse(status, contentType, nil)
+			res.SetHeader("Content-Length", strconv.FormatInt(r.length, 10))
+		case content != nil:
+			res = bytesResponse(status, contentType, content[r.start:r.start+r.length])
+		default:
+			body, err := newFileBody(f, r.start, r.length)
+			if err != nil {
+				return nil, err
+			}
+			streamed = true
+			res = NewResponseBuilder(NewHttpResponseBytes()).
+				Status(status).
+				Header("Content-Type", contentType).
+				Header("Content-Length", strconv.FormatInt(r.length, 10)).
+				Body(body).
+				Unwrap()
+		}
+		if status == StatusCodePartialContent {
+			res.SetHeader("Content-Range", r.contentRange(size))
+		}
+		return headers(res), nil
+	}
+	whole := byteRange{start: 0, length: size}
+
+	rangeHeader := req.Headers.Value("Range")
+	if rangeHeader == "" || !rangeApplies(req, etag, modTime) {
+		return serve(StatusCodeOk, whole)
+	}
+
+	ranges, ok := parseRange(rangeHeader, size)
+	if !ok {
+		res := Error(StatusCodeRequestedRangeNotSatisfiable, nil)
+		res.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", size))
+		return headers(res), nil
+	}
+	if ranges == nil {
+		return serve(StatusCodeOk, whole)
+	}
+	if len(ranges) == 1 {
+		return serve(StatusCodePartialContent, ranges[0])
+	}
+
+	readPart := func(r byteRange) ([]byte, error) {
+		if content != nil {
+			return content[r.start : r.start+r.length], nil
+		}
+		return readRange(f, r.start, r.length)
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, r := range ranges {
+		data, err := readPart(r)
+		if err != nil {
+			return nil, err
+		}
+		part, _ := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":  {contentType},
+			"Content-Range": {r.contentRange(size)},
+		})
+		part.Write(data)
+	}
+	w.Close()
+	return headers(bytesResponse(StatusCodePartialContent, "multipart/byteranges; boundary="+w.Boundary(), buf.Bytes())), nil
+}
+
+// readRange reads length bytes of the seekable file f from offset start, less if the file ends before.
+func readRange(f fs.File, start, length int64) ([]byte, error) {
+	seeker := f.(io.Seeker)
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(f, length))
+	if err != nil {
+		return nil, err
+	}
+	_, err = seeker.Seek(0, io.SeekStart)
+	return data, err
+}
+
+// notModified returns true if the conditional headers of req match the current ETag or modification time of the file.
+//
+// If-Modified-Since is ignored if If-None-Match is present.
+func notModified(req *Request[RequestBody], etag string, modTime time.Time) bool {
+	if tags := req.Headers.List("If-None-Match"); len(tags) > 0 {
+		for _, tag := range tags {
+			tag = strings.TrimPrefix(tag, "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	return !modTime.IsZero() && !modifiedSince(req.Headers.Value("If-Modified-Since"), modTime)
+}
+
+// rangeApplies returns false if the If-Range header of req names another version of the file.
+func rangeApplies(req *Request[RequestBody], etag string, modTime time.Time) bool {
+	ifRange := req.Headers.Value("If-Range")
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return ifRange == etag
+	}
+	return !modTime.IsZero() && !modifiedSince(ifRange, modTime)
+}
+
+// modifiedSince returns true if date is missing or invalid, or modTime is after date in the resolution of HTTP dates.
+func modifiedSince(date string, modTime time.Time) bool {
+	t, err := time.Parse(TimeFormat, date)
+	if err != nil {
+		return true
+	}
+	return modTime.Truncate(time.Second).After(t)
+}
+
+// byteRange is a range of a file.
+type byteRange struct {
+	start, length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// maxRanges is the maximum number of ranges served in a multipart response. Requests with more ranges get the whole file.
+const maxRanges = 32
+
+// parseRange returns the ranges of the Range header value in a file of size bytes, sorted with overlapping and adjacent
+// ranges merged.
+//
+// It returns nil and true if the header is invalid and must be ignored, and false if none of the ranges is satisfiable.
+// Like net/http, the header is also ignored if its ranges add up to more than the file or if there are more than
+// maxRanges of them after merging, so repeated ranges can't make the response larger than the file.
+func parseRange(value string, size int64) ([]byteRange, bool) {
+	if !strings.HasPrefix(value, "bytes=") {
+		return nil, true
+	}
+	spec := strings.TrimPrefix(value, "bytes=")
+
+	ranges := []byteRange{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, found := strings.Cut(part, "-")
+		if !found {
+			return nil, true
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r byteRange
+		if first == "" {
+			// the suffix of the file
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, true
+			}
+			if n == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = byteRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, true
+			}
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, true
+				}
+				if end >= size {
+					end = size - 1
+				}
+			}
+			if start >= size {
+				continue
+			}
+			r = byteRange{start: start, length: end - start + 1}
+		}
+		if r.length > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, false
+	}
+
+	var total int64
+	for _, r := range ranges {
+		if total += r.length; total > size {
+			return nil, true
+		}
+	}
+	ranges = mergeRanges(ranges)
+	if len(ranges) > maxRanges {
+		return nil, true
+	}
+	return ranges, true
+}
+
+// mergeRanges sorts ranges by their start and merges the ones which overlap or are adjacent.
+func mergeRanges(ranges []byteRange) []byteRange {
+	slices.SortFunc(ranges, func(a, b byteRange) int {
+		return cmp.Compare(a.start, b.start)
+	})
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.start > last.start+last.length {
+			merged = append(merged, r)
+			continue
+		}
+		last.length = max(last.length, r.start+r.length-last.start)
+	}
+	return merged
+}
+
+// fileBody is a StreamingBody with a range of a seekable file, which is read in chunks while the response is sent
+// instead of being loaded into memory. The file is closed when the range was read or the body is closed.
+type fileBody struct {
+	f         fs.File
+	remaining int64
+	// ready is always closed, so the response writer reads the next chunk until the body is done
+	ready chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+var _ StreamingBody = (*fileBody)(nil)
+
+// fileChunkSize is the size of the chunks a fileBody is read in.
+const fileChunkSize = 32 << 10
+
+// newFileBody returns a body with length bytes of the seekable file f from offset start.
+func newFileBody(f fs.File, start, length int64) (*fileBody, error) {
+	if _, err := f.(io.Seeker).Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	ready := make(chan struct{})
+	close(ready)
+	return &fileBody{f: f, remaining: length, ready: ready, done: make(chan struct{})}, nil
+}
+
+// Read appends the next chunk of the file to bs.
+func (b *fileBody) Read(bs *slice.Slice[byte]) shepard.Result[int, error] {
+	if b.Closed() {
+		return shepard.Ok[int, error](0)
+	}
+	buf := make([]byte, min(b.remaining, fileChunkSize))
+	n, err := io.ReadFull(b.f, buf)
+	b.remaining -= int64(n)
+	if n > 0 {
+		chunk := slice.Init(buf[:n]...)
+		bs.Append(&chunk)
+	}
+	if err != nil || b.remaining == 0 {
+		b.Close()
+	}
+	if err != nil {
+		return shepard.Err[int, error](fmt.Errorf("[http.FileServer] read file failed: %w", err))
+	}
+	return shepard.Ok[int, error](n)
+}
+
+func (b *fileBody) Write(slice.Slice[byte]) shepard.Result[int, error] {
+	return shepard.Err[int, error](errors.New("[http.FileServer] can't write to a file body"))
+}
+
+func (b *fileBody) Close() shepard.Result[shepard.Nil, error] {
+	var err error
+	b.once.Do(func() {
+		err = b.f.Close()
+		close(b.done)
+	})
+	if err != nil {
+		return shepard.Err[shepard.Nil, error](err)
+	}
+	return shepard.Ok[shepard.Nil, error](shepard.Nil{})
+}
+
+func (b *fileBody) Closed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Flush does nothing, the data is read when the response writer asks for it.
+func (b *fileBody) Flush() {}
+
+func (b *fileBody) Flushed() <-chan struct{} {
+	if b.Closed() {
+		// a nil channel blocks, the response writer waits for Done
+		return nil
+	}
+	return b.ready
+}
+
+func (b *fileBody) Done() <-chan struct{} {
+	return b.done
+}
@@ -0,0 +1,311 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+var testModTime = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newFileServerRouter(opts FileServerOptions) *Router {
+	fsys := fstest.MapFS{
+		"index.html":          {Data: []byte("<h1>home</h1>"), ModTime: testModTime},
+		"app.js":              {Data: []byte("console.log(1)"), ModTime: testModTime},
+		"data":                {Data: []byte("0123456789"), ModTime: testModTime},
+		"docs/index.html":     {Data: []byte("<h1>docs</h1>"), ModTime: testModTime},
+		"empty/.keep":         {Data: []byte{}, ModTime: testModTime},
+		"embedded/logo.svg":   {Data: []byte("<svg></svg>")},
+		"embedded/index.html": {Data: []byte("<h1>embedded</h1>")},
+	}
+	return NewRouter().Route(Get("/static/*path", FileServerWith(fsys, "/static", opts)))
+}
+
+func serveFileRequest(r *Router, method Method, uri string, headers map[string]string) Response[Body] {
+	req := newTestRequest(method, uri)
+	for key, value := range headers {
+		req.Headers.Set(key, value)
+	}
+	return r.Serve(req).Unwrap()
+}
+
+func TestFileServer(t *testing.T) {
+	assert := assert.New(t)
+	r := newFileServerRouter(FileServerOptions{}.Default())
+
+	res := serveFileRequest(r, MethodGet, "/static/app.js", nil)
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.Equal(slice.Init(mime.TypeByExtension(".js")), res.Headers().Get("Content-Type"))
+	assert.Equal(slice.Init("Mon, 01 May 2023 12:00:00 GMT"), res.Headers().Get("Last-Modified"))
+	assert.Equal(slice.Init("bytes"), res.Headers().Get("Accept-Ranges"))
+	assert.True(res.Headers().Has("ETag"))
+	assert.Equal("console.log(1)", readBody(res))
+
+	// sniffed content type
+	res = serveFileRequest(r, MethodGet, "/static/data", nil)
+	assert.Equal(slice.Init("text/plain; charset=utf-8"), res.Headers().Get("Content-Type"))
+
+	// directory index
+	res = serveFileRequest(r, MethodGet, "/static/", nil)
+	assert.Equal("<h1>home</h1>", readBody(res))
+	res = serveFileRequest(r, MethodGet, "/static/docs/", nil)
+	assert.Equal("<h1>docs</h1>", readBody(res))
+	res = serveFileRequest(r, MethodGet, "/static/docs", nil)
+	assert.Equal(StatusCodeMovedPermanently, res.StatusCode())
+	assert.Equal(slice.Init("/static/docs/"), res.Headers().Get("Location"))
+	assert.Equal(StatusCodeNotFound, serveFileRequest(r, MethodGet, "/static/empty/", nil).StatusCode())
+
+	res = serveFileRequest(r, MethodHead, "/static/app.js", nil)
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.Equal(slice.Init("14"), res.Headers().Get("Content-Length"))
+	assert.Equal("", readBody(res))
+
+	assert.Equal(StatusCodeNotFound, serveFileRequest(r, MethodGet, "/static/missing.js", nil).StatusCode())
+	assert.Equal(StatusCodeNotFound, serveFileRequest(r, MethodGet, "/static/missing", nil).StatusCode())
+}
+
+func TestFileServer_Traversal(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.Mkdir(filepath.Join(dir, "public"), 0o755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "public", "index.html"), []byte("public"), 0o644))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644))
+
+	handler := FileServer(os.DirFS(filepath.Join(dir, "public")), "/")
+	for _, path := range []string{"/../secret.txt", "/a/../../secret.txt", "/..", "/..\\secret.txt"} {
+		req := newTestRequest(MethodGet, "/")
+		req.URL.Path = path
+		res := handler(req)
+		assert.Equal(StatusCodeBadRequest, res.StatusCode(), path)
+		assert.NotContains(readBody(res), "secret", path)
+	}
+
+	req := newTestRequest(MethodGet, "/")
+	assert.Equal("public", readBody(handler(req)))
+	req = newTestRequest(MethodPost, "/")
+	assert.Equal(StatusCodeMethodNotAllowed, handler(req).StatusCode())
+}
+
+func TestFileServer_Conditional(t *testing.T) {
+	assert := assert.New(t)
+	r := newFileServerRouter(FileServerOptions{}.Default())
+
+	etag := *serveFileRequest(r, MethodGet, "/static/app.js", nil).Headers().Get("ETag").First().Unwrap()
+
+	testCases := []struct {
+		headers map[string]string
+		status  StatusCode
+	}{
+		{headers: map[string]string{"If-None-Match": etag}, status: StatusCodeNotModified},
+		{headers: map[string]string{"If-None-Match": `"other", ` + etag}, status: StatusCodeNotModified},
+		{headers: map[string]string{"If-None-Match": "W/" + etag}, status: StatusCodeNotModified},
+		{headers: map[string]string{"If-None-Match": "*"}, status: StatusCodeNotModified},
+		{headers: map[string]string{"If-None-Match": `"other"`}, status: StatusCodeOk},
+		{headers: map[string]string{"If-Modified-Since": "Mon, 01 May 2023 12:00:00 GMT"}, status: StatusCodeNotModified},
+		{headers: map[string]string{"If-Modified-Since": "Tue, 02 May 2023 12:00:00 GMT"}, status: StatusCodeNotModified},
+		{headers: map[string]string{"If-Modified-Since": "Sun, 30 Apr 2023 12:00:00 GMT"}, status: StatusCodeOk},
+		{headers: map[string]string{"If-Modified-Since": "yesterday"}, status: StatusCodeOk},
+		// If-None-Match takes precedence
+		{headers: map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Tue, 02 May 2023 12:00:00 GMT"}, status: StatusCodeOk},
+	}
+	for _, tc := range testCases {
+		res := serveFileRequest(r, MethodGet, "/static/app.js", tc.headers)
+		assert.Equal(tc.status, res.StatusCode(), tc.headers)
+		if tc.status == StatusCodeNotModified {
+			assert.Equal(slice.Init(etag), res.Headers().Get("ETag"))
+			assert.Equal("", readBody(res))
+		}
+	}
+
+	// files without modification time, e.g. of an embed.FS, have a content based ETag
+	res := serveFileRequest(r, MethodGet, "/static/embedded/logo.svg", nil)
+	assert.False(res.Headers().Has("Last-Modified"))
+	assert.Equal(slice.Init("image/svg+xml"), res.Headers().Get("Content-Type"))
+	etag = *res.Headers().Get("ETag").First().Unwrap()
+	res = serveFileRequest(r, MethodGet, "/static/embedded/logo.svg", map[string]string{"If-None-Match": etag})
+	assert.Equal(StatusCodeNotModified, res.StatusCode())
+	res = serveFileRequest(r, MethodGet, "/static/embedded/logo.svg", map[string]string{"If-Modified-Since": "Tue, 02 May 2023 12:00:00 GMT"})
+	assert.Equal(StatusCodeOk, res.StatusCode())
+}
+
+func TestFileServer_Range(t *testing.T) {
+	assert := assert.New(t)
+	r := newFileServerRouter(FileServerOptions{}.Default())
+
+	testCases := []struct {
+		rangeHeader  string
+		status       StatusCode
+		contentRange string
+		body         string
+	}{
+		{rangeHeader: "bytes=0-3", status: StatusCodePartialContent, contentRange: "bytes 0-3/10", body: "0123"},
+		{rangeHeader: "bytes=7-", status: StatusCodePartialContent, contentRange: "bytes 7-9/10", body: "789"},
+		{rangeHeader: "bytes=-2", status: StatusCodePartialContent, contentRange: "bytes 8-9/10", body: "89"},
+		{rangeHeader: "bytes=5-100", status: StatusCodePartialContent, contentRange: "bytes 5-9/10", body: "56789"},
+		{rangeHeader: "bytes=-100", status: StatusCodePartialContent, contentRange: "bytes 0-9/10", body: "0123456789"},
+		{rangeHeader: "bytes=10-", status: StatusCodeRequestedRangeNotSatisfiable, contentRange: "bytes */10"},
+		{rangeHeader: "bytes=-0", status: StatusCodeRequestedRangeNotSatisfiable, contentRange: "bytes */10"},
+		// overlapping and adjacent ranges are merged
+		{rangeHeader: "bytes=2-5,0-3", status: StatusCodePartialContent, contentRange: "bytes 0-5/10", body: "012345"},
+		{rangeHeader: "bytes=4-5,6-7", status: StatusCodePartialContent, contentRange: "bytes 4-7/10", body: "4567"},
+		// ranges adding up to more than the file get the whole file
+		{rangeHeader: "bytes=0-,0-", status: StatusCodeOk, body: "0123456789"},
+		// invalid ranges are ignored
+		{rangeHeader: "bytes=3-1", status: StatusCodeOk, body: "0123456789"},
+		{rangeHeader: "items=0-1", status: StatusCodeOk, body: "0123456789"},
+		{rangeHeader: "bytes=a-b", status: StatusCodeOk, body: "0123456789"},
+	}
+	for _, tc := range testCases {
+		res := serveFileRequest(r, MethodGet, "/static/data", map[string]string{"Range": tc.rangeHeader})
+		assert.Equal(tc.status, res.StatusCode(), tc.rangeHeader)
+		if tc.contentRange != "" {
+			assert.Equal(slice.Init(tc.contentRange), res.Headers().Get("Content-Range"), tc.rangeHeader)
+		} else {
+			assert.False(res.Headers().Has("Content-Range"), tc.rangeHeader)
+		}
+		if tc.body != "" {
+			assert.Equal(tc.body, readBody(res), tc.rangeHeader)
+		}
+	}
+
+	// embedded files support ranges as well
+	res := serveFileRequest(r, MethodGet, "/static/embedded/logo.svg", map[string]string{"Range": "bytes=1-3"})
+	assert.Equal("svg", readBody(res))
+
+	// several ranges
+	res = serveFileRequest(r, MethodGet, "/static/data", map[string]string{"Range": "bytes=0-1, 8-"})
+	assert.Equal(StatusCodePartialContent, res.StatusCode())
+	mediaType, params, err := mime.ParseMediaType(*res.Headers().Get("Content-Type").First().Unwrap())
+	assert.NoError(err)
+	assert.Equal("multipart/byteranges", mediaType)
+	mr := multipart.NewReader(strings.NewReader(readBody(res)), params["boundary"])
+	for _, expected := range []struct{ contentRange, body string }{{"bytes 0-1/10", "01"}, {"bytes 8-9/10", "89"}} {
+		part, err := mr.NextPart()
+		assert.NoError(err)
+		assert.Equal(expected.contentRange, part.Header.Get("Content-Range"))
+		body, _ := io.ReadAll(part)
+		assert.Equal(expected.body, string(body))
+	}
+
+	// If-Range
+	etag := *res.Headers().Get("ETag").First().Unwrap()
+	res = serveFileRequest(r, MethodGet, "/static/data", map[string]string{"Range": "bytes=0-1", "If-Range": etag})
+	assert.Equal(StatusCodePartialContent, res.StatusCode())
+	res = serveFileRequest(r, MethodGet, "/static/data", map[string]string{"Range": "bytes=0-1", "If-Range": `"old"`})
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	res = serveFileRequest(r, MethodGet, "/static/data", map[string]string{"Range": "bytes=0-1", "If-Range": "Sun, 30 Apr 2023 12:00:00 GMT"})
+	assert.Equal(StatusCodeOk, res.StatusCode())
+}
+
+func TestParseRange(t *testing.T) {
+	assert := assert.New(t)
+
+	ranges, ok := parseRange("bytes=8-9, 0-1, 1-3", 10)
+	assert.True(ok)
+	assert.Equal([]byteRange{{start: 0, length: 4}, {start: 8, length: 2}}, ranges)
+
+	// repeated ranges can't multiply the size of the response
+	size := int64(100 << 10)
+	ranges, ok = parseRange("bytes="+strings.Repeat("0-,", 200), size)
+	assert.True(ok)
+	assert.Nil(ranges)
+
+	// too many ranges after merging
+	parts := []string{}
+	for i := 0; i <= maxRanges; i++ {
+		parts = append(parts, fmt.Sprintf("%d-%d", i*10, i*10))
+	}
+	ranges, ok = parseRange("bytes="+strings.Join(parts, ","), size)
+	assert.True(ok)
+	assert.Nil(ranges)
+	ranges, ok = parseRange("bytes="+strings.Join(parts[1:], ","), size)
+	assert.True(ok)
+	assert.Len(ranges, maxRanges)
+}
+
+func TestFileServer_Stream(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	data := []byte(strings.Repeat("0123456789abcdef", fileChunkSize/4))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "data.bin"), data, 0o644))
+	r := NewRouter().Route(Get("/static/*path", FileServer(os.DirFS(dir), "/static")))
+
+	// files of an os.DirFS are read while the response is written
+	res := serveFileRequest(r, MethodGet, "/static/data.bin", nil)
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.IsType(&fileBody{}, res.Body())
+	assert.Equal(slice.Init(strconv.Itoa(len(data))), res.Headers().Get("Content-Length"))
+	out, _ := writeTestResponse(newTestRequest(MethodGet, "/static/data.bin"), res, true)
+	_, body, _ := strings.Cut(out, "\r\n\r\n")
+	assert.Equal(string(data), body)
+	assert.True(res.Body().Closed())
+
+	res = serveFileRequest(r, MethodGet, "/static/data.bin", map[string]string{"Range": "bytes=100-"})
+	assert.Equal(StatusCodePartialContent, res.StatusCode())
+	assert.IsType(&fileBody{}, res.Body())
+	out, _ = writeTestResponse(newTestRequest(MethodGet, "/static/data.bin"), res, true)
+	_, body, _ = strings.Cut(out, "\r\n\r\n")
+	assert.Equal(string(data[100:]), body)
+
+	// HEAD requests don't open a stream
+	res = serveFileRequest(r, MethodHead, "/static/data.bin", nil)
+	assert.Equal(slice.Init(strconv.Itoa(len(data))), res.Headers().Get("Content-Length"))
+	assert.True(res.Body().Closed())
+
+	// a closed body closes the file
+	res = serveFileRequest(r, MethodGet, "/static/data.bin", nil)
+	assert.True(res.Body().Close().IsOk())
+	assert.True(res.Body().Closed())
+	assert.Equal("", readBody(res))
+}
+
+func TestFileServer_Fallback(t *testing.T) {
+	assert := assert.New(t)
+	r := newFileServerRouter(FileServerOptions{Index: "index.html", Fallback: "embedded/index.html"})
+
+	res := serveFileRequest(r, MethodGet, "/static/users/42", nil)
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.Equal("<h1>embedded</h1>", readBody(res))
+
+	// missing assets aren't answered with the application
+	assert.Equal(StatusCodeNotFound, serveFileRequest(r, MethodGet, "/static/missing.js", nil).StatusCode())
+	assert.Equal("console.log(1)", readBody(serveFileRequest(r, MethodGet, "/static/app.js", nil)))
+}
+
+// failingFS fails to open any file with err.
+type failingFS struct {
+	err error
+}
+
+func (f failingFS) Open(name string) (fs.File, error) {
+	return nil, f.err
+}
+
+func TestFileServer_Error(t *testing.T) {
+	assert := assert.New(t)
+	logger := &testLogger{}
+	opts := FileServerOptions{}.Default()
+	opts.Logger = logger
+	handler := FileServerWith(failingFS{err: errors.New("disk failure in /srv/secret")}, "/", opts)
+
+	// internal errors are logged, not sent to the client
+	res := handler(newTestRequest(MethodGet, "/app.js"))
+	assert.Equal(StatusCodeInternalServerError, res.StatusCode())
+	assert.NotContains(readBody(res), "secret")
+	assert.Len(logger.Messages(), 1)
+	assert.Contains(logger.Messages()[0], "disk failure in /srv/secret")
+}
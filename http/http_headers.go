@@ -0,0 +1,212 @@
+package http
+
+import (
+	"mime"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/iter"
+	"github.com/marlaone/shepard/num"
+)
+
+var headerValueSeparator = []byte{','}
+
+// Headers are the header fields of a request or response.
+//
+// Keys are canonicalized, e.g. "content-type" becomes "Content-Type", so they are matched regardless of case.
+// Values are stored as received, one per header line: comma separated lists are only split by List.
+type Headers struct {
+	headers hashmap.HashMap[string, slice.Slice[string]]
+}
+
+func (h Headers) Default() Headers {
+	return Headers{
+		headers: hashmap.WithCapacity[string, slice.Slice[string]](16),
+	}
+}
+
+// CanonicalHeaderKey returns the canonical form of a header key, the first letter and letters following a hyphen in
+// upper case, the rest in lower case, e.g. "X-Request-Id". Keys containing spaces or invalid characters are returned unchanged.
+func CanonicalHeaderKey(key string) string {
+	return textproto.CanonicalMIMEHeaderKey(key)
+}
+
+func (h *Headers) Has(key string) bool {
+	return h.headers.ContainsKey(CanonicalHeaderKey(key))
+}
+
+// Set replaces the values of key.
+func (h *Headers) Set(key string, values ...string) {
+	h.headers.Insert(CanonicalHeaderKey(key), slice.Init(values...))
+}
+
+// Add appends values to the values of key, e.g. to send several Set-Cookie headers.
+func (h *Headers) Add(key string, values ...string) {
+	h.headers.Entry(CanonicalHeaderKey(key)).AndModify(func(s *slice.Slice[string]) {
+		for _, v := range values {
+			s.Push(v)
+		}
+	}).OrInsert(slice.Init(values...))
+}
+
+// Del removes key.
+func (h *Headers) Del(key string) {
+	h.headers.Remove(CanonicalHeaderKey(key))
+}
+
+// Get returns the raw values of key, one per header line.
+func (h *Headers) Get(key string) slice.Slice[string] {
+	values := h.headers.Get(CanonicalHeaderKey(key))
+	if values.IsSome() {
+		return *values.Unwrap()
+	}
+	return slice.New[string]()
+}
+
+// Value returns the values of key joined by commas, the combined value of list-valued headers, or an empty string if
+// key is missing.
+func (h *Headers) Value(key string) string {
+	return strings.Join(h.values(key), ", ")
+}
+
+// List returns the elements of the comma separated lists in the values of key, e.g. ["gzip", "br;q=0.5"] for
+// "Accept-Encoding: gzip, br;q=0.5". Commas in quoted strings don't separate elements, empty elements are skipped.
+//
+// It must only be used for list-valued headers, values like dates or cookies contain commas.
+func (h *Headers) List(key string) []string {
+	elements := []string{}
+	for _, value := range h.values(key) {
+		for _, element := range splitList(value) {
+			if element != "" {
+				elements = append(elements, element)
+			}
+		}
+	}
+	return elements
+}
+
+func (h *Headers) values(key string) []string {
+	values := []string{}
+	h.Get(key).Iter().Foreach(func(_ int, v string) {
+		values = append(values, v)
+	})
+	return values
+}
+
+func (h *Headers) Iter() iter.Iter[hashmap.Pair[*string, *slice.Slice[string]]] {
+	return h.headers.Iter()
+}
+
+// ContentLength returns the value of the Content-Length header, shepard.None if it's missing or invalid.
+func (h *Headers) ContentLength() shepard.Option[uint64] {
+	values := h.List("Content-Length")
+	if len(values) == 0 {
+		return shepard.None[uint64]()
+	}
+	n := num.ParseString[uint64](values[0])
+	if n.IsErr() {
+		return shepard.None[uint64]()
+	}
+	// repeated values must be equal
+	for _, value := range values[1:] {
+		if value != values[0] {
+			return shepard.None[uint64]()
+		}
+	}
+	return n.Ok()
+}
+
+// MediaType is a media type with its parameters, e.g. the value of a Content-Type header.
+type MediaType struct {
+	// Type is the type and subtype in lower case, e.g. "text/html".
+	Type string
+	// Params are the parameters by their lower case names, e.g. {"charset": "utf-8"}.
+	Params map[string]string
+}
+
+// ContentType returns the media type of the Content-Type header, shepard.None if it's missing.
+// The parameters of an invalid value are dropped.
+func (h *Headers) ContentType() shepard.Option[MediaType] {
+	value := h.Value("Content-Type")
+	if strings.TrimSpace(value) == "" {
+		return shepard.None[MediaType]()
+	}
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return shepard.Some(MediaType{Type: strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0])), Params: map[string]string{}})
+	}
+	return shepard.Some(MediaType{Type: mediaType, Params: params})
+}
+
+// MediaRange is a media range of an Accept header, e.g. "text/*;q=0.5".
+type MediaRange struct {
+	MediaType
+	// Q is the quality of the range between 0 and 1.
+	Q float64
+}
+
+// Accept returns the media ranges of the Accept header ordered by descending quality, ranges of equal quality keep
+// their order. Ranges with an invalid quality are skipped, a missing header accepts everything.
+func (h *Headers) Accept() []MediaRange {
+	if strings.TrimSpace(h.Value("Accept")) == "" {
+		return []MediaRange{{MediaType: MediaType{Type: "*/*", Params: map[string]string{}}, Q: 1}}
+	}
+
+	ranges := []MediaRange{}
+	for _, element := range h.List("Accept") {
+		params := strings.Split(element, ";")
+		r := MediaRange{MediaType: MediaType{Type: strings.ToLower(strings.TrimSpace(params[0])), Params: map[string]string{}}, Q: 1}
+		if r.Type == "" {
+			continue
+		}
+		valid := true
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			key, value = strings.ToLower(strings.TrimSpace(key)), strings.Trim(strings.TrimSpace(value), `"`)
+			if key != "q" {
+				r.Params[key] = value
+				continue
+			}
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+			}
+			r.Q = q
+		}
+		if valid {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Q > ranges[j].Q
+	})
+	return ranges
+}
+
+// splitList splits a comma separated header value into its trimmed elements. Commas in quoted strings don't separate elements.
+func splitList(value string) []string {
+	elements := []string{}
+	start := 0
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case headerValueSeparator[0]:
+			if !quoted {
+				elements = append(elements, strings.TrimSpace(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(elements, strings.TrimSpace(value[start:]))
+}
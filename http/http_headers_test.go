@@ -0,0 +1,118 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	h := Headers{}.Default()
+	h.Set("content-type", "text/plain")
+	assert.True(h.Has("Content-Type"))
+	assert.True(h.Has("CONTENT-TYPE"))
+	assert.Equal(slice.Init("text/plain"), h.Get("Content-type"))
+
+	h.Set("CONTENT-TYPE", "text/html")
+	assert.Equal(slice.Init("text/html"), h.Get("content-type"))
+
+	h.Add("set-cookie", "a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT")
+	h.Add("Set-Cookie", "b=2")
+	assert.Equal(slice.Init("a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT", "b=2"), h.Get("Set-Cookie"))
+
+	h.Del("content-type")
+	assert.False(h.Has("Content-Type"))
+	assert.Equal("", h.Value("Content-Type"))
+
+	keys := []string{}
+	h.Iter().Foreach(func(_ int, pair hashmap.Pair[*string, *slice.Slice[string]]) {
+		keys = append(keys, *pair.Key)
+	})
+	assert.Equal([]string{"Set-Cookie"}, keys)
+
+	// responses are written with canonical keys, cookies on separate lines
+	res := Text(StatusCodeOk, "ok")
+	res.SetHeader("x-custom", "a, b")
+	res.Headers().Add("X-CUSTOM", "c")
+	res.Headers().Add("set-cookie", "a=1", "b=2")
+	raw, _ := writeTestResponse(newTestRequest(MethodGet, "/"), res, true)
+	assert.Contains(raw, "\r\nX-Custom: a, b, c\r\n")
+	assert.Contains(raw, "\r\nSet-Cookie: a=1\r\nSet-Cookie: b=2\r\n")
+
+	assert.Equal("X-Request-Id", CanonicalHeaderKey("x-request-ID"))
+	assert.Equal("Www-Authenticate", CanonicalHeaderKey("WWW-Authenticate"))
+	assert.Equal("invalid key", CanonicalHeaderKey("invalid key"))
+}
+
+func TestHeaders_List(t *testing.T) {
+	assert := assert.New(t)
+
+	h := Headers{}.Default()
+	h.Add("Accept-Encoding", "gzip , br;q=0.5,")
+	h.Add("Accept-Encoding", "identity")
+	assert.Equal("gzip , br;q=0.5,, identity", h.Value("Accept-Encoding"))
+	assert.Equal([]string{"gzip", "br;q=0.5", "identity"}, h.List("accept-encoding"))
+	assert.Empty(h.List("Connection"))
+
+	assert.Equal([]string{`"a,b"`, `W/"c"`}, splitList(`"a,b", W/"c"`))
+	assert.Equal([]string{`a; q="\","`, "b"}, splitList(`a; q="\",", b`))
+	assert.Equal([]string{""}, splitList(""))
+
+	// values are stored as received
+	raw := "GET / HTTP/1.1\r\nHost: localhost\r\nCookie: a=1, b=2\r\nIf-Modified-Since: Wed, 21 Oct 2015 07:28:00 GMT\r\n" +
+		"User-Agent: Mozilla/5.0 (X11; Linux x86_64), Firefox/118.0\r\naccept: a, b\r\nAccept: c\r\n\r\n"
+	req := NewRequestParser(strings.NewReader(raw), ParserOptions{}).Next().Unwrap()
+	assert.Equal(slice.Init("a=1, b=2"), req.Headers.Get("Cookie"))
+	assert.Equal(slice.Init("Wed, 21 Oct 2015 07:28:00 GMT"), req.Headers.Get("If-Modified-Since"))
+	assert.Equal(slice.Init("Mozilla/5.0 (X11; Linux x86_64), Firefox/118.0"), req.Headers.Get("User-Agent"))
+	assert.Equal(slice.Init("a, b", "c"), req.Headers.Get("Accept"))
+	assert.Equal([]string{"a", "b", "c"}, req.Headers.List("Accept"))
+}
+
+func TestHeaders_ContentLength(t *testing.T) {
+	assert := assert.New(t)
+
+	h := Headers{}.Default()
+	assert.True(h.ContentLength().IsNone())
+	h.Set("Content-Length", "42")
+	assert.Equal(uint64(42), h.ContentLength().Unwrap())
+	h.Set("Content-Length", "42, 42")
+	assert.Equal(uint64(42), h.ContentLength().Unwrap())
+	h.Add("Content-Length", "43")
+	assert.True(h.ContentLength().IsNone())
+	h.Set("Content-Length", "-1")
+	assert.True(h.ContentLength().IsNone())
+}
+
+func TestHeaders_ContentType(t *testing.T) {
+	assert := assert.New(t)
+
+	h := Headers{}.Default()
+	assert.True(h.ContentType().IsNone())
+	h.Set("Content-Type", `Multipart/Form-Data; Boundary="a, b"; charset=UTF-8`)
+	assert.Equal(MediaType{Type: "multipart/form-data", Params: map[string]string{"boundary": "a, b", "charset": "UTF-8"}}, h.ContentType().Unwrap())
+	h.Set("Content-Type", "text/plain; invalid")
+	assert.Equal(MediaType{Type: "text/plain", Params: map[string]string{}}, h.ContentType().Unwrap())
+}
+
+func TestHeaders_Accept(t *testing.T) {
+	assert := assert.New(t)
+
+	h := Headers{}.Default()
+	assert.Equal([]MediaRange{{MediaType: MediaType{Type: "*/*", Params: map[string]string{}}, Q: 1}}, h.Accept())
+
+	h.Add("Accept", `text/html;level=1, text/*;q=0.5, application/json;q=0.9`)
+	h.Add("Accept", "application/XML;q=0.9, image/png;q=2, */*;q=0")
+	types := []string{}
+	for _, r := range h.Accept() {
+		types = append(types, r.Type)
+	}
+	assert.Equal([]string{"text/html", "application/json", "application/xml", "text/*", "*/*"}, types)
+	assert.Equal(map[string]string{"level": "1"}, h.Accept()[0].Params)
+	assert.Equal(0.5, h.Accept()[3].Q)
+}
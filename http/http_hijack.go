@@ -0,0 +1,84 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	stdhttp "net/http"
+	"time"
+)
+
+// HijackFunc takes over the connection of a request after the head of its 101 Switching Protocols response was written,
+// e.g. to speak the WebSocket protocol. r buffers data the client sent after the request, it must be read before conn.
+//
+// The connection is closed when the function returns.
+type HijackFunc func(conn net.Conn, r *bufio.Reader)
+
+// hijacker is implemented by responses returned by Hijack.
+type hijacker interface {
+	hijackFunc() HijackFunc
+}
+
+// hijackResponse is a response taking over the connection after it was written.
+type hijackResponse struct {
+	Response[Body]
+	hijack HijackFunc
+}
+
+func (r *hijackResponse) hijackFunc() HijackFunc {
+	return r.hijack
+}
+
+// Hijack returns res, whose status must be 101 Switching Protocols, taking over the connection with hijack after its
+// head was written. Middleware may still set headers on the returned response.
+//
+// The Server stops tracking hijacked connections, so Shutdown doesn't wait for them. A hijacked connection keeps its
+// slot of Server.MaxConnections until hijack returns.
+func Hijack(res Response[Body], hijack HijackFunc) Response[Body] {
+	return &hijackResponse{Response: res, hijack: hijack}
+}
+
+// hijackFor returns the HijackFunc of res, if res was returned by Hijack and switches protocols.
+func hijackFor(res Response[Body]) (HijackFunc, bool) {
+	h, ok := res.(hijacker)
+	if !ok || res.StatusCode() != StatusCodeSwitchingProtocols {
+		return nil, false
+	}
+	return h.hijackFunc(), true
+}
+
+// hijackConn writes the head of res to conn and hands the connection over to hijack.
+func (s *Server) hijackConn(conn net.Conn, w *bufio.Writer, parser *RequestParser, req *Request[RequestBody], res Response[Body], hijack HijackFunc) {
+	if _, err := writeResponse(w, req, res, true); err != nil {
+		return
+	}
+	s.untrackConn(conn)
+	conn.SetDeadline(time.Time{})
+	hijack(conn, parser.r)
+}
+
+// hijackStd writes the head of res to the connection hijacked from w and hands it over to hijack.
+func hijackStd(w stdhttp.ResponseWriter, res Response[Body], hijack HijackFunc) error {
+	hj, ok := w.(stdhttp.Hijacker)
+	if !ok {
+		return errors.New("[http.WriteStdResponse] response writer doesn't support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return fmt.Errorf("[http.WriteStdResponse] hijack failed: %w", err)
+	}
+	defer conn.Close()
+
+	res.SetVersion("1.1")
+	if !res.Headers().Has("Date") {
+		res.SetHeader("Date", time.Now().UTC().Format(TimeFormat))
+	}
+	writeHead(rw.Writer, res)
+	if err := rw.Flush(); err != nil {
+		return fmt.Errorf("[http.WriteStdResponse] write head failed: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	hijack(conn, rw.Reader)
+	return nil
+}
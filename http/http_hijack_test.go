@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// upgradeHandler switches to a protocol answering each line with its upper case version.
+func upgradeHandler(req *Request[RequestBody]) Response[Body] {
+	res := NewHttpResponseBytes()
+	res.SetStatusCode(StatusCodeSwitchingProtocols)
+	res.SetHeader("Upgrade", "shout")
+	res.SetHeader("Connection", "Upgrade")
+	return Hijack(res, func(conn net.Conn, r *bufio.Reader) {
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fmt.Fprint(conn, strings.ToUpper(line))
+		}
+	})
+}
+
+func TestServer_Hijack(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/upgrade", upgradeHandler))
+	r.Route(Get("/", textHandler("ok")))
+	s := &Server{Handler: r, ReadTimeout: 50 * time.Millisecond}
+
+	conn, err := s.DialPipe(context.Background(), "tcp", "")
+	assert.NoError(err)
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	// the request before the upgrade is served normally, data sent right after the upgrade request isn't lost
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\n\r\n")
+	status, _, body, err := readResponse(br)
+	assert.NoError(err)
+	assert.Equal("HTTP/1.1 200 OK", status)
+	assert.Equal("ok", body)
+	fmt.Fprint(conn, "GET /upgrade HTTP/1.1\r\n\r\nhello\n")
+	status, headers, _, err := readResponse(br)
+	assert.NoError(err)
+	assert.Equal("HTTP/1.1 101 Switching Protocols", status)
+	assert.Equal("shout", headers["Upgrade"])
+	assert.Equal("Upgrade", headers["Connection"])
+	line, _ := br.ReadString('\n')
+	assert.Equal("HELLO\n", line)
+
+	// the read timeout of the server doesn't apply to the hijacked connection
+	time.Sleep(100 * time.Millisecond)
+	fmt.Fprint(conn, "world\n")
+	line, _ = br.ReadString('\n')
+	assert.Equal("WORLD\n", line)
+
+	// hijacked connections aren't waited for by Shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(s.Shutdown(ctx))
+	fmt.Fprint(conn, "still open\n")
+	line, _ = br.ReadString('\n')
+	assert.Equal("STILL OPEN\n", line)
+}
+
+func TestServer_Hijack_RequiresSwitchingProtocols(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/", func(req *Request[RequestBody]) Response[Body] {
+		return Hijack(Text(StatusCodeOk, "ok"), func(conn net.Conn, r *bufio.Reader) {
+			fmt.Fprint(conn, "hijacked")
+		})
+	}))
+	s := &Server{Handler: r}
+
+	conn, err := s.DialPipe(context.Background(), "tcp", "")
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nConnection: close\r\n\r\n")
+	data, err := io.ReadAll(conn)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(string(data), "HTTP/1.1 200 OK\r\n"))
+	assert.True(strings.HasSuffix(string(data), "\r\n\r\nok"))
+}
+
+func TestWriteStdResponse_Hijack(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/upgrade", upgradeHandler))
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	assert.NoError(err)
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	fmt.Fprint(conn, "GET /upgrade HTTP/1.1\r\nHost: example.com\r\n\r\nhello\n")
+	status, headers, _, err := readResponse(br)
+	assert.NoError(err)
+	assert.Equal("HTTP/1.1 101 Switching Protocols", status)
+	assert.Equal("shout", headers["Upgrade"])
+	assert.NotEmpty(headers["Date"])
+	line, _ := br.ReadString('\n')
+	assert.Equal("HELLO\n", line)
+}
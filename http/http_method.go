@@ -29,6 +29,19 @@ const (
 	MethodTrace   Method = "TRACE"
 )
 
+// methods are all known methods.
+var methods = []Method{
+	MethodGet,
+	MethodHead,
+	MethodPost,
+	MethodPut,
+	MethodPatch,
+	MethodDelete,
+	MethodConnect,
+	MethodOptions,
+	MethodTrace,
+}
+
 func TryMethodFromString(s string) shepard.Result[Method, error] {
 	s = strings.TrimSpace(s)
 	switch s {
@@ -40,6 +53,8 @@ func TryMethodFromString(s string) shepard.Result[Method, error] {
 		return shepard.Ok[Method, error](MethodPost)
 	case MethodPut.String():
 		return shepard.Ok[Method, error](MethodPut)
+	case MethodPatch.String():
+		return shepard.Ok[Method, error](MethodPatch)
 	case MethodDelete.String():
 		return shepard.Ok[Method, error](MethodDelete)
 	case MethodConnect.String():
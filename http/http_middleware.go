@@ -2,6 +2,13 @@ package http
 
 import "github.com/marlaone/shepard"
 
+// Next invokes the rest of the middleware chain and finally the handler, and returns their shepard.Result.
 type Next func() shepard.Result[Response[Body], error]
 
+// Middleware wraps the handling of a request.
+//
+// Middleware is invoked in the order it was added, each one wrapping the ones added after it:
+// code before calling next runs on the way in, code after it runs on the way out and may inspect or replace the Response.
+// Returning without calling next short-circuits the chain, the handler is not invoked then.
+// A shepard.Err returned by next propagates outwards through the remaining middleware, which may turn it into a Response.
 type Middleware func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error]
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+// trace returns a middleware recording when it is entered and left.
+func trace(calls *[]string, name string) Middleware {
+	return func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		*calls = append(*calls, name+" in")
+		res := next()
+		*calls = append(*calls, name+" out")
+		return res
+	}
+}
+
+func tracedHandler(calls *[]string) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		*calls = append(*calls, "handler")
+		return textHandler("handler")(req)
+	}
+}
+
+func TestMiddleware_Order(t *testing.T) {
+	var calls []string
+
+	r := NewRouter().Use(trace(&calls, "global 1")).Use(trace(&calls, "global 2"))
+	api := r.Group("/api", trace(&calls, "group"))
+	api.Route(Get("/users", tracedHandler(&calls)).Use(trace(&calls, "route 1"), trace(&calls, "route 2")))
+
+	res := r.Serve(newTestRequest(MethodGet, "/api/users"))
+	assert.Equal(t, "handler", readBody(res.Unwrap()))
+	assert.Equal(t, []string{
+		"global 1 in",
+		"global 2 in",
+		"group in",
+		"route 1 in",
+		"route 2 in",
+		"handler",
+		"route 2 out",
+		"route 1 out",
+		"group out",
+		"global 2 out",
+		"global 1 out",
+	}, calls)
+}
+
+func TestMiddleware_ShortCircuit(t *testing.T) {
+	var calls []string
+
+	r := NewRouter().
+		Use(trace(&calls, "outer")).
+		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+			calls = append(calls, "cache hit")
+			return shepard.Ok[Response[Body], error](textHandler("cached")(req))
+		}).
+		Use(trace(&calls, "inner")).
+		Route(Get("/", tracedHandler(&calls)))
+
+	res := r.Serve(newTestRequest(MethodGet, "/"))
+	assert.Equal(t, "cached", readBody(res.Unwrap()))
+	assert.Equal(t, []string{"outer in", "cache hit", "outer out"}, calls)
+}
+
+func TestMiddleware_PostProcessing(t *testing.T) {
+	r := NewRouter().
+		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+			res := next()
+			if res.IsOk() {
+				res.Unwrap().SetHeader("X-Powered-By", "shepard")
+			}
+			return res
+		}).
+		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+			res := next()
+			if res.IsOk() && res.Unwrap().StatusCode() == StatusCodeNotFound {
+				return shepard.Ok[Response[Body], error](textHandler("replaced")(req))
+			}
+			return res
+		})
+
+	res := r.Serve(newTestRequest(MethodGet, "/missing")).Unwrap()
+	assert.Equal(t, StatusCodeOk, res.StatusCode())
+	assert.Equal(t, "replaced", readBody(res))
+	assert.Equal(t, slice.Init("shepard"), res.Headers().Get("X-Powered-By"))
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	var calls []string
+	errBoom := errors.New("boom")
+
+	failing := func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		calls = append(calls, "failing")
+		return shepard.Err[Response[Body], error](errBoom)
+	}
+
+	r := NewRouter().Use(trace(&calls, "outer")).Use(failing).Use(trace(&calls, "inner"))
+	r.Route(Get("/", tracedHandler(&calls)))
+
+	res := r.Serve(newTestRequest(MethodGet, "/"))
+	assert.ErrorIs(t, res.UnwrapErr(), errBoom)
+	assert.Equal(t, []string{"outer in", "failing", "outer out"}, calls)
+
+	// an outer middleware can recover from errors of the inner ones
+	recovering := NewRouter().
+		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+			res := next()
+			if res.IsErr() {
+				errRes := NewResponseBuilder(NewHttpResponseBytes()).Status(StatusCodeInternalServerError).Body(NewBytesBody()).Unwrap()
+				errRes.Body().Close()
+				return shepard.Ok[Response[Body], error](errRes)
+			}
+			return res
+		}).
+		Use(failing)
+	recovering.Route(Get("/", tracedHandler(&calls)))
+
+	assert.Equal(t, StatusCodeInternalServerError, recovering.Serve(newTestRequest(MethodGet, "/")).Unwrap().StatusCode())
+}
+
+func TestMiddleware_HandlerRunsOnce(t *testing.T) {
+	var calls []string
+
+	r := NewRouter().Use(trace(&calls, "a")).Use(trace(&calls, "b")).Use(trace(&calls, "c"))
+	r.Route(Get("/", tracedHandler(&calls)))
+	r.Serve(newTestRequest(MethodGet, "/"))
+
+	handlerCalls := 0
+	for _, call := range calls {
+		if call == "handler" {
+			handlerCalls++
+		}
+	}
+	assert.Equal(t, 1, handlerCalls)
+}
+
+func TestMiddleware_RepeatedRequests(t *testing.T) {
+	var calls []string
+
+	r := NewRouter()
+	r.Group("/api", trace(&calls, "group")).Route(Get("/users", tracedHandler(&calls)).Use(trace(&calls, "route")))
+
+	for i := 0; i < 2; i++ {
+		calls = nil
+		r.Serve(newTestRequest(MethodGet, "/api/users"))
+		assert.Equal(t, []string{"group in", "route in", "handler", "route out", "group out"}, calls)
+	}
+}
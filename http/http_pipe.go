@@ -0,0 +1,183 @@
+package http
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// pipeAddr is the address of both ends of a pipe.
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+// pipeBuffer holds the data written to one end of a pipe until the other end reads it.
+type pipeBuffer struct {
+	mu       sync.Mutex
+	data     []byte
+	closed   bool
+	deadline time.Time
+	// notify wakes up a blocked reader after a write, close or deadline change
+	notify chan struct{}
+}
+
+func newPipeBuffer() *pipeBuffer {
+	return &pipeBuffer{notify: make(chan struct{}, 1)}
+}
+
+func (b *pipeBuffer) signal() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (b *pipeBuffer) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.signal()
+}
+
+func (b *pipeBuffer) setDeadline(t time.Time) {
+	b.mu.Lock()
+	b.deadline = t
+	b.mu.Unlock()
+	b.signal()
+}
+
+// pipeConn is one end of an in-memory connection. Unlike the ends of net.Pipe, writes are buffered and don't wait for
+// the peer to read, as on a TCP connection, so both ends may write at the same time.
+type pipeConn struct {
+	// r buffers the data written by the peer, w the data written to the peer
+	r, w *pipeBuffer
+
+	mu       sync.Mutex
+	closed   bool
+	deadline time.Time
+}
+
+// newPipe returns the two ends of an in-memory connection.
+func newPipe() (net.Conn, net.Conn) {
+	a, b := newPipeBuffer(), newPipeBuffer()
+	return &pipeConn{r: a, w: b}, &pipeConn{r: b, w: a}
+}
+
+func (c *pipeConn) Read(p []byte) (int, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		if c.isClosed() {
+			return 0, c.opError("read", net.ErrClosed)
+		}
+		c.r.mu.Lock()
+		if len(c.r.data) > 0 {
+			n := copy(p, c.r.data)
+			c.r.data = c.r.data[n:]
+			c.r.mu.Unlock()
+			return n, nil
+		}
+		closed, deadline := c.r.closed, c.r.deadline
+		c.r.mu.Unlock()
+		if closed {
+			return 0, io.EOF
+		}
+
+		var expired <-chan time.Time
+		if !deadline.IsZero() {
+			wait := time.Until(deadline)
+			if wait <= 0 {
+				return 0, c.opError("read", os.ErrDeadlineExceeded)
+			}
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
+			expired = timer.C
+		}
+		select {
+		case <-c.r.notify:
+		case <-expired:
+		}
+		if timer != nil && !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+func (c *pipeConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	closed, deadline := c.closed, c.deadline
+	c.mu.Unlock()
+	if closed {
+		return 0, c.opError("write", net.ErrClosed)
+	}
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, c.opError("write", os.ErrDeadlineExceeded)
+	}
+
+	c.w.mu.Lock()
+	if c.w.closed {
+		c.w.mu.Unlock()
+		return 0, c.opError("write", io.ErrClosedPipe)
+	}
+	c.w.data = append(c.w.data, p...)
+	c.w.mu.Unlock()
+	c.w.signal()
+	return len(p), nil
+}
+
+func (c *pipeConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return c.opError("close", net.ErrClosed)
+	}
+	c.closed = true
+	c.mu.Unlock()
+	// the peer reads the rest of the data before io.EOF, while data it writes from now on is dropped
+	c.w.close()
+	c.r.close()
+	return nil
+}
+
+func (c *pipeConn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *pipeConn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: "pipe", Addr: pipeAddr{}, Err: err}
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return pipeAddr{} }
+func (c *pipeConn) RemoteAddr() net.Addr { return pipeAddr{} }
+
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+	return c.SetWriteDeadline(t)
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	c.r.setDeadline(t)
+	return nil
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return nil
+}
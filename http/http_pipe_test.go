@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipe(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := newPipe()
+
+	// writes don't wait for the peer to read
+	n, err := a.Write([]byte("hello"))
+	assert.NoError(err)
+	assert.Equal(5, n)
+	_, err = b.Write([]byte("world"))
+	assert.NoError(err)
+
+	buf := make([]byte, 3)
+	n, _ = b.Read(buf)
+	assert.Equal("hel", string(buf[:n]))
+	n, _ = b.Read(buf)
+	assert.Equal("lo", string(buf[:n]))
+	data := make([]byte, 5)
+	_, err = io.ReadFull(a, data)
+	assert.NoError(err)
+	assert.Equal("world", string(data))
+
+	// a blocked read returns once data is written
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		a.Write([]byte("later"))
+	}()
+	n, err = b.Read(data)
+	assert.NoError(err)
+	assert.Equal("later", string(data[:n]))
+
+	// the peer reads the rest of the data after close
+	a.Write([]byte("rest"))
+	assert.NoError(a.Close())
+	assert.ErrorIs(a.Close(), net.ErrClosed)
+	n, _ = b.Read(data)
+	assert.Equal("rest", string(data[:n]))
+	_, err = b.Read(data)
+	assert.Equal(io.EOF, err)
+	_, err = b.Write([]byte("dropped"))
+	assert.ErrorIs(err, io.ErrClosedPipe)
+	_, err = a.Read(data)
+	assert.ErrorIs(err, net.ErrClosed)
+	_, err = a.Write(data)
+	assert.ErrorIs(err, net.ErrClosed)
+	assert.Equal("pipe", a.RemoteAddr().String())
+}
+
+func TestPipe_Deadlines(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := newPipe()
+	defer a.Close()
+	defer b.Close()
+
+	a.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	start := time.Now()
+	_, err := a.Read(make([]byte, 1))
+	assert.ErrorIs(err, os.ErrDeadlineExceeded)
+	var netErr net.Error
+	assert.True(errors.As(err, &netErr) && netErr.Timeout())
+	assert.GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+
+	// an extended deadline applies to a blocked read
+	a.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		a.SetReadDeadline(time.Time{})
+		time.Sleep(30 * time.Millisecond)
+		b.Write([]byte("x"))
+	}()
+	_, err = a.Read(make([]byte, 1))
+	assert.NoError(err)
+
+	// closing a conn ends its blocked read
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		a.Close()
+	}()
+	a.SetReadDeadline(time.Time{})
+	_, err = a.Read(make([]byte, 1))
+	assert.ErrorIs(err, net.ErrClosed)
+
+	b.SetWriteDeadline(time.Now().Add(-time.Second))
+	_, err = b.Write([]byte("x"))
+	assert.ErrorIs(err, os.ErrDeadlineExceeded)
+}
@@ -1,29 +1,27 @@
 package http
 
 import (
-	"bufio"
 	"context"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
 )
 
 type RequestBody io.Reader
 
+// ParseParams parses the url encoded form in body, which may be at most DefaultMaxBodyBytes large.
 func ParseParams(body RequestBody) shepard.Result[Values, error] {
-	scanner := bufio.NewScanner(body)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		res := ParseQuery(line)
-		if res.IsErr() {
-			return shepard.Err[Values, error](res.Err().Unwrap())
-		}
-		return shepard.Ok[Values, error](res.Ok().Unwrap())
+	data, err := io.ReadAll(io.LimitReader(body, int64(DefaultMaxBodyBytes)+1))
+	if err != nil {
+		return shepard.Err[Values, error](err)
 	}
-
-	return shepard.Err[Values, error](scanner.Err())
+	if len(data) > int(DefaultMaxBodyBytes) {
+		return shepard.Err[Values, error](fmt.Errorf("[http.ParseParams] form exceeds %s: %w", DefaultMaxBodyBytes, ErrBodyTooLarge))
+	}
+	return ParseQuery(strings.TrimSpace(string(data)))
 }
 
 type Request[T RequestBody] struct {
@@ -32,8 +30,16 @@ type Request[T RequestBody] struct {
 	URL     URL
 	Version Version
 	Headers Headers
-	params  *Values
-	body    T
+	// RemoteAddr is the network address of the client, e.g. "192.0.2.1:51234". It's set by the server, not by a proxy.
+	RemoteAddr string
+	params     *Values
+	body       T
+
+	// pathParams are the values of the path parameters of the matched route
+	pathParams hashmap.HashMap[string, string]
+
+	// closeConn is true if the connection must be closed after the response to this request
+	closeConn bool
 }
 
 func (r Request[T]) Default() Request[T] {
@@ -48,6 +54,24 @@ func (r Request[T]) Default() Request[T] {
 	}
 }
 
+// KeepAlive returns true if the connection the request was received on may be reused for further requests.
+//
+// HTTP/1.1 connections are persistent unless the client sent "Connection: close",
+// HTTP/1.0 connections only if the client sent "Connection: keep-alive".
+func (r *Request[T]) KeepAlive() bool {
+	return !r.closeConn
+}
+
+// Param returns the value of the path parameter name of the route matching the request,
+// e.g. the value of "id" for the pattern "/users/:id" or of "path" for "/files/*path".
+func (r *Request[T]) Param(name string) shepard.Option[string] {
+	value := r.pathParams.Get(name)
+	if value.IsNone() {
+		return shepard.None[string]()
+	}
+	return shepard.Some(*value.Unwrap())
+}
+
 func (r *Request[T]) Params() *Values {
 	return r.params
 }
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxChunkLineBytes limits the size of a chunk size line including chunk extensions.
+const maxChunkLineBytes = 4096
+
+// noBody is the RequestBody of requests without a body.
+type noBody struct{}
+
+func (noBody) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+// lengthBody is a RequestBody of a fixed size given by the Content-Length header.
+type lengthBody struct {
+	r         io.Reader
+	remaining uint64
+}
+
+func (b *lengthBody) Read(p []byte) (int, error) {
+	if b.remaining == 0 {
+		return 0, io.EOF
+	}
+	if uint64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= uint64(n)
+	if err == io.EOF && b.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// chunkedBody is a RequestBody sent with "Transfer-Encoding: chunked". Trailers are discarded.
+type chunkedBody struct {
+	r               *bufio.Reader
+	maxTrailerBytes int
+
+	// remaining bytes of the current chunk
+	remaining uint64
+	// started is true once the first chunk header has been read
+	started bool
+	// err is returned by every Read once the body is finished or broken
+	err error
+}
+
+func (b *chunkedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.remaining == 0 {
+		if err := b.nextChunk(); err != nil {
+			b.err = err
+			return 0, err
+		}
+	}
+	if uint64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= uint64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// nextChunk reads the header of the next chunk. io.EOF is returned after the last chunk and the trailers have been read.
+func (b *chunkedBody) nextChunk() error {
+	if b.started {
+		// every chunk is terminated by CRLF
+		line, err := readLine(b.r, 2)
+		if err == ErrHeaderTooLarge || (err == nil && len(line) > 0) {
+			return b.malformed("missing chunk terminator", nil)
+		}
+		if err != nil {
+			return b.malformed("read chunk terminator failed", err)
+		}
+	}
+	b.started = true
+
+	line, err := readLine(b.r, maxChunkLineBytes)
+	if err != nil {
+		return b.malformed("read chunk size failed", err)
+	}
+	// chunk extensions are ignored
+	if i := bytes.IndexByte(line, ';'); i >= 0 {
+		line = line[:i]
+	}
+	line = bytes.TrimSpace(line)
+	size, err := strconv.ParseUint(string(line), 16, 63)
+	if err != nil {
+		return b.malformed(fmt.Sprintf("invalid chunk size %q", line), nil)
+	}
+	if size > 0 {
+		b.remaining = size
+		return nil
+	}
+
+	// the last chunk is followed by the trailers and an empty line
+	budget := b.maxTrailerBytes
+	for {
+		line, err := readLine(b.r, budget)
+		if err != nil {
+			return b.malformed("read trailer failed", err)
+		}
+		if len(line) == 0 {
+			return io.EOF
+		}
+		budget -= len(line) + 2
+	}
+}
+
+func (b *chunkedBody) malformed(msg string, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return fmt.Errorf("[http.chunkedBody] %s: %w", msg, err)
+	}
+	return fmt.Errorf("[http.chunkedBody] %s: %w", msg, ErrMalformedRequest)
+}
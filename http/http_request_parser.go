@@ -0,0 +1,308 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+)
+
+// DefaultMaxHeaderBytes is the default limit for the size of the request line and headers of a request.
+const DefaultMaxHeaderBytes = 1 << 20
+
+var (
+	ErrMalformedRequest            = errors.New("malformed request")
+	ErrHeaderTooLarge              = errors.New("request header too large")
+	ErrHTTPVersionNotSupported     = errors.New("http version not supported")
+	ErrUnsupportedTransferEncoding = errors.New("unsupported transfer encoding")
+)
+
+type ParserOptions struct {
+	// MaxHeaderBytes limits the size of the request line and headers, and of the trailers of chunked bodies.
+	MaxHeaderBytes int
+	// Scheme is the URL scheme of the requests, "http" or "https" depending on the connection.
+	Scheme string
+	// TrustForwardedHeaders makes the X-Forwarded-Host and X-Forwarded-Proto headers override the host and scheme of the
+	// URL. Enable it only behind a reverse proxy which sets them, otherwise any client can choose the host and scheme.
+	TrustForwardedHeaders bool
+}
+
+func (o ParserOptions) Default() ParserOptions {
+	return ParserOptions{
+		MaxHeaderBytes: DefaultMaxHeaderBytes,
+		Scheme:         "http",
+	}
+}
+
+// RequestParser reads HTTP/1.x requests from a stream, e.g. a persistent connection.
+//
+// Requests are read incrementally, so headers and bodies may arrive in any number of segments,
+// and pipelined requests are returned one after another.
+type RequestParser struct {
+	r    *bufio.Reader
+	opts ParserOptions
+
+	// body of the previous request, which has to be consumed before the next request can be read
+	body RequestBody
+}
+
+func NewRequestParser(r io.Reader, opts ParserOptions) *RequestParser {
+	if opts.MaxHeaderBytes <= 0 {
+		opts.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	if opts.Scheme == "" {
+		opts.Scheme = "http"
+	}
+	return &RequestParser{
+		r:    bufio.NewReader(r),
+		opts: opts,
+	}
+}
+
+// discardBody reads and discards the unread rest of the body of the previous request, up to limit bytes.
+// Returns false if the body is longer or broken, so the stream can't be used for further requests.
+func (p *RequestParser) discardBody(limit int64) bool {
+	if p.body == nil {
+		return true
+	}
+	n, err := io.CopyN(io.Discard, p.body, limit+1)
+	if err == io.EOF {
+		p.body = nil
+		return true
+	}
+	return err == nil && n <= limit
+}
+
+// Next reads the next request from the stream. The unread rest of the body of the previous request is discarded.
+//
+// The body of the returned request streams from the underlying reader and is only valid until Next is called again.
+// io.EOF is returned unwrapped if the stream ended cleanly before a new request started.
+func (p *RequestParser) Next() shepard.Result[Request[RequestBody], error] {
+	if p.body != nil {
+		_, err := io.Copy(io.Discard, p.body)
+		p.body = nil
+		if err != nil {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] discard previous body failed: %w", err))
+		}
+	}
+
+	budget := p.opts.MaxHeaderBytes
+
+	// read request line, empty lines in front of it are ignored
+	var line []byte
+	for len(line) == 0 {
+		l, err := p.readHeaderLine(&budget)
+		if err == io.EOF {
+			return shepard.Err[Request[RequestBody], error](io.EOF)
+		}
+		if err != nil {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] read request line failed: %w", err))
+		}
+		line = l
+	}
+
+	parts := strings.Split(string(line), " ")
+	if len(parts) != 3 || parts[1] == "" {
+		return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] invalid request line %q: %w", line, ErrMalformedRequest))
+	}
+
+	// check if method is valid
+	method := TryMethodFromString(parts[0])
+	if method.IsErr() {
+		return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] %s: %w", method.UnwrapErr(), ErrMalformedRequest))
+	}
+
+	urlRes := ParseRequestURI(parts[1])
+	if urlRes.IsErr() {
+		return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] parse url failed: %s: %w", urlRes.UnwrapErr(), ErrMalformedRequest))
+	}
+
+	version := parseVersion(parts[2])
+	if version.IsErr() {
+		return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] %w", version.UnwrapErr()))
+	}
+
+	// create request builder
+	builder := NewRequestBuilder[RequestBody]().Method(method.Unwrap()).Version(version.Unwrap()).URL(urlRes.Unwrap())
+
+	var contentLength shepard.Option[uint64]
+
+	// read headers
+	for {
+		line, err := p.readHeaderLine(&budget)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] read header line failed: %w", err))
+		}
+
+		// an empty line terminates the headers
+		if len(line) == 0 {
+			break
+		}
+
+		// obsolete line folding is not supported
+		if line[0] == ' ' || line[0] == '\t' {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] folded header line: %w", ErrMalformedRequest))
+		}
+
+		key, headerValue, found := bytes.Cut(line, headerKeySeparator)
+		if !found || len(key) == 0 || bytes.ContainsAny(key, " \t") {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] invalid header line %q: %w", line, ErrMalformedRequest))
+		}
+
+		// values are kept as received, lists are split when they are read
+		builder.request.Headers.Add(string(key), string(bytes.TrimSpace(headerValue)))
+	}
+
+	headers := &builder.request.Headers
+	if headers.Has("Content-Length") {
+		contentLength = headers.ContentLength()
+		if contentLength.IsNone() {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] invalid content length %q: %w", headers.Value("Content-Length"), ErrMalformedRequest))
+		}
+	}
+	transferEncoding := headers.List("Transfer-Encoding")
+	connection := headers.List("Connection")
+	// several Host headers are joined into an invalid host
+	host := headers.Value("Host")
+	forwardedProto := ""
+	if p.opts.TrustForwardedHeaders {
+		if forwarded := headers.List("X-Forwarded-Host"); len(forwarded) > 0 {
+			host = forwarded[0]
+		}
+		if forwarded := headers.List("X-Forwarded-Proto"); len(forwarded) > 0 {
+			forwardedProto = strings.ToLower(forwarded[0])
+		}
+	}
+
+	builder.request.URL.Scheme = p.opts.Scheme
+	if forwardedProto == "http" || forwardedProto == "https" {
+		builder.request.URL.Scheme = forwardedProto
+	}
+
+	if host != "" {
+		hostname, port, err := parseHost(host, builder.request.URL.Scheme)
+		if err != nil {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] %s: %w", err, ErrMalformedRequest))
+		}
+		builder.request.URL.Host = hostname
+		builder.request.URL.Port = port
+	}
+
+	builder.request.closeConn = shouldClose(builder.request.Version, connection)
+
+	var body RequestBody = noBody{}
+	switch {
+	case len(transferEncoding) > 0:
+		// a message with both headers might be an attempt of request smuggling
+		if contentLength.IsSome() {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] both Transfer-Encoding and Content-Length given: %w", ErrMalformedRequest))
+		}
+		if !strings.EqualFold(transferEncoding[len(transferEncoding)-1], "chunked") {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.RequestParser.Next] %q: %w", strings.Join(transferEncoding, ", "), ErrUnsupportedTransferEncoding))
+		}
+		body = &chunkedBody{r: p.r, maxTrailerBytes: p.opts.MaxHeaderBytes}
+	case contentLength.IsSome() && contentLength.Unwrap() > 0:
+		body = &lengthBody{r: p.r, remaining: contentLength.Unwrap()}
+	}
+	p.body = body
+
+	return builder.Body(body)
+}
+
+// readHeaderLine reads a line terminated by CRLF or LF and returns it without the line terminator.
+// The size of the line is subtracted from budget, ErrHeaderTooLarge is returned if it is exceeded.
+//
+// io.EOF is only returned if the stream ended before the first byte of the line.
+func (p *RequestParser) readHeaderLine(budget *int) ([]byte, error) {
+	line, err := readLine(p.r, *budget)
+	*budget -= len(line) + 2
+	return line, err
+}
+
+// readLine reads a line of at most limit bytes, including the line terminator, and returns it without the line terminator.
+func readLine(r *bufio.Reader, limit int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > limit {
+			return nil, ErrHeaderTooLarge
+		}
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(line) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line, nil
+}
+
+func parseVersion(proto string) shepard.Result[Version, error] {
+	v := strings.TrimPrefix(proto, "HTTP/")
+	if len(v) == len(proto) || len(v) != 3 || v[1] != '.' || !isDigit(v[0]) || !isDigit(v[2]) {
+		return shepard.Err[Version, error](fmt.Errorf("invalid protocol %q: %w", proto, ErrMalformedRequest))
+	}
+	if v[0] != '1' {
+		return shepard.Err[Version, error](fmt.Errorf("%q: %w", proto, ErrHTTPVersionNotSupported))
+	}
+	return shepard.Ok[Version, error](Version(v))
+}
+
+// shouldClose reports whether the connection has to be closed after a request with the given version and Connection header values.
+func shouldClose(version Version, connection []string) bool {
+	for _, token := range connection {
+		if strings.EqualFold(token, "close") {
+			return true
+		}
+		if strings.EqualFold(token, "keep-alive") {
+			return false
+		}
+	}
+	return version == "1.0"
+}
+
+// parseHost splits the value of a Host header into the host name and the port, which defaults to the default port of scheme.
+func parseHost(host string, scheme string) (string, uint16, error) {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// the host has no port
+		return strings.Trim(host, "[]"), defaultPort(scheme), nil
+	}
+	if port == "" {
+		return hostname, defaultPort(scheme), nil
+	}
+	n := num.ParseString[uint16](port)
+	if n.IsErr() {
+		return "", 0, fmt.Errorf("parse port failed: %s", n.UnwrapErr())
+	}
+	return hostname, n.Unwrap(), nil
+}
+
+func defaultPort(scheme string) uint16 {
+	if scheme == "https" {
+		return 443
+	}
+	return 80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
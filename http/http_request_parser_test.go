@@ -0,0 +1,251 @@
+package http
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestParser_Next(t *testing.T) {
+	assert := assert.New(t)
+
+	raw := "POST /users?id=1 HTTP/1.1\r\n" +
+		"Host: example.com:8080\r\n" +
+		"Accept: text/html, application/json\r\n" +
+		"Accept: text/plain\r\n" +
+		"Content-Length: 11\r\n" +
+		"\r\n" +
+		"hello world"
+
+	// deliver the request one byte at a time to simulate many TCP segments
+	parser := NewRequestParser(iotest.OneByteReader(strings.NewReader(raw)), ParserOptions{}.Default())
+	res := parser.Next()
+	assert.True(res.IsOk(), res)
+
+	req := res.Unwrap()
+	assert.Equal(MethodPost, req.Method)
+	assert.Equal("/users", req.URL.Path)
+	assert.Equal("id=1", req.URL.RawQuery)
+	assert.Equal("example.com", req.URL.Host)
+	assert.Equal(uint16(8080), req.URL.Port)
+	assert.Equal(Version("1.1"), req.Version)
+	assert.Equal(slice.Init("text/html, application/json", "text/plain"), req.Headers.Get("Accept"))
+	assert.Equal([]string{"text/html", "application/json", "text/plain"}, req.Headers.List("Accept"))
+	assert.True(req.KeepAlive())
+
+	body, err := io.ReadAll(req.body)
+	assert.NoError(err)
+	assert.Equal("hello world", string(body))
+
+	assert.Equal(io.EOF, parser.Next().UnwrapErr())
+}
+
+func TestRequestParser_Next_LargeHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	cookie := strings.Repeat("a", 8192)
+	raw := "GET / HTTP/1.1\r\nHost: localhost\r\nCookie: " + cookie + "\r\n\r\n"
+
+	res := NewRequestParser(strings.NewReader(raw), ParserOptions{}.Default()).Next()
+	assert.True(res.IsOk(), res)
+	req := res.Unwrap()
+	assert.Equal(cookie, *req.Headers.Get("Cookie").First().Unwrap())
+	assert.Equal("localhost", req.URL.Host)
+	assert.Equal(uint16(80), req.URL.Port)
+
+	res = NewRequestParser(strings.NewReader(raw), ParserOptions{MaxHeaderBytes: 4096}).Next()
+	assert.ErrorIs(res.UnwrapErr(), ErrHeaderTooLarge)
+}
+
+func TestRequestParser_Next_Chunked(t *testing.T) {
+	assert := assert.New(t)
+
+	raw := "POST /upload HTTP/1.1\r\n" +
+		"Transfer-Encoding: chunked\r\n" +
+		"\r\n" +
+		"5\r\nhello\r\n" +
+		"6;name=value\r\n world\r\n" +
+		"0\r\n" +
+		"Checksum: abc\r\n" +
+		"\r\n" +
+		"GET /next HTTP/1.1\r\n\r\n"
+
+	parser := NewRequestParser(iotest.OneByteReader(strings.NewReader(raw)), ParserOptions{}.Default())
+	req := parser.Next().Unwrap()
+
+	body, err := io.ReadAll(req.body)
+	assert.NoError(err)
+	assert.Equal("hello world", string(body))
+
+	next := parser.Next()
+	assert.True(next.IsOk(), next)
+	assert.Equal("/next", next.Unwrap().URL.Path)
+}
+
+func TestRequestParser_Next_ChunkedMalformed(t *testing.T) {
+	testCases := map[string]string{
+		"invalid size":       "zz\r\nhello\r\n0\r\n\r\n",
+		"missing terminator": "5\r\nhelloX\r\n0\r\n\r\n",
+		"truncated":          "5\r\nhel",
+	}
+	for name, chunks := range testCases {
+		t.Run(name, func(t *testing.T) {
+			raw := "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n" + chunks
+			req := NewRequestParser(strings.NewReader(raw), ParserOptions{}.Default()).Next().Unwrap()
+			_, err := io.ReadAll(req.body)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRequestParser_Next_Pipelined(t *testing.T) {
+	assert := assert.New(t)
+
+	raw := "POST /a HTTP/1.1\r\nContent-Length: 5\r\n\r\nfirst" +
+		"POST /b HTTP/1.1\r\nContent-Length: 6\r\n\r\nsecond" +
+		"\r\n" +
+		"GET /c HTTP/1.1\r\nConnection: close\r\n\r\n"
+
+	parser := NewRequestParser(strings.NewReader(raw), ParserOptions{}.Default())
+
+	// the body of the first request is never read and has to be skipped
+	a := parser.Next().Unwrap()
+	assert.Equal("/a", a.URL.Path)
+
+	b := parser.Next().Unwrap()
+	assert.Equal("/b", b.URL.Path)
+	body, err := io.ReadAll(b.body)
+	assert.NoError(err)
+	assert.Equal("second", string(body))
+
+	c := parser.Next().Unwrap()
+	assert.Equal("/c", c.URL.Path)
+	assert.False(c.KeepAlive())
+
+	assert.Equal(io.EOF, parser.Next().UnwrapErr())
+}
+
+func TestRequestParser_Next_KeepAlive(t *testing.T) {
+	testCases := []struct {
+		raw       string
+		keepAlive bool
+	}{
+		{raw: "GET / HTTP/1.1\r\n\r\n", keepAlive: true},
+		{raw: "GET / HTTP/1.1\r\nconnection: Close\r\n\r\n", keepAlive: false},
+		{raw: "GET / HTTP/1.0\r\n\r\n", keepAlive: false},
+		{raw: "GET / HTTP/1.0\r\nConnection: keep-alive\r\n\r\n", keepAlive: true},
+	}
+	for _, tc := range testCases {
+		req := NewRequestParser(strings.NewReader(tc.raw), ParserOptions{}.Default()).Next().Unwrap()
+		assert.Equal(t, tc.keepAlive, req.KeepAlive(), tc.raw)
+	}
+}
+
+func TestRequestParser_Next_Errors(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+		err  error
+	}{
+		{name: "invalid request line", raw: "GET /\r\n\r\n", err: ErrMalformedRequest},
+		{name: "invalid method", raw: "FETCH / HTTP/1.1\r\n\r\n", err: ErrMalformedRequest},
+		{name: "invalid protocol", raw: "GET / HTTX/1.1\r\n\r\n", err: ErrMalformedRequest},
+		{name: "unsupported version", raw: "GET / HTTP/2.0\r\n\r\n", err: ErrHTTPVersionNotSupported},
+		{name: "invalid header", raw: "GET / HTTP/1.1\r\nHost localhost\r\n\r\n", err: ErrMalformedRequest},
+		{name: "space before colon", raw: "GET / HTTP/1.1\r\nHost : localhost\r\n\r\n", err: ErrMalformedRequest},
+		{name: "folded header", raw: "GET / HTTP/1.1\r\nX-A: a\r\n b\r\n\r\n", err: ErrMalformedRequest},
+		{name: "invalid content length", raw: "POST / HTTP/1.1\r\nContent-Length: -1\r\n\r\n", err: ErrMalformedRequest},
+		{name: "conflicting content length", raw: "POST / HTTP/1.1\r\nContent-Length: 1, 2\r\n\r\n", err: ErrMalformedRequest},
+		{name: "content length and chunked", raw: "POST / HTTP/1.1\r\nContent-Length: 1\r\nTransfer-Encoding: chunked\r\n\r\n", err: ErrMalformedRequest},
+		{name: "unsupported transfer encoding", raw: "POST / HTTP/1.1\r\nTransfer-Encoding: gzip\r\n\r\n", err: ErrUnsupportedTransferEncoding},
+		{name: "invalid port", raw: "GET / HTTP/1.1\r\nHost: localhost:http\r\n\r\n", err: ErrMalformedRequest},
+		{name: "truncated headers", raw: "GET / HTTP/1.1\r\nHost: localhost\r\n", err: io.ErrUnexpectedEOF},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewRequestParser(strings.NewReader(tc.raw), ParserOptions{}.Default()).Next()
+			assert.ErrorIs(t, res.UnwrapErr(), tc.err)
+		})
+	}
+}
+
+func TestRequestParser_Next_TruncatedBody(t *testing.T) {
+	raw := "POST / HTTP/1.1\r\nContent-Length: 10\r\n\r\nshort"
+	parser := NewRequestParser(strings.NewReader(raw), ParserOptions{}.Default())
+	req := parser.Next().Unwrap()
+
+	_, err := io.ReadAll(req.body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestServeConn_KeepAlive(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter().Route(Post("/echo", func(req *Request[RequestBody]) Response[Body] {
+		body, _ := io.ReadAll(req.body)
+		res := NewResponseBuilder(NewHttpResponseBytes()).Header("Content-Length", "5").Body(NewBytesBody()).Unwrap()
+		res.Body().Write(slice.Init(body[:5]...))
+		res.Body().Close()
+		return res
+	}))
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go serveTestConn(&Server{Handler: r}, server)
+
+	go func() {
+		client.Write([]byte("POST /echo HTTP/1.1\r\nContent-Length: 5\r\n\r\nfirst"))
+		client.Write([]byte("POST /echo HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n6\r\nsecond\r\n0\r\n\r\n"))
+		client.Write([]byte("POST /echo HTTP/1.1\r\nContent-Length: 5\r\nConnection: close\r\n\r\nthird"))
+	}()
+
+	reader := bufio.NewReader(client)
+	for _, expected := range []string{"first", "secon", "third"} {
+		status, err := reader.ReadString('\n')
+		assert.NoError(err)
+		assert.Equal("HTTP/1.1 200 OK\r\n", status)
+
+		connection := ""
+		for {
+			line, err := reader.ReadString('\n')
+			assert.NoError(err)
+			if line == "\r\n" {
+				break
+			}
+			if strings.HasPrefix(line, "Connection: ") {
+				connection = line
+			}
+		}
+		body := make([]byte, 5)
+		_, err = io.ReadFull(reader, body)
+		assert.NoError(err)
+		assert.Equal(expected, string(body))
+
+		if expected == "third" {
+			assert.Equal("Connection: close\r\n", connection)
+		} else {
+			assert.Empty(connection)
+		}
+	}
+
+	_, err := reader.ReadByte()
+	assert.Equal(io.EOF, err)
+}
+
+func TestServeConn_BadRequest(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go serveTestConn(&Server{Handler: NewRouter(), Logger: &testLogger{}}, server)
+
+	go client.Write([]byte("GET / HTTP/1.1\r\nbroken\r\n\r\n"))
+
+	status, err := bufio.NewReader(client).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 400 Bad Request\r\n", status)
+}
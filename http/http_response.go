@@ -3,8 +3,6 @@ package http
 import (
 	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/collections/hashmap"
-	"github.com/marlaone/shepard/collections/slice"
-	"github.com/marlaone/shepard/iter"
 	"github.com/marlaone/shepard/sync/io"
 )
 
@@ -43,41 +41,6 @@ type Body interface {
 	Closed() bool
 }
 
-type Headers struct {
-	headers hashmap.HashMap[string, slice.Slice[string]]
-}
-
-func (h Headers) Default() Headers {
-	return Headers{
-		headers: hashmap.WithCapacity[string, slice.Slice[string]](16),
-	}
-}
-
-func (h *Headers) Has(key string) bool {
-	return h.headers.ContainsKey(key)
-}
-
-func (h *Headers) Set(key string, values ...string) {
-	h.headers.Entry(key).AndModify(func(s *slice.Slice[string]) {
-		s.Clear()
-		for _, v := range values {
-			s.Push(v)
-		}
-	}).OrInsert(slice.Init(values...))
-}
-
-func (h *Headers) Get(key string) slice.Slice[string] {
-	entry := h.headers.Entry(key)
-	if entry.IsOccupied() {
-		return *entry.Value()
-	}
-	return slice.New[string]()
-}
-
-func (h *Headers) Iter() iter.Iter[hashmap.Pair[*string, *slice.Slice[string]]] {
-	return h.headers.Iter()
-}
-
 type Response[T Body] interface {
 	SetStatusCode(statusCode StatusCode)
 	StatusCode() StatusCode
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime"
+	stdhttp "net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marlaone/shepard/collections/slice"
+)
+
+const (
+	// ContentTypeText is the media type of text responses.
+	ContentTypeText = "text/plain; charset=utf-8"
+	// ContentTypeHTML is the media type of HTML responses.
+	ContentTypeHTML = "text/html; charset=utf-8"
+)
+
+// Text returns a response with status and text as plain text body.
+func Text(status StatusCode, text string) Response[Body] {
+	return bytesResponse(status, ContentTypeText, []byte(text))
+}
+
+// HTML returns a response with status and html as body.
+func HTML(status StatusCode, html string) Response[Body] {
+	return bytesResponse(status, ContentTypeHTML, []byte(html))
+}
+
+// Redirect returns a response redirecting the client to url with a 3xx status, e.g. StatusCodeFound or StatusCodeSeeOther.
+// A 500 problem response is returned for other statuses, or if url contains control characters.
+func Redirect(status StatusCode, url string) Response[Body] {
+	if status < 300 || status >= 400 {
+		return Error(StatusCodeInternalServerError, fmt.Errorf("[http.Redirect] invalid redirect status %d", status))
+	}
+	// control characters, CR and LF in particular, would allow injecting headers into the response
+	if strings.IndexFunc(url, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		return Error(StatusCodeInternalServerError, errors.New("[http.Redirect] invalid control character in redirect url"))
+	}
+	res := bytesResponse(status, "", nil)
+	res.SetHeader("Location", url)
+	return res
+}
+
+// NoContent returns a 204 response.
+func NoContent() Response[Body] {
+	return bytesResponse(StatusCodeNoContent, "", nil)
+}
+
+// File returns a 200 response with the content of the file at path.
+//
+// The Content-Type is derived from the extension of path, or sniffed from the content if the extension is unknown.
+// A 404 problem response is returned if the file doesn't exist or is a directory, a 403 one if it can't be read.
+// Other errors are logged to the standard logger and answered with a 500 problem response without details.
+func File(path string) Response[Body] {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		err = fs.ErrNotExist
+	}
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(path)
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Error(StatusCodeNotFound, nil)
+	case errors.Is(err, fs.ErrPermission):
+		return Error(StatusCodeForbidden, nil)
+	case err != nil:
+		log.Printf("[http.File] read file failed: %v", err)
+		return Error(StatusCodeInternalServerError, nil)
+	}
+
+	res := bytesResponse(StatusCodeOk, fileContentType(path, data), data)
+	res.SetHeader("Last-Modified", info.ModTime().UTC().Format(TimeFormat))
+	return res
+}
+
+// fileContentType returns the media type of the file name with content data.
+func fileContentType(name string, data []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return stdhttp.DetectContentType(data)
+}
+
+// bytesResponse returns a response with status and the closed body data, the Content-Type header is only set if contentType isn't empty.
+func bytesResponse(status StatusCode, contentType string, data []byte) Response[Body] {
+	builder := NewResponseBuilder(NewHttpResponseBytes()).Status(status)
+	if contentType != "" {
+		builder.Header("Content-Type", contentType)
+	}
+	res := builder.Body(NewBytesBody()).Unwrap()
+	if len(data) > 0 {
+		res.Body().Write(slice.Init(data...))
+	}
+	res.Body().Close()
+	return res
+}
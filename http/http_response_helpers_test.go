@@ -0,0 +1,139 @@
+package http
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseHelpers(t *testing.T) {
+	assert := assert.New(t)
+
+	res := JSON(StatusCodeCreated, map[string]int{"id": 1})
+	assert.Equal(StatusCodeCreated, res.StatusCode())
+	assert.Equal(slice.Init(ContentTypeJSON), res.Headers().Get("Content-Type"))
+	assert.Equal(`{"id":1}`, readBody(res))
+	assert.True(res.Body().Closed())
+
+	res = JsonResponse([]int{1, 2})
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.Equal(`[1,2]`, readBody(res))
+
+	res = JSON(StatusCodeOk, make(chan int))
+	assert.Equal(StatusCodeInternalServerError, res.StatusCode())
+	assert.Equal(slice.Init(ContentTypeProblemJSON), res.Headers().Get("Content-Type"))
+
+	res = Text(StatusCodeAccepted, "queued")
+	assert.Equal(StatusCodeAccepted, res.StatusCode())
+	assert.Equal(slice.Init(ContentTypeText), res.Headers().Get("Content-Type"))
+	assert.Equal("queued", readBody(res))
+
+	res = HTML(StatusCodeOk, "<p>hi</p>")
+	assert.Equal(slice.Init(ContentTypeHTML), res.Headers().Get("Content-Type"))
+	assert.Equal("<p>hi</p>", readBody(res))
+
+	res = Redirect(StatusCodeSeeOther, "/login?next=%2F")
+	assert.Equal(StatusCodeSeeOther, res.StatusCode())
+	assert.Equal(slice.Init("/login?next=%2F"), res.Headers().Get("Location"))
+	assert.False(res.Headers().Has("Content-Type"))
+	assert.Equal(StatusCodeInternalServerError, Redirect(StatusCodeOk, "/").StatusCode())
+	res = Redirect(StatusCodeFound, "/\r\nSet-Cookie: session=evil")
+	assert.Equal(StatusCodeInternalServerError, res.StatusCode())
+	assert.False(res.Headers().Has("Location"))
+
+	res = NoContent()
+	assert.Equal(StatusCodeNoContent, res.StatusCode())
+	assert.Equal("", readBody(res))
+}
+
+func TestError(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Error(StatusCodeNotFound, errors.New(`user "42" not found`))
+	assert.Equal(StatusCodeNotFound, res.StatusCode())
+	assert.Equal(slice.Init(ContentTypeProblemJSON), res.Headers().Get("Content-Type"))
+	assert.JSONEq(`{"type": "about:blank", "title": "Not Found", "status": 404, "detail": "user \"42\" not found"}`, readBody(res))
+
+	res = Error(StatusCodeForbidden, nil)
+	assert.JSONEq(`{"type": "about:blank", "title": "Forbidden", "status": 403}`, readBody(res))
+
+	res = ProblemResponse(Problem{Type: "https://example.com/probs/out-of-credit", Title: "You do not have enough credit.", Status: StatusCodeForbidden, Instance: "/account/12345"})
+	assert.Equal(StatusCodeForbidden, res.StatusCode())
+	assert.JSONEq(`{"type": "https://example.com/probs/out-of-credit", "title": "You do not have enough credit.", "status": 403, "instance": "/account/12345"}`, readBody(res))
+}
+
+func TestFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	modified := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	for name, content := range map[string]string{"index.html": "<html></html>", "data": "plain text", "empty.json": "{}"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(os.WriteFile(path, []byte(content), 0o644))
+		assert.NoError(os.Chtimes(path, modified, modified))
+	}
+
+	res := File(filepath.Join(dir, "index.html"))
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.Equal(slice.Init("text/html; charset=utf-8"), res.Headers().Get("Content-Type"))
+	assert.Equal(slice.Init("Mon, 01 May 2023 12:00:00 GMT"), res.Headers().Get("Last-Modified"))
+	assert.Equal("<html></html>", readBody(res))
+
+	// the content type is sniffed for unknown extensions
+	res = File(filepath.Join(dir, "data"))
+	assert.Equal(slice.Init("text/plain; charset=utf-8"), res.Headers().Get("Content-Type"))
+
+	assert.Equal(StatusCodeNotFound, File(filepath.Join(dir, "missing")).StatusCode())
+	assert.Equal(StatusCodeNotFound, File(dir).StatusCode())
+}
+
+func TestNegotiate(t *testing.T) {
+	type user struct {
+		ID    int     `json:"id" xml:"id,attr" csv:"id"`
+		Name  string  `json:"name" xml:"name" csv:"name"`
+		Email *string `json:"-" xml:"-" csv:"-"`
+	}
+	users := []user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob, jr."}}
+
+	testCases := []struct {
+		accept      string
+		v           any
+		status      StatusCode
+		contentType string
+		body        string
+	}{
+		{accept: "", v: users, status: StatusCodeOk, contentType: ContentTypeJSON, body: `[{"id":1,"name":"alice"},{"id":2,"name":"bob, jr."}]`},
+		{accept: "*/*", v: users, status: StatusCodeOk, contentType: ContentTypeJSON},
+		{accept: "text/csv", v: users, status: StatusCodeOk, contentType: ContentTypeCSV, body: "id,name\n1,alice\n2,\"bob, jr.\"\n"},
+		{accept: "text/*", v: users, status: StatusCodeOk, contentType: ContentTypeCSV},
+		{accept: "application/json;q=0.5, application/xml;q=0.9", v: users[0], status: StatusCodeOk, contentType: ContentTypeXML, body: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<user id="1"><name>alice</name></user>`},
+		{accept: "text/xml, */*;q=0.1", v: users[0], status: StatusCodeOk, contentType: ContentTypeXML},
+		{accept: "text/csv;q=1, application/json;q=1", v: users, status: StatusCodeOk, contentType: ContentTypeJSON},
+		// the most specific range decides: json is excluded
+		{accept: "application/json;q=0, */*", v: users, status: StatusCodeOk, contentType: ContentTypeXML},
+		{accept: "text/csv", v: [][]string{{"a", "b"}, {"1", "2"}}, status: StatusCodeOk, contentType: ContentTypeCSV, body: "a,b\n1,2\n"},
+		// csv isn't offered for values without columns
+		{accept: "text/csv", v: []int{1, 2}, status: StatusCodeNotAcceptable, contentType: ContentTypeProblemJSON},
+		{accept: "text/html", v: users, status: StatusCodeNotAcceptable, contentType: ContentTypeProblemJSON},
+		{accept: "application/json;q=abc", v: users, status: StatusCodeNotAcceptable, contentType: ContentTypeProblemJSON},
+	}
+
+	for _, tc := range testCases {
+		req := newTestRequest(MethodGet, "/users")
+		if tc.accept != "" {
+			req.Headers.Set("Accept", tc.accept)
+		}
+		res := Negotiate(req, tc.v)
+		assert.Equal(t, tc.status, res.StatusCode(), tc.accept)
+		assert.Equal(t, slice.Init(tc.contentType), res.Headers().Get("Content-Type"), tc.accept)
+		assert.Equal(t, slice.Init("Accept"), res.Headers().Get("Vary"), tc.accept)
+		if tc.body != "" {
+			assert.Equal(t, tc.body, readBody(res), tc.accept)
+		}
+	}
+}
@@ -2,35 +2,65 @@ package http
 
 import (
 	"encoding/json"
+)
 
-	"github.com/marlaone/shepard/collections/slice"
+const (
+	// ContentTypeJSON is the media type of JSON responses.
+	ContentTypeJSON = "application/json"
+	// ContentTypeProblemJSON is the media type of RFC 7807 problem details.
+	ContentTypeProblemJSON = "application/problem+json"
 )
 
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	// Type is a URI identifying the problem type, "about:blank" if the problem is described by the status code only.
+	Type string `json:"type"`
+	// Title is a short summary of the problem type.
+	Title string `json:"title"`
+	// Status is the status code of the response.
+	Status StatusCode `json:"status"`
+	// Detail is an explanation of this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI identifying this occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+}
+
+// JsonResponse returns a 200 response with body encoded as JSON.
 func JsonResponse(body any) Response[Body] {
+	return JSON(StatusCodeOk, body)
+}
 
-	builder := NewResponseBuilder(NewHttpResponseBytes())
-	potentialRes := builder.Header("Content-Type", "application/json").Status(StatusCodeOk).Body(NewBytesBody())
-	if potentialRes.IsErr() {
-		errRes := NewHttpResponseBytes()
-		errRes.SetStatusCode(StatusCodeInternalServerError)
-		errRes.SetBody(NewBytesBody())
-		errRes.Body().Write(slice.Init[byte]([]byte(potentialRes.Err().Unwrap().Error())...))
-		errRes.Finish()
-		return errRes
+// JSON returns a response with status and v encoded as JSON.
+//
+// A 500 problem response is returned if v can't be encoded.
+func JSON(status StatusCode, v any) Response[Body] {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Error(StatusCodeInternalServerError, err)
 	}
-	res := potentialRes.Unwrap()
+	return bytesResponse(status, ContentTypeJSON, data)
+}
 
-	bytes, err := json.Marshal(body)
+// Error returns a response with status and err rendered as RFC 7807 problem details.
+//
+// The detail is the message of err, leave err nil for problems described by the status code alone.
+func Error(status StatusCode, err error) Response[Body] {
+	problem := Problem{Type: "about:blank", Title: status.Reason(), Status: status}
 	if err != nil {
-		res.SetStatusCode(StatusCodeInternalServerError)
-		res.Body().Write(slice.Init[byte]([]byte(err.Error())...))
-		res.Finish()
-		return res
+		problem.Detail = err.Error()
 	}
+	return ProblemResponse(problem)
+}
 
-	res.SetStatusCode(StatusCodeOk)
-	res.Body().Write(slice.Init[byte](bytes...))
-	res.Finish()
-
-	return res
+// ProblemResponse returns a response rendering problem, its status is the status of problem.
+func ProblemResponse(problem Problem) Response[Body] {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = problem.Status.Reason()
+	}
+	// a problem consists of strings and a number only, it's always encodable
+	data, _ := json.Marshal(problem)
+	return bytesResponse(problem.Status, ContentTypeProblemJSON, data)
 }
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// ContentTypeXML is the media type of XML responses.
+	ContentTypeXML = "application/xml; charset=utf-8"
+	// ContentTypeCSV is the media type of CSV responses.
+	ContentTypeCSV = "text/csv; charset=utf-8"
+)
+
+// negotiableTypes are the media types Negotiate chooses from, in order of preference.
+// "text/xml" is an alias of "application/xml" and comes last, so "text/*" prefers CSV.
+var negotiableTypes = []string{"application/json", "application/xml", "text/csv", "text/xml"}
+
+// Negotiate returns a 200 response with v encoded as JSON, XML or CSV, whichever is preferred by the Accept header of req.
+//
+// JSON is chosen if req has no Accept header or the client accepts several formats equally.
+// CSV is only offered if v is a [][]string, a struct or a slice of structs, whose exported fields are the columns
+// named by their `csv` tag or their name.
+// A 406 problem response is returned if the client accepts none of the formats.
+func Negotiate(req *Request[RequestBody], v any) Response[Body] {
+	records, csvOk := csvRecords(v)
+	offers := make([]string, 0, len(negotiableTypes))
+	for _, offer := range negotiableTypes {
+		if offer != "text/csv" || csvOk {
+			offers = append(offers, offer)
+		}
+	}
+
+	var res Response[Body]
+	switch negotiate(req.Headers.Accept(), offers...) {
+	case "application/json":
+		res = JSON(StatusCodeOk, v)
+	case "application/xml", "text/xml":
+		data, err := xml.Marshal(v)
+		if err != nil {
+			res = Error(StatusCodeInternalServerError, fmt.Errorf("[http.Negotiate] encode xml failed: %w", err))
+			break
+		}
+		res = bytesResponse(StatusCodeOk, ContentTypeXML, append([]byte(xml.Header), data...))
+	case "text/csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.WriteAll(records)
+		if err := w.Error(); err != nil {
+			res = Error(StatusCodeInternalServerError, fmt.Errorf("[http.Negotiate] encode csv failed: %w", err))
+			break
+		}
+		res = bytesResponse(StatusCodeOk, ContentTypeCSV, buf.Bytes())
+	default:
+		res = Error(StatusCodeNotAcceptable, fmt.Errorf("supported media types: %s", strings.Join(offers, ", ")))
+	}
+	res.SetHeader("Vary", "Accept")
+	return res
+}
+
+// negotiate returns the offer with the highest quality in the ranges of an Accept header, or an empty string if none is acceptable.
+//
+// The quality of an offer is given by the most specific matching range, ties are won by the earlier offer.
+func negotiate(ranges []MediaRange, offers ...string) string {
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := matchMediaRange(r.Type, offer)
+			if s > specificity {
+				q, specificity = r.Q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// matchMediaRange returns how specific mediaRange matches mediaType: 2 for an exact match, 1 for "type/*", 0 for "*/*" and -1 if it doesn't match.
+func matchMediaRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
+
+// csvRecords returns the CSV records of v, a header and a row per struct, and false if v can't be represented as CSV.
+func csvRecords(v any) ([][]string, bool) {
+	if records, ok := v.([][]string); ok {
+		return records, true
+	}
+
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return nil, false
+	}
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	rows := []reflect.Value{value}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		rows = make([]reflect.Value, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			row := value.Index(i)
+			for row.Kind() == reflect.Pointer && !row.IsNil() {
+				row = row.Elem()
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	var rowType reflect.Type
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		rowType = value.Type().Elem()
+		for rowType.Kind() == reflect.Pointer {
+			rowType = rowType.Elem()
+		}
+	} else {
+		rowType = value.Type()
+	}
+	if rowType == nil || rowType.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	header := []string{}
+	fields := []int{}
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		name := field.Tag.Get("csv")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	records := [][]string{header}
+	for _, row := range rows {
+		if row.Kind() != reflect.Struct {
+			return nil, false
+		}
+		record := make([]string, len(fields))
+		for i, field := range fields {
+			record[i] = csvValue(row.Field(field))
+		}
+		records = append(records, record)
+	}
+	return records, true
+}
+
+// csvValue formats a field value for a CSV record.
+func csvValue(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return ""
+	}
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	return fmt.Sprint(value.Interface())
+}
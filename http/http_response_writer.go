@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+)
+
+// TimeFormat is the format of dates in HTTP headers, like the Date header. Times must be in UTC.
+const TimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// writeResponse writes res as the answer to req and returns whether the connection may be kept alive afterwards.
+//
+// Bodies which are closed or don't implement StreamingBody are sent at once, with a Content-Length unless the handler set one.
+// Streaming bodies are sent as they are flushed: with "Transfer-Encoding: chunked", with the Content-Length set by the handler,
+// or delimited by closing the connection for HTTP/1.0 clients. Streams of responses without a body are closed unsent.
+func writeResponse(w *bufio.Writer, req *Request[RequestBody], res Response[Body], keepAlive bool) (bool, error) {
+	headers := res.Headers()
+	head := req.Method == MethodHead
+	bodyAllowed := res.StatusCode().bodyAllowed()
+
+	stream, isStream := res.Body().(StreamingBody)
+	streaming := isStream && bodyAllowed && !head && !stream.Closed()
+	if isStream && !streaming {
+		// the stream isn't sent, closing it stops its producer
+		defer stream.Close()
+	}
+
+	var data []byte
+	chunked := false
+	switch {
+	case streaming:
+		if headers.Has("Content-Length") {
+			break
+		}
+		if req.Version == "1.0" {
+			keepAlive = false
+		} else {
+			chunked = true
+			headers.Set("Transfer-Encoding", "chunked")
+		}
+	case bodyAllowed:
+		data = ReadBody(res.Body())
+		// the body of a HEAD response is usually stripped already, the Content-Length should describe the GET response
+		if !headers.Has("Content-Length") && (!head || len(data) > 0) {
+			headers.Set("Content-Length", strconv.Itoa(len(data)))
+		}
+	}
+
+	if !keepAlive {
+		headers.Set("Connection", "close")
+	} else if req.Version == "1.0" {
+		headers.Set("Connection", "keep-alive")
+	}
+	if !headers.Has("Date") {
+		headers.Set("Date", time.Now().UTC().Format(TimeFormat))
+	}
+
+	writeHead(w, res)
+
+	if !streaming {
+		if bodyAllowed && !head {
+			w.Write(data)
+		}
+		return keepAlive, w.Flush()
+	}
+
+	for done := false; !done; {
+		select {
+		case <-stream.Flushed():
+		case <-stream.Done():
+			done = true
+		}
+		writeChunk(w, ReadBody(stream), chunked)
+		if err := w.Flush(); err != nil {
+			// the client is gone, closing the body releases what the producer holds
+			stream.Close()
+			return false, err
+		}
+	}
+	if chunked {
+		w.WriteString("0\r\n\r\n")
+	}
+	return keepAlive, w.Flush()
+}
+
+// headerNewlineToSpace replaces line breaks in header keys and values, which would end the header line early.
+var headerNewlineToSpace = strings.NewReplacer("\r", " ", "\n", " ")
+
+// writeHead writes the status line and the headers of res. Line breaks in the headers are replaced by spaces.
+func writeHead(w *bufio.Writer, res Response[Body]) {
+	w.WriteString("HTTP/" + res.Version().String() + " " + res.StatusCode().String() + " " + res.StatusCode().Reason() + "\r\n")
+	res.Headers().Iter().Foreach(func(_ int, value hashmap.Pair[*string, *slice.Slice[string]]) {
+		// cookies contain commas, each one is sent in its own header line
+		if *value.Key == "Set-Cookie" {
+			value.Value.Iter().Foreach(func(_ int, cookie string) {
+				w.WriteString(headerNewlineToSpace.Replace(*value.Key) + ": " + headerNewlineToSpace.Replace(cookie) + "\r\n")
+			})
+			return
+		}
+		headerValues := ""
+		value.Value.Iter().Foreach(func(i int, value string) {
+			if i > 0 {
+				headerValues += ", "
+			}
+			headerValues += value
+		})
+		w.WriteString(headerNewlineToSpace.Replace(*value.Key) + ": " + headerNewlineToSpace.Replace(headerValues) + "\r\n")
+	})
+	w.WriteString("\r\n")
+}
+
+// writeChunk writes data as a chunk of a chunked body, or unframed if chunked is false. Empty data is skipped,
+// as an empty chunk terminates the body.
+func writeChunk(w *bufio.Writer, data []byte, chunked bool) {
+	if len(data) == 0 {
+		return
+	}
+	if chunked {
+		w.WriteString(strconv.FormatInt(int64(len(data)), 16) + "\r\n")
+	}
+	w.Write(data)
+	if chunked {
+		w.WriteString("\r\n")
+	}
+}
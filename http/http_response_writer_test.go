@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestResponse writes res as the answer to req and returns the written bytes.
+func writeTestResponse(req *Request[RequestBody], res Response[Body], keepAlive bool) (string, bool) {
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	keepAlive, err := writeResponse(w, req, res, keepAlive)
+	if err != nil {
+		panic(err)
+	}
+	return out.String(), keepAlive
+}
+
+func TestWriteResponse_ContentLength(t *testing.T) {
+	assert := assert.New(t)
+
+	out, keepAlive := writeTestResponse(newTestRequest(MethodGet, "/"), textHandler("hello")(nil), true)
+	assert.True(keepAlive)
+	assert.True(strings.HasPrefix(out, "HTTP/1.1 200 OK\r\n"), out)
+	assert.Contains(out, "Content-Length: 5\r\n")
+	assert.Contains(out, "Date: ")
+	assert.True(strings.HasSuffix(out, "\r\n\r\nhello"), out)
+
+	// a Date set by the handler is kept
+	res := textHandler("")(nil)
+	res.SetHeader("Date", "Thu, 01 Jan 1970 00:00:00 GMT")
+	out, _ = writeTestResponse(newTestRequest(MethodGet, "/"), res, false)
+	assert.Contains(out, "Content-Length: 0\r\n")
+	assert.Contains(out, "Date: Thu, 01 Jan 1970 00:00:00 GMT\r\n")
+	assert.Contains(out, "Connection: close\r\n")
+
+	// unknown status codes have an empty reason phrase
+	res = textHandler("")(nil)
+	res.SetStatusCode(599)
+	out, _ = writeTestResponse(newTestRequest(MethodGet, "/"), res, true)
+	assert.True(strings.HasPrefix(out, "HTTP/1.1 599 \r\n"), out)
+
+	// line breaks can't inject headers
+	res = textHandler("")(nil)
+	res.SetHeader("X-Custom", "a\r\nSet-Cookie: session=evil")
+	res.SetHeader("Set-Cookie", "id=1\nX-Injected: 1")
+	out, _ = writeTestResponse(newTestRequest(MethodGet, "/"), res, true)
+	assert.Contains(out, "X-Custom: a  Set-Cookie: session=evil\r\n")
+	assert.Contains(out, "Set-Cookie: id=1 X-Injected: 1\r\n")
+	assert.NotContains(out, "\r\nSet-Cookie: session")
+	assert.NotContains(out, "\nX-Injected")
+}
+
+func TestWriteResponse_NoBody(t *testing.T) {
+	assert := assert.New(t)
+
+	res := textHandler("ignored")(nil)
+	res.SetStatusCode(StatusCodeNoContent)
+	out, _ := writeTestResponse(newTestRequest(MethodGet, "/"), res, true)
+	assert.True(strings.HasPrefix(out, "HTTP/1.1 204 No Content\r\n"), out)
+	assert.NotContains(out, "Content-Length")
+	assert.True(strings.HasSuffix(out, "\r\n\r\n"), out)
+
+	// HEAD responses keep the Content-Length of the GET response
+	r := NewRouter().Route(Get("/", textHandler("hello")))
+	out, _ = writeTestResponse(newTestRequest(MethodHead, "/"), r.Serve(newTestRequest(MethodHead, "/")).Unwrap(), true)
+	assert.Contains(out, "Content-Length: 5\r\n")
+	assert.True(strings.HasSuffix(out, "\r\n\r\n"), out)
+
+	// streams which aren't sent are closed
+	body := NewStreamBody()
+	res = NewResponseBuilder(NewHttpResponseBytes()).Status(StatusCodeNoContent).Body(body).Unwrap()
+	writeTestResponse(newTestRequest(MethodGet, "/"), res, true)
+	assert.True(body.Closed())
+
+	body = NewStreamBody()
+	res = NewResponseBuilder(NewHttpResponseBytes()).Body(body).Unwrap()
+	writeTestResponse(newTestRequest(MethodHead, "/"), res, true)
+	assert.True(body.Closed())
+}
+
+// streamHandler streams the parts as separate flushes.
+func streamHandler(parts ...string) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		body := NewStreamBody()
+		go func() {
+			for _, part := range parts {
+				body.Write(slice.Init([]byte(part)...))
+				body.Flush()
+				time.Sleep(time.Millisecond)
+			}
+			body.Close()
+		}()
+		return NewResponseBuilder(NewHttpResponseBytes()).Body(body).Unwrap()
+	}
+}
+
+func TestWriteResponse_Chunked(t *testing.T) {
+	assert := assert.New(t)
+
+	req := newTestRequest(MethodGet, "/")
+	out, keepAlive := writeTestResponse(req, streamHandler("hello", " ", "world")(req), true)
+	assert.True(keepAlive)
+	assert.Contains(out, "Transfer-Encoding: chunked\r\n")
+	assert.NotContains(out, "Content-Length")
+
+	r := bufio.NewReader(strings.NewReader(out[strings.Index(out, "\r\n\r\n")+4:]))
+	body, err := io.ReadAll(&chunkedBody{r: r, maxTrailerBytes: DefaultMaxHeaderBytes})
+	assert.NoError(err)
+	assert.Equal("hello world", string(body))
+	_, err = r.ReadByte()
+	assert.Equal(io.EOF, err)
+
+	// HTTP/1.0 clients don't support chunked encoding, the end of the body is signalled by closing the connection
+	req.Version = "1.0"
+	out, keepAlive = writeTestResponse(req, streamHandler("hello", " ", "world")(req), true)
+	assert.False(keepAlive)
+	assert.NotContains(out, "Transfer-Encoding")
+	assert.Contains(out, "Connection: close\r\n")
+	assert.True(strings.HasSuffix(out, "\r\n\r\nhello world"), out)
+
+	// with a Content-Length the body is streamed unframed
+	req.Version = "1.1"
+	res := streamHandler("hello")(req)
+	res.SetHeader("Content-Length", "5")
+	out, keepAlive = writeTestResponse(req, res, true)
+	assert.True(keepAlive)
+	assert.NotContains(out, "Transfer-Encoding")
+	assert.True(strings.HasSuffix(out, "\r\n\r\nhello"), out)
+}
+
+func TestServer_Streaming(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	s := &Server{Handler: NewRouter().Route(Get("/", func(req *Request[RequestBody]) Response[Body] {
+		body := NewStreamBody()
+		go func() {
+			body.Write(slice.Init([]byte("first")...))
+			body.Flush()
+			<-release
+			body.Write(slice.Init([]byte("second")...))
+			body.Close()
+		}()
+		return NewResponseBuilder(NewHttpResponseBytes()).Body(body).Unwrap()
+	}))}
+	client, server := net.Pipe()
+	defer client.Close()
+	go serveTestConn(s, server)
+	go client.Write([]byte("GET / HTTP/1.1\r\n\r\nGET / HTTP/1.1\r\nConnection: close\r\n\r\n"))
+
+	r := bufio.NewReader(client)
+	for i := 0; i < 2; i++ {
+		status, headers, _, err := readResponse(r)
+		assert.NoError(err)
+		assert.Equal("HTTP/1.1 200 OK", status)
+		assert.Equal("chunked", headers["Transfer-Encoding"])
+
+		// the first chunk arrives before the body is complete
+		body := &chunkedBody{r: r, maxTrailerBytes: DefaultMaxHeaderBytes}
+		first := make([]byte, 5)
+		_, err = io.ReadFull(body, first)
+		assert.NoError(err)
+		assert.Equal("first", string(first))
+
+		release <- struct{}{}
+		rest, err := io.ReadAll(body)
+		assert.NoError(err)
+		assert.Equal("second", string(rest))
+	}
+
+	_, err := r.ReadByte()
+	assert.Equal(io.EOF, err)
+}
@@ -1,72 +1,218 @@
 package http
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+
 	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
 	"github.com/marlaone/shepard/collections/slice"
 )
 
 type Router struct {
 	routes slice.Slice[Route]
+	tree   *node
 
 	middleware slice.Slice[Middleware]
 
-	notFoundHandler Handler
+	notFoundHandler         Handler
+	methodNotAllowedHandler Handler
+
+	// logger logs errors of ServeHTTP, log.Default() if nil
+	logger Logger
+
+	// root is the Router owning the tree, r itself if r was created by NewRouter
+	root *Router
+	// parent is the Router a group was created on, nil for the root
+	parent *Router
+	// prefix of all patterns registered on a group
+	prefix string
+
+	// names maps route names to their patterns
+	names hashmap.HashMap[string, string]
+	// mounts are the routers mounted on the root
+	mounts slice.Slice[mount]
 }
 
 func NewRouter() *Router {
-	return &Router{
+	r := &Router{
 		routes:     slice.New[Route](),
+		tree:       newNode(""),
 		middleware: slice.New[Middleware](),
 		notFoundHandler: func(req *Request[RequestBody]) Response[Body] {
 			res := NewResponseBuilder(NewHttpResponseBytes()).Status(404).Body(NewBytesBody()).Unwrap()
 			res.Body().Close()
 			return res
 		},
+		methodNotAllowedHandler: func(req *Request[RequestBody]) Response[Body] {
+			res := NewResponseBuilder(NewHttpResponseBytes()).Status(405).Body(NewBytesBody()).Unwrap()
+			res.Body().Close()
+			return res
+		},
+		names:  hashmap.New[string, string](),
+		mounts: slice.New[mount](),
 	}
+	r.root = r
+	return r
 }
 
+// Use adds middleware to the Router. The middleware of a group is only invoked for the routes registered on the group.
 func (r *Router) Use(middleware Middleware) *Router {
 	r.middleware.Push(middleware)
 	return r
 }
 
+// Route registers route. Patterns may contain named parameters matching a single path segment, e.g. "/users/:id",
+// and a trailing catch-all matching the rest of the path, e.g. "/files/*path".
+// The values are available via Request.Param.
+//
+// Static segments take precedence over parameters, which take precedence over catch-alls.
+//
+// Panics if the pattern is invalid, the method and pattern are already registered or the name of the route is already taken.
 func (r *Router) Route(route Route) *Router {
-	r.routes.Push(route)
+	route.Pattern = r.prefix + route.Pattern
+	if r != r.root {
+		route.group = r
+	}
+
+	r.root.tree.insert(route)
+	r.root.routes.Push(route)
+
+	if route.Name != "" {
+		if r.root.names.ContainsKey(route.Name) {
+			panic(fmt.Sprintf("http: route name %q is already registered", route.Name))
+		}
+		r.root.names.Insert(route.Name, route.Pattern)
+	}
 	return r
 }
 
+// NotFound sets the handler for requests no route matches.
 func (r *Router) NotFound(handler Handler) {
-	r.notFoundHandler = handler
+	r.root.notFoundHandler = handler
+}
+
+// MethodNotAllowed sets the handler for requests whose path matches a route but not its method.
+// The Allow header of its response is set by the Router.
+func (r *Router) MethodNotAllowed(handler Handler) {
+	r.root.methodNotAllowedHandler = handler
 }
 
+// Logger sets the logger for errors of serving requests with ServeHTTP, log.Default() by default.
+func (r *Router) Logger(logger Logger) {
+	r.root.logger = logger
+}
+
+// Serve dispatches req to the handler of the matching route.
+//
+// HEAD requests are answered by the GET handler of a path without a body, if no HEAD route is registered for it.
+// OPTIONS requests are answered with the allowed methods in the Allow header, if no OPTIONS route is registered for it.
 func (r *Router) Serve(req *Request[RequestBody]) shepard.Result[Response[Body], error] {
+	return r.root.serve(req, "")
+}
+
+// serve dispatches req by path, which is the request path relative to the mount point of r.
+// An empty path dispatches by the request path after the middleware of r ran, so it can rewrite the path.
+func (r *Router) serve(req *Request[RequestBody], path string) shepard.Result[Response[Body], error] {
 	return invokeMiddlewares(r.middleware, req, func() shepard.Result[Response[Body], error] {
-		route := r.routes.Iter().Find(func(val *Route) bool {
-			// TODO find route by pattern
-			return val.Pattern == req.URL.Path
-		})
-		if route.IsNone() {
+		if path == "" {
+			path = req.URL.Path
+		}
+		params := slice.New[pathParam]()
+		n := r.tree.match(path, &params)
+		if n == nil {
 			return shepard.Ok[Response[Body], error](r.notFoundHandler(req))
 		}
-		if route.Unwrap().Method != req.Method {
-			res := NewResponseBuilder(NewHttpResponseBytes()).Status(405).Body(NewBytesBody()).Unwrap()
+
+		req.pathParams = hashmap.WithCapacity[string, string](params.Len())
+		params.Iter().Foreach(func(_ int, p pathParam) {
+			req.pathParams.Insert(p.name, p.value)
+		})
+
+		route := n.routes.Get(req.Method)
+		if route.IsSome() {
+			return route.Unwrap().dispatch(req)
+		}
+
+		switch req.Method {
+		case MethodHead:
+			if get := n.routes.Get(MethodGet); get.IsSome() {
+				res := get.Unwrap().dispatch(req)
+				if res.IsOk() {
+					getRes := res.Unwrap()
+					// the length of a streamed body isn't known without producing it
+					if _, streaming := getRes.Body().(StreamingBody); (!streaming || getRes.Body().Closed()) && !getRes.Headers().Has("Content-Length") {
+						getRes.SetHeader("Content-Length", strconv.Itoa(len(ReadBody(getRes.Body()))))
+					}
+					// the body of the GET response isn't sent, closing it stops a producer of a streaming body
+					getRes.Body().Close()
+					body := NewBytesBody()
+					body.Close()
+					res.Unwrap().SetBody(body)
+				}
+				return res
+			}
+		case MethodOptions:
+			res := NewResponseBuilder(NewHttpResponseBytes()).Status(StatusCodeNoContent).Header("Allow", allowedMethods(n)...).Body(NewBytesBody()).Unwrap()
 			res.Body().Close()
 			return shepard.Ok[Response[Body], error](res)
 		}
-		return shepard.Ok[Response[Body], error](route.Unwrap().Handler(req))
+
+		res := r.methodNotAllowedHandler(req)
+		res.SetHeader("Allow", allowedMethods(n)...)
+		return shepard.Ok[Response[Body], error](res)
 	})
 }
 
-func invokeMiddlewares(middleware slice.Slice[Middleware], req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
-	mwIter := middleware.Iter()
-	nextMW := mwIter.Next()
-	for nextMW.IsSome() {
-		mw := nextMW.Unwrap()
-		nextMW = mwIter.Next()
-		res := mw(req, next)
-		if res.IsErr() {
-			return res
+// dispatch invokes the handler of the route wrapped by the middleware of its groups and of the route itself.
+func (route *Route) dispatch(req *Request[RequestBody]) shepard.Result[Response[Body], error] {
+	middleware := slice.New[Middleware]()
+	for g := route.group; g != nil && g.parent != nil; g = g.parent {
+		groupMiddleware := g.middleware.Clone()
+		groupMiddleware.Append(&middleware)
+		middleware = groupMiddleware
+	}
+	// Append moves the elements, the middleware of the route is kept for further requests
+	routeMiddleware := route.Middleware.Clone()
+	middleware.Append(&routeMiddleware)
+
+	return invokeMiddlewares(middleware, req, func() shepard.Result[Response[Body], error] {
+		if route.mount != nil {
+			return route.mount.serve(req, "/"+req.Param(mountParam).UnwrapOr(""))
 		}
+		return shepard.Ok[Response[Body], error](route.Handler(req))
+	})
+}
+
+// allowedMethods returns the methods a request may use for the routes of n, including the automatically handled HEAD and OPTIONS.
+func allowedMethods(n *node) []string {
+	methods := make([]string, 0, n.routes.Len()+2)
+	n.routes.Keys().Foreach(func(_ int, m Method) {
+		methods = append(methods, m.String())
+	})
+	if n.routes.ContainsKey(MethodGet) && !n.routes.ContainsKey(MethodHead) {
+		methods = append(methods, MethodHead.String())
+	}
+	if !n.routes.ContainsKey(MethodOptions) {
+		methods = append(methods, MethodOptions.String())
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// invokeMiddlewares invokes middleware as an onion around handler: the first middleware is invoked first,
+// and the next of every middleware invokes the following middleware, the next of the last one invokes handler.
+func invokeMiddlewares(middleware slice.Slice[Middleware], req *Request[RequestBody], handler Next) shepard.Result[Response[Body], error] {
+	var invoke func(i int) shepard.Result[Response[Body], error]
+	invoke = func(i int) shepard.Result[Response[Body], error] {
+		mw := middleware.Get(i)
+		if mw.IsNone() {
+			return handler()
+		}
+		return (*mw.Unwrap())(req, func() shepard.Result[Response[Body], error] {
+			return invoke(i + 1)
+		})
 	}
-	return next()
+	return invoke(0)
 }
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mountParam is the name of the catch-all parameter holding the path below the prefix of a mounted Router.
+const mountParam = "mountpath"
+
+type mount struct {
+	prefix string
+	router *Router
+}
+
+// Group returns a Router registering its routes below prefix on r.
+//
+// The given middleware and middleware added to the group later are only invoked for routes of the group,
+// after the middleware of r.
+func (r *Router) Group(prefix string, middleware ...Middleware) *Router {
+	g := &Router{
+		root:   r.root,
+		parent: r,
+		prefix: r.prefix + strings.TrimSuffix(prefix, "/"),
+	}
+	for _, mw := range middleware {
+		g.Use(mw)
+	}
+	return g
+}
+
+// Mount dispatches all requests for prefix and the paths below it to sub, e.g. "/admin/users" to the route "/users" of sub
+// if sub is mounted on "/admin".
+//
+// sub keeps its own middleware and not found handler. Named routes of sub can be resolved via Router.URL of r.
+func (r *Router) Mount(prefix string, sub *Router) *Router {
+	prefix = r.prefix + strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		panic(fmt.Sprintf("http: can't mount router on %q", "/"))
+	}
+
+	for _, method := range methods {
+		for _, pattern := range []string{prefix, prefix + "/*" + mountParam} {
+			route := Route{Method: method, Pattern: pattern, mount: sub.root}
+			if r != r.root {
+				route.group = r
+			}
+			r.root.tree.insert(route)
+		}
+	}
+	r.root.mounts.Push(mount{prefix: prefix, router: sub.root})
+	return r
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// flag returns a middleware setting the request header name.
+func flag(name string) Middleware {
+	return func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		req.Headers.Set(name, "1")
+		return next()
+	}
+}
+
+func requireToken(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+	if !req.Headers.Has("Token") {
+		return shepard.Err[Response[Body], error](errUnauthorized)
+	}
+	return next()
+}
+
+func flagsHandler(req *Request[RequestBody]) Response[Body] {
+	text := ""
+	for _, name := range []string{"X-Global", "X-Group", "X-Nested", "X-Route"} {
+		if req.Headers.Has(name) {
+			text += name + ";"
+		}
+	}
+	return textHandler(text)(req)
+}
+
+func TestRouter_Group(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter().Use(flag("X-Global"))
+	r.Route(Get("/", flagsHandler))
+
+	api := r.Group("/api/v1/", flag("X-Group"))
+	api.Route(Get("/users", flagsHandler))
+	api.Route(Get("/users/:id", flagsHandler).Use(flag("X-Route")))
+
+	admin := api.Group("/admin", requireToken)
+	admin.Use(flag("X-Nested"))
+	admin.Route(Delete("/users/:id", paramsHandler("id")))
+
+	assert.Equal("X-Global;", readBody(r.Serve(newTestRequest(MethodGet, "/")).Unwrap()))
+	assert.Equal("X-Global;X-Group;", readBody(r.Serve(newTestRequest(MethodGet, "/api/v1/users")).Unwrap()))
+	assert.Equal("X-Global;X-Group;X-Route;", readBody(r.Serve(newTestRequest(MethodGet, "/api/v1/users/1")).Unwrap()))
+	assert.Equal(StatusCodeNotFound, r.Serve(newTestRequest(MethodGet, "/users")).Unwrap().StatusCode())
+
+	assert.ErrorIs(r.Serve(newTestRequest(MethodDelete, "/api/v1/admin/users/1")).UnwrapErr(), errUnauthorized)
+
+	req := newTestRequest(MethodDelete, "/api/v1/admin/users/1")
+	req.Headers.Set("Token", "secret")
+	assert.Equal("id=1;", readBody(r.Serve(req).Unwrap()))
+	assert.True(req.Headers.Has("X-Nested"))
+
+	// serving through a group serves the whole router
+	assert.Equal("X-Global;", readBody(api.Serve(newTestRequest(MethodGet, "/")).Unwrap()))
+}
+
+func TestRouter_Mount(t *testing.T) {
+	assert := assert.New(t)
+
+	admin := NewRouter().Use(requireToken)
+	admin.Route(Get("/", textHandler("dashboard")))
+	admin.Route(Get("/users/:id", paramsHandler("id")).Named("admin.user"))
+	admin.NotFound(textHandler("admin not found"))
+
+	r := NewRouter()
+	r.Route(Get("/users/:id", paramsHandler("id")))
+	r.Mount("/admin", admin)
+
+	serve := func(method Method, path string) Response[Body] {
+		req := newTestRequest(method, path)
+		req.Headers.Set("Token", "secret")
+		return r.Serve(req).Unwrap()
+	}
+
+	assert.Equal("dashboard", readBody(serve(MethodGet, "/admin")))
+	assert.Equal("dashboard", readBody(serve(MethodGet, "/admin/")))
+	assert.Equal("id=7;", readBody(serve(MethodGet, "/admin/users/7")))
+	assert.Equal("admin not found", readBody(serve(MethodGet, "/admin/unknown")))
+	assert.Equal("id=7;", readBody(serve(MethodGet, "/users/7")))
+
+	res := serve(MethodPost, "/admin/users/7")
+	assert.Equal(StatusCodeMethodNotAllowed, res.StatusCode())
+
+	assert.ErrorIs(r.Serve(newTestRequest(MethodGet, "/admin")).UnwrapErr(), errUnauthorized)
+
+	// routes added after mounting are served too
+	admin.Route(Get("/settings", textHandler("settings")))
+	assert.Equal("settings", readBody(serve(MethodGet, "/admin/settings")))
+
+	assert.Equal(shepard.Ok[string, error]("/admin/users/7"), r.URL("admin.user", map[string]string{"id": "7"}))
+}
+
+func TestRouter_URL(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/users/:id", textHandler("")).Named("user.show"))
+	r.Route(Get("/files/*path", textHandler("")).Named("files"))
+	r.Group("/api").Route(Get("/posts/:post/comments/:comment", textHandler("")).Named("api.comment"))
+
+	assert.Equal(shepard.Ok[string, error]("/users/42"), r.URL("user.show", map[string]string{"id": "42"}))
+	assert.Equal(shepard.Ok[string, error]("/users/a%20b"), r.URL("user.show", map[string]string{"id": "a b"}))
+	assert.Equal(shepard.Ok[string, error]("/files/docs/read%20me.md"), r.URL("files", map[string]string{"path": "docs/read me.md"}))
+	assert.Equal(shepard.Ok[string, error]("/api/posts/1/comments/2"), r.URL("api.comment", map[string]string{"post": "1", "comment": "2"}))
+
+	assert.True(r.URL("user.show", nil).IsErr())
+	assert.True(r.URL("user.show", map[string]string{"id": ""}).IsErr())
+	assert.True(r.URL("unknown", nil).IsErr())
+
+	assert.Panics(func() {
+		r.Route(Post("/users", textHandler("")).Named("user.show"))
+	})
+}
+
+func TestRoute_Use(t *testing.T) {
+	base := Get("/", flagsHandler).Use(flag("X-Route"))
+	extended := base.Use(flag("X-Group"))
+
+	assert.Equal(t, 1, base.Middleware.Len())
+	assert.Equal(t, 2, extended.Middleware.Len())
+}
@@ -1,11 +1,38 @@
 package http
 
+import "github.com/marlaone/shepard/collections/slice"
+
 type Handler func(req *Request[RequestBody]) Response[Body]
 
 type Route struct {
 	Method  Method
 	Pattern string
 	Handler Handler
+
+	// Name identifies the route for reverse URL generation with Router.URL.
+	Name string
+	// Middleware is invoked for this route only, after the middleware of the Router and its groups.
+	Middleware slice.Slice[Middleware]
+
+	// group is the Router the route was registered on
+	group *Router
+	// mount is the Router requests matching the route are dispatched to
+	mount *Router
+}
+
+// Named returns a copy of the route with the given name.
+func (r Route) Named(name string) Route {
+	r.Name = name
+	return r
+}
+
+// Use returns a copy of the route which additionally invokes the given middleware.
+func (r Route) Use(middleware ...Middleware) Route {
+	r.Middleware = r.Middleware.Clone()
+	for _, mw := range middleware {
+		r.Middleware.Push(mw)
+	}
+	return r
 }
 
 func Get(pattern string, handler Handler) Route {
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRequest(method Method, uri string) *Request[RequestBody] {
+	req := NewRequestBuilder[RequestBody]().Method(method).URL(ParseRequestURI(uri).Unwrap()).Body(noBody{}).Unwrap()
+	return &req
+}
+
+func textHandler(text string) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		res := NewResponseBuilder(NewHttpResponseBytes()).Status(StatusCodeOk).Body(NewBytesBody()).Unwrap()
+		res.Body().Write(slice.Init([]byte(text)...))
+		res.Body().Close()
+		return res
+	}
+}
+
+func paramsHandler(names ...string) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		text := ""
+		for _, name := range names {
+			text += name + "=" + req.Param(name).UnwrapOr("<none>") + ";"
+		}
+		return textHandler(text)(req)
+	}
+}
+
+func readBody(res Response[Body]) string {
+	return string(ReadBody(res.Body()))
+}
+
+func TestRouter_Serve_Params(t *testing.T) {
+	r := NewRouter().
+		Route(Get("/", textHandler("index"))).
+		Route(Get("/users", textHandler("users"))).
+		Route(Get("/users/new", textHandler("new user"))).
+		Route(Get("/users/:id", paramsHandler("id"))).
+		Route(Get("/users/:id/posts/:post", paramsHandler("id", "post"))).
+		Route(Get("/files/*path", paramsHandler("path"))).
+		Route(Get("/static/*path", paramsHandler("path"))).
+		Route(Get("/static/favicon.ico", textHandler("favicon")))
+
+	testCases := []struct {
+		path     string
+		status   StatusCode
+		expected string
+	}{
+		{path: "/", status: 200, expected: "index"},
+		{path: "/users", status: 200, expected: "users"},
+		{path: "/users/new", status: 200, expected: "new user"},
+		{path: "/users/42", status: 200, expected: "id=42;"},
+		{path: "/users/newer", status: 200, expected: "id=newer;"},
+		{path: "/users/42/posts/7", status: 200, expected: "id=42;post=7;"},
+		{path: "/files/", status: 200, expected: "path=;"},
+		{path: "/files/a/b.txt", status: 200, expected: "path=a/b.txt;"},
+		{path: "/static/favicon.ico", status: 200, expected: "favicon"},
+		{path: "/static/app.js", status: 200, expected: "path=app.js;"},
+		{path: "/users/", status: 404},
+		{path: "/users/42/posts", status: 404},
+		{path: "/files", status: 404},
+		{path: "/unknown", status: 404},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			res := r.Serve(newTestRequest(MethodGet, tc.path)).Unwrap()
+			assert.Equal(t, tc.status, res.StatusCode())
+			if tc.status == 200 {
+				assert.Equal(t, tc.expected, readBody(res))
+			}
+		})
+	}
+}
+
+func TestRouter_Serve_Methods(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter().
+		Route(Get("/users/:id", textHandler("get"))).
+		Route(Put("/users/:id", textHandler("put"))).
+		Route(Delete("/users/:id", textHandler("delete"))).
+		Route(Post("/users", textHandler("post")))
+
+	assert.Equal("put", readBody(r.Serve(newTestRequest(MethodPut, "/users/1")).Unwrap()))
+	assert.Equal("delete", readBody(r.Serve(newTestRequest(MethodDelete, "/users/1")).Unwrap()))
+	assert.Equal("post", readBody(r.Serve(newTestRequest(MethodPost, "/users")).Unwrap()))
+
+	res := r.Serve(newTestRequest(MethodPatch, "/users/1")).Unwrap()
+	assert.Equal(StatusCodeMethodNotAllowed, res.StatusCode())
+	assert.Equal(slice.Init("DELETE", "GET", "HEAD", "OPTIONS", "PUT"), res.Headers().Get("Allow"))
+
+	res = r.Serve(newTestRequest(MethodGet, "/users")).Unwrap()
+	assert.Equal(StatusCodeMethodNotAllowed, res.StatusCode())
+	assert.Equal(slice.Init("OPTIONS", "POST"), res.Headers().Get("Allow"))
+
+	r.MethodNotAllowed(textHandler("custom"))
+	res = r.Serve(newTestRequest(MethodPatch, "/users/1")).Unwrap()
+	assert.Equal("custom", readBody(res))
+	assert.Equal(slice.Init("DELETE", "GET", "HEAD", "OPTIONS", "PUT"), res.Headers().Get("Allow"))
+}
+
+func TestRouter_Serve_HeadAndOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter().
+		Route(Get("/auto", func(req *Request[RequestBody]) Response[Body] {
+			res := textHandler("body")(req)
+			res.SetHeader("Content-Length", "4")
+			return res
+		})).
+		Route(Head("/manual", textHandler("head"))).
+		Route(Options("/manual", textHandler("options")))
+
+	res := r.Serve(newTestRequest(MethodHead, "/auto")).Unwrap()
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	assert.Equal(slice.Init("4"), res.Headers().Get("Content-Length"))
+	assert.Equal("", readBody(res))
+
+	res = r.Serve(newTestRequest(MethodOptions, "/auto")).Unwrap()
+	assert.Equal(StatusCodeNoContent, res.StatusCode())
+	assert.Equal(slice.Init("GET", "HEAD", "OPTIONS"), res.Headers().Get("Allow"))
+
+	assert.Equal("head", readBody(r.Serve(newTestRequest(MethodHead, "/manual")).Unwrap()))
+	assert.Equal("options", readBody(r.Serve(newTestRequest(MethodOptions, "/manual")).Unwrap()))
+
+	// the stream of a GET route is closed by the automatic HEAD response
+	stream := NewStreamBody()
+	r.Route(Get("/stream", func(req *Request[RequestBody]) Response[Body] {
+		return NewResponseBuilder(NewHttpResponseBytes()).Body(stream).Unwrap()
+	}))
+	res = r.Serve(newTestRequest(MethodHead, "/stream")).Unwrap()
+	assert.True(stream.Closed())
+	assert.False(res.Headers().Has("Content-Length"))
+}
+
+func TestRouter_Route_Panics(t *testing.T) {
+	testCases := map[string][]Route{
+		"missing slash":        {Get("users", textHandler(""))},
+		"empty parameter":      {Get("/users/:", textHandler(""))},
+		"inline parameter":     {Get("/users:id", textHandler(""))},
+		"catch-all not last":   {Get("/files/*path/edit", textHandler(""))},
+		"duplicate route":      {Get("/users/:id", textHandler("")), Get("/users/:id", textHandler(""))},
+		"conflicting wildcard": {Get("/users/:id", textHandler("")), Get("/users/:name/posts", textHandler(""))},
+	}
+	for name, routes := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Panics(t, func() {
+				r := NewRouter()
+				for _, route := range routes {
+					r.Route(route)
+				}
+			})
+		})
+	}
+}
+
+func TestRequest_Param(t *testing.T) {
+	req := newTestRequest(MethodGet, "/")
+	assert.Equal(t, shepard.None[string](), req.Param("id"))
+}
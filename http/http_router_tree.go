@@ -0,0 +1,194 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+)
+
+// node is a node of the radix tree the Router matches paths with.
+//
+// Static path parts are stored as compressed prefixes on the edges, parameter and catch-all segments as dedicated children.
+// While matching, static children take precedence over parameters which take precedence over catch-alls.
+type node struct {
+	// prefix is the static part of the path matched by this node, empty for parameter and catch-all nodes
+	prefix string
+	// name of the parameter matched by a parameter or catch-all node
+	name string
+
+	children []*node
+	param    *node
+	catchAll *node
+
+	// pattern of the routes registered on this node
+	pattern string
+	routes  hashmap.HashMap[Method, Route]
+}
+
+type pathParam struct {
+	name  string
+	value string
+}
+
+func newNode(prefix string) *node {
+	return &node{
+		prefix: prefix,
+		routes: hashmap.New[Method, Route](),
+	}
+}
+
+// insert registers route for its pattern below n.
+//
+// Panics if the pattern is invalid, if a route with the same method and pattern already exists
+// or if the pattern uses a different parameter name than an existing pattern at the same position.
+func (n *node) insert(route Route) {
+	pattern := route.Pattern
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("http: pattern %q must begin with '/'", pattern))
+	}
+
+	current := n
+	rest := pattern
+	for rest != "" {
+		i := strings.IndexAny(rest, ":*")
+		if i < 0 {
+			current = current.insertStatic(rest)
+			break
+		}
+		if i == 0 || rest[i-1] != '/' {
+			panic(fmt.Sprintf("http: wildcard in pattern %q must follow a '/'", pattern))
+		}
+		current = current.insertStatic(rest[:i])
+
+		end := strings.IndexByte(rest[i:], '/')
+		if end < 0 {
+			end = len(rest) - i
+		}
+		name := rest[i+1 : i+end]
+		if name == "" || strings.ContainsAny(name, ":*") {
+			panic(fmt.Sprintf("http: invalid wildcard %q in pattern %q", rest[i:i+end], pattern))
+		}
+
+		if rest[i] == '*' {
+			if i+end != len(rest) {
+				panic(fmt.Sprintf("http: catch-all must be the last segment of pattern %q", pattern))
+			}
+			if current.catchAll == nil {
+				current.catchAll = newNode("")
+				current.catchAll.name = name
+			}
+			current = current.catchAll
+		} else {
+			if current.param == nil {
+				current.param = newNode("")
+				current.param.name = name
+			}
+			current = current.param
+		}
+		if current.name != name {
+			panic(fmt.Sprintf("http: wildcard %q in pattern %q conflicts with existing wildcard %q", name, pattern, current.name))
+		}
+
+		rest = rest[i+end:]
+	}
+
+	if current.routes.ContainsKey(route.Method) {
+		panic(fmt.Sprintf("http: route %s %s is already registered", route.Method, pattern))
+	}
+	current.pattern = pattern
+	current.routes.Insert(route.Method, route)
+}
+
+// insertStatic inserts the static path s below n, splitting existing edges where needed, and returns the node matching s.
+func (n *node) insertStatic(s string) *node {
+	current := n
+	for s != "" {
+		var child *node
+		for _, c := range current.children {
+			if c.prefix[0] == s[0] {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			child = newNode(s)
+			current.children = append(current.children, child)
+			return child
+		}
+
+		l := commonPrefixLen(child.prefix, s)
+		if l < len(child.prefix) {
+			// split the edge at the end of the common prefix
+			split := newNode(child.prefix[:l])
+			child.prefix = child.prefix[l:]
+			split.children = []*node{child}
+			for i, c := range current.children {
+				if c == child {
+					current.children[i] = split
+				}
+			}
+			child = split
+		}
+		current = child
+		s = s[l:]
+	}
+	return current
+}
+
+// match returns the node below n with routes matching path, path being the part of the request path not matched by n yet.
+// Values of matched parameters are pushed to params.
+func (n *node) match(path string, params *slice.Slice[pathParam]) *node {
+	if path == "" {
+		if !n.routes.IsEmpty() {
+			return n
+		}
+		if n.catchAll != nil && !n.catchAll.routes.IsEmpty() {
+			params.Push(pathParam{name: n.catchAll.name})
+			return n.catchAll
+		}
+		return nil
+	}
+
+	for _, c := range n.children {
+		if c.prefix[0] != path[0] {
+			continue
+		}
+		if strings.HasPrefix(path, c.prefix) {
+			if found := c.match(path[len(c.prefix):], params); found != nil {
+				return found
+			}
+		}
+		break
+	}
+
+	if n.param != nil {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			params.Push(pathParam{name: n.param.name, value: path[:end]})
+			if found := n.param.match(path[end:], params); found != nil {
+				return found
+			}
+			params.Pop()
+		}
+	}
+
+	if n.catchAll != nil && !n.catchAll.routes.IsEmpty() {
+		params.Push(pathParam{name: n.catchAll.name, value: path})
+		return n.catchAll
+	}
+
+	return nil
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
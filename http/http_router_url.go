@@ -0,0 +1,70 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/marlaone/shepard"
+)
+
+// URL builds the path of the route registered with name, filling in its parameters from params.
+//
+// Parameter values are escaped, the value of a catch-all may contain slashes.
+// Routes of mounted routers are resolved below their mount point.
+func (r *Router) URL(name string, params map[string]string) shepard.Result[string, error] {
+	root := r.root
+	pattern := root.names.Get(name)
+	if pattern.IsSome() {
+		return buildPath(*pattern.Unwrap(), params)
+	}
+
+	mounted := root.mounts.Iter()
+	for m := mounted.Next(); m.IsSome(); m = mounted.Next() {
+		path := m.Unwrap().router.URL(name, params)
+		if path.IsOk() {
+			return shepard.Ok[string, error](m.Unwrap().prefix + path.Unwrap())
+		}
+	}
+
+	return shepard.Err[string, error](fmt.Errorf("[http.Router.URL] unknown route %q", name))
+}
+
+func buildPath(pattern string, params map[string]string) shepard.Result[string, error] {
+	var path strings.Builder
+	rest := pattern
+	for rest != "" {
+		i := strings.IndexAny(rest, ":*")
+		if i < 0 {
+			path.WriteString(rest)
+			break
+		}
+		path.WriteString(rest[:i])
+
+		end := strings.IndexByte(rest[i:], '/')
+		if end < 0 {
+			end = len(rest) - i
+		}
+		name := rest[i+1 : i+end]
+		value, ok := params[name]
+		if !ok {
+			return shepard.Err[string, error](fmt.Errorf("[http.Router.URL] missing parameter %q for pattern %q", name, pattern))
+		}
+
+		if rest[i] == '*' {
+			segments := strings.Split(value, "/")
+			for j, segment := range segments {
+				segments[j] = url.PathEscape(segment)
+			}
+			path.WriteString(strings.Join(segments, "/"))
+		} else {
+			if value == "" {
+				return shepard.Err[string, error](fmt.Errorf("[http.Router.URL] empty parameter %q for pattern %q", name, pattern))
+			}
+			path.WriteString(url.PathEscape(value))
+		}
+
+		rest = rest[i+end:]
+	}
+	return shepard.Ok[string, error](path.String())
+}
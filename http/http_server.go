@@ -0,0 +1,445 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrServerClosed is returned by the Serve and ListenAndServe methods of a Server after Server.Shutdown was called.
+var ErrServerClosed = errors.New("http: server closed")
+
+const (
+	// shutdownPollInterval is the interval in which Server.Shutdown checks for connections which became idle
+	shutdownPollInterval = 10 * time.Millisecond
+	// maxAcceptBackoff is the maximal delay between retries of a temporarily failing Accept
+	maxAcceptBackoff = time.Second
+	// maxDiscardBodyBytes is the maximal size of an unread request body which is discarded to keep the connection alive
+	maxDiscardBodyBytes = 256 << 10
+)
+
+// Logger receives errors and messages of a Server. *log.Logger implements it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type connState int
+
+const (
+	// connStateIdle is a connection waiting for a request
+	connStateIdle connState = iota
+	// connStateActive is a connection serving a request
+	connStateActive
+	// connStateClosed is a connection closed by the server
+	connStateClosed
+)
+
+type Server struct {
+	// Addr is the TCP address ListenAndServe listens on, ":80" if empty.
+	Addr    string
+	Handler *Router
+
+	// ReadTimeout is the maximum duration for reading a request, including its body. Zero means no timeout.
+	ReadTimeout time.Duration
+	// WriteTimeout is the maximum duration from the end of reading the request headers until the response is written. Zero means no timeout.
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum duration to wait for the next request on a persistent connection.
+	// If zero, ReadTimeout is used.
+	IdleTimeout time.Duration
+	// MaxHeaderBytes limits the size of the request line and headers, DefaultMaxHeaderBytes if zero.
+	MaxHeaderBytes int
+	// MaxConnections limits the number of connections served concurrently, further connections wait to be accepted.
+	// Zero means no limit.
+	MaxConnections int
+	// TrustForwardedHeaders makes the X-Forwarded-Host and X-Forwarded-Proto headers override the host and scheme of the
+	// request URL, see ParserOptions.
+	TrustForwardedHeaders bool
+
+	// TLSConfig configures the TLS connections of ListenAndServeTLS and ServeTLS.
+	// The certificate files passed to them are added to a copy of it.
+	TLSConfig *tls.Config
+
+	// Logger logs errors of accepting connections and reading requests, log.Default() if nil.
+	Logger Logger
+
+	mu         sync.Mutex
+	listeners  map[net.Listener]struct{}
+	conns      map[net.Conn]connState
+	inShutdown atomic.Bool
+	slots      chan struct{}
+	slotsOnce  sync.Once
+}
+
+// ListenAndServe listens on the TCP address s.Addr and serves connections until Shutdown is called.
+//
+// ListenAndServe always returns a non-nil error, ErrServerClosed after Shutdown.
+func (s *Server) ListenAndServe() error {
+	if s.shuttingDown() {
+		return ErrServerClosed
+	}
+	addr := s.Addr
+	if addr == "" {
+		addr = ":80"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("[http.Server.ListenAndServe] listen failed: %w", err)
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l and serves requests from them until Shutdown is called. l is closed when Serve returns.
+//
+// Temporary accept errors are retried with an exponential backoff.
+// Serve always returns a non-nil error, ErrServerClosed after Shutdown.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.untrackListener(l)
+	defer l.Close()
+
+	var backoff time.Duration
+	for {
+		if !s.acquireSlot() {
+			return ErrServerClosed
+		}
+
+		conn, err := l.Accept()
+		if err != nil {
+			s.releaseSlot()
+			if s.shuttingDown() {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Temporary() {
+				if backoff == 0 {
+					backoff = 5 * time.Millisecond
+				} else if backoff *= 2; backoff > maxAcceptBackoff {
+					backoff = maxAcceptBackoff
+				}
+				s.logf("[http.Server.Serve] accept failed: %v; retrying in %v", err, backoff)
+				time.Sleep(backoff)
+				continue
+			}
+			return fmt.Errorf("[http.Server.Serve] accept failed: %w", err)
+		}
+		backoff = 0
+
+		if !s.trackConn(conn) {
+			conn.Close()
+			s.releaseSlot()
+			return ErrServerClosed
+		}
+		go func() {
+			defer s.releaseSlot()
+			defer s.untrackConn(conn)
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// ListenAndServeTLS listens on the TCP address s.Addr, ":443" if empty, and serves HTTPS connections until Shutdown is called.
+//
+// certFile and keyFile are the PEM encoded certificate and matching private key, they may be empty if s.TLSConfig provides certificates.
+// ListenAndServeTLS always returns a non-nil error, ErrServerClosed after Shutdown.
+func (s *Server) ListenAndServeTLS(certFile string, keyFile string) error {
+	if s.shuttingDown() {
+		return ErrServerClosed
+	}
+	addr := s.Addr
+	if addr == "" {
+		addr = ":443"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("[http.Server.ListenAndServeTLS] listen failed: %w", err)
+	}
+	return s.ServeTLS(l, certFile, keyFile)
+}
+
+// ServeTLS accepts connections on l and serves HTTPS requests from them until Shutdown is called, like Serve.
+//
+// certFile and keyFile are the PEM encoded certificate and matching private key, they may be empty if s.TLSConfig provides certificates.
+func (s *Server) ServeTLS(l net.Listener, certFile string, keyFile string) error {
+	config := &tls.Config{}
+	if s.TLSConfig != nil {
+		config = s.TLSConfig.Clone()
+	}
+	if len(config.NextProtos) == 0 {
+		config.NextProtos = []string{"http/1.1"}
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			l.Close()
+			return fmt.Errorf("[http.Server.ServeTLS] load certificate failed: %w", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	if len(config.Certificates) == 0 && config.GetCertificate == nil && config.GetConfigForClient == nil {
+		l.Close()
+		return errors.New("[http.Server.ServeTLS] no certificate configured")
+	}
+
+	return s.Serve(tls.NewListener(l, config))
+}
+
+// Shutdown stops the server gracefully: it closes all listeners and idle connections,
+// and waits for active connections to finish their current request.
+//
+// If ctx is done before all connections are closed, Shutdown returns the error of ctx. Connections still active are left open.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.inShutdown.Store(true)
+
+	s.mu.Lock()
+	for l := range s.listeners {
+		l.Close()
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for {
+		if s.closeIdleConns() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeConn serves requests from conn like a connection accepted by Serve, until the client or a response closes it.
+// It serves the connections of DialPipe, which connect a client in the same process without a network.
+func (s *Server) ServeConn(conn net.Conn) {
+	if !s.trackConn(conn) {
+		conn.Close()
+		return
+	}
+	defer s.untrackConn(conn)
+	s.serveConn(conn)
+}
+
+// DialPipe returns the client end of an in-memory connection whose server end is served by ServeConn, ignoring
+// network and addr. It can be used as Client.DialContext to send requests to s in the same process without a network.
+//
+// Unlike with net.Pipe, writes to the connection are buffered, so both ends may write at the same time.
+func (s *Server) DialPipe(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	client, server := newPipe()
+	go s.ServeConn(server)
+	return client, nil
+}
+
+// serveConn serves requests from conn until the client or a response closes the connection, or the server shuts down.
+func (s *Server) serveConn(conn net.Conn) {
+	// a panicking handler closes its connection instead of crashing the server
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logf("[http.Server] panic serving %s: %v\n%s", conn.RemoteAddr(), recovered, debug.Stack())
+		}
+	}()
+	defer conn.Close()
+
+	parser := NewRequestParser(conn, ParserOptions{MaxHeaderBytes: s.MaxHeaderBytes, Scheme: connScheme(conn), TrustForwardedHeaders: s.TrustForwardedHeaders})
+	w := bufio.NewWriter(conn)
+
+	for first := true; ; first = false {
+		// wait for the next request
+		waitTimeout := s.ReadTimeout
+		if !first && s.IdleTimeout > 0 {
+			waitTimeout = s.IdleTimeout
+		}
+		conn.SetReadDeadline(deadline(waitTimeout))
+		if _, err := parser.r.Peek(1); err != nil {
+			return
+		}
+		if !s.setConnState(conn, connStateActive) {
+			return
+		}
+
+		conn.SetReadDeadline(deadline(s.ReadTimeout))
+		req := parser.Next()
+
+		if req.IsErr() {
+			if status := parseErrorStatus(req.UnwrapErr()); status != "" {
+				conn.Write([]byte("HTTP/1.1 " + status + "\r\nConnection: close\r\n\r\n"))
+				s.logf("[http.Server] read request failed: %v", req.UnwrapErr())
+			}
+			return
+		}
+		request := req.Unwrap()
+		request.RemoteAddr = conn.RemoteAddr().String()
+
+		conn.SetWriteDeadline(deadline(s.WriteTimeout))
+
+		potentialRes := HandleRequest(conn, request, s.Handler)
+		var res Response[Body]
+		var bindErr *BindError
+		switch {
+		case potentialRes.IsOk():
+			res = potentialRes.Unwrap()
+		case errors.As(potentialRes.UnwrapErr(), &bindErr):
+			res = bindErr.Response()
+		default:
+			conn.Write([]byte("HTTP/1.1 500 Internal Server Error\r\nConnection: close\r\n\r\n"))
+			s.logf("[http.Server] handle request failed: %v", potentialRes.UnwrapErr())
+			return
+		}
+
+		if hijack, ok := hijackFor(res); ok {
+			s.hijackConn(conn, w, parser, &request, res, hijack)
+			return
+		}
+
+		keepAlive := request.KeepAlive() && !s.shuttingDown() && !hasToken(res.Headers().List("Connection"), "close")
+		keepAlive, err := writeResponse(w, &request, res, keepAlive)
+		if err != nil || !keepAlive {
+			return
+		}
+
+		// an unread rest of the body would be taken for the next request while the connection is idle
+		if !parser.discardBody(maxDiscardBodyBytes) {
+			return
+		}
+
+		if !s.setConnState(conn, connStateIdle) {
+			return
+		}
+	}
+}
+
+func (s *Server) logf(format string, v ...any) {
+	logf(s.Logger, format, v...)
+}
+
+// logf logs to logger, or to the standard logger if it's nil.
+func logf(logger Logger, format string, v ...any) {
+	if logger != nil {
+		logger.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
+
+func (s *Server) shuttingDown() bool {
+	return s.inShutdown.Load()
+}
+
+func (s *Server) trackListener(l net.Listener) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shuttingDown() {
+		return false
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackListener(l net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.listeners, l)
+}
+
+func (s *Server) trackConn(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shuttingDown() {
+		return false
+	}
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]connState)
+	}
+	s.conns[conn] = connStateIdle
+	return true
+}
+
+func (s *Server) untrackConn(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+}
+
+// setConnState sets the state of conn and returns false if the connection was closed by the server in the meantime.
+func (s *Server) setConnState(conn net.Conn, state connState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conns[conn] == connStateClosed {
+		return false
+	}
+	s.conns[conn] = state
+	return true
+}
+
+// closeIdleConns closes all idle connections and returns true if no connections are left.
+func (s *Server) closeIdleConns() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := true
+	for conn, state := range s.conns {
+		switch state {
+		case connStateIdle:
+			s.conns[conn] = connStateClosed
+			conn.Close()
+		case connStateActive:
+			done = false
+		}
+	}
+	return done
+}
+
+// acquireSlot blocks until a connection may be accepted with respect to s.MaxConnections.
+// Returns false if the server shut down while waiting.
+func (s *Server) acquireSlot() bool {
+	if s.MaxConnections <= 0 {
+		return true
+	}
+	s.slotsOnce.Do(func() {
+		s.slots = make(chan struct{}, s.MaxConnections)
+	})
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case s.slots <- struct{}{}:
+			return true
+		case <-ticker.C:
+			if s.shuttingDown() {
+				return false
+			}
+		}
+	}
+}
+
+func (s *Server) releaseSlot() {
+	if s.MaxConnections > 0 {
+		<-s.slots
+	}
+}
+
+// deadline returns the point in time timeout from now, or the zero time for no deadline if timeout is zero.
+func deadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
@@ -0,0 +1,384 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveTestConn serves conn like a connection accepted by s.
+func serveTestConn(s *Server, conn net.Conn) {
+	s.trackConn(conn)
+	defer s.untrackConn(conn)
+	s.serveConn(conn)
+}
+
+// startServer serves s on a random local port and returns its address and a channel receiving the error returned by Serve.
+func startServer(t *testing.T, s *Server) (string, <-chan error) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Serve(l)
+	}()
+	return l.Addr().String(), errs
+}
+
+// sizedHandler responds with text and a Content-Length set by the handler.
+func sizedHandler(text string) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		res := textHandler(text)(req)
+		res.SetHeader("Content-Length", fmt.Sprint(len(text)))
+		return res
+	}
+}
+
+// readResponse reads a response with a Content-Length and returns its status line, headers and body.
+func readResponse(r *bufio.Reader) (string, map[string]string, string, error) {
+	status, err := r.ReadString('\n')
+	if err != nil {
+		return "", nil, "", err
+	}
+	headers := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", nil, "", err
+		}
+		if line == "\r\n" {
+			break
+		}
+		key, value, _ := strings.Cut(strings.TrimSpace(line), ": ")
+		headers[key] = value
+	}
+	var length int
+	fmt.Sscan(headers["Content-Length"], &length)
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return strings.TrimSpace(status), headers, string(body), err
+}
+
+type testLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func (l *testLogger) Messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.messages...)
+}
+
+func TestServer_ServeAndShutdown(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Server{Handler: NewRouter().Route(Get("/", sizedHandler("hello")))}
+	addr, errs := startServer(t, s)
+
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for i := 0; i < 3; i++ {
+		fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+		status, _, body, err := readResponse(r)
+		assert.NoError(err)
+		assert.Equal("HTTP/1.1 200 OK", status)
+		assert.Equal("hello", body)
+	}
+
+	assert.NoError(s.Shutdown(context.Background()))
+	assert.ErrorIs(<-errs, ErrServerClosed)
+
+	// the idle keep-alive connection is closed
+	_, err = r.ReadByte()
+	assert.Equal(io.EOF, err)
+
+	_, err = net.Dial("tcp", addr)
+	assert.Error(err)
+
+	assert.ErrorIs(s.ListenAndServe(), ErrServerClosed)
+}
+
+func TestServer_Shutdown_DrainsActiveRequests(t *testing.T) {
+	assert := assert.New(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := &Server{Handler: NewRouter().Route(Get("/slow", func(req *Request[RequestBody]) Response[Body] {
+		close(started)
+		<-release
+		return sizedHandler("done")(req)
+	}))}
+	addr, errs := startServer(t, s)
+
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /slow HTTP/1.1\r\n\r\n")
+	<-started
+
+	// the deadline passes while the request is still active
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(s.Shutdown(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(<-errs, ErrServerClosed)
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+
+	close(release)
+	status, headers, body, err := readResponse(bufio.NewReader(conn))
+	assert.NoError(err)
+	assert.Equal("HTTP/1.1 200 OK", status)
+	assert.Equal("done", body)
+	assert.Equal("close", headers["Connection"])
+
+	assert.NoError(<-shutdown)
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Server{
+		Handler:     NewRouter().Route(Get("/", sizedHandler("ok"))),
+		ReadTimeout: 100 * time.Millisecond,
+		IdleTimeout: 50 * time.Millisecond,
+	}
+	addr, _ := startServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	// incomplete request headers
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\n")
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Equal(io.EOF, err)
+
+	// idle keep-alive connection
+	idle, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer idle.Close()
+	fmt.Fprint(idle, "GET / HTTP/1.1\r\n\r\n")
+	r := bufio.NewReader(idle)
+	_, _, body, err := readResponse(r)
+	assert.NoError(err)
+	assert.Equal("ok", body)
+
+	idle.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = r.ReadByte()
+	assert.Equal(io.EOF, err)
+}
+
+func TestServer_MaxConnections(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Server{Handler: NewRouter().Route(Get("/", sizedHandler("ok"))), MaxConnections: 1}
+	addr, _ := startServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	first, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	fmt.Fprint(first, "GET / HTTP/1.1\r\n\r\n")
+	_, _, body, err := readResponse(bufio.NewReader(first))
+	assert.NoError(err)
+	assert.Equal("ok", body)
+
+	second, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer second.Close()
+	fmt.Fprint(second, "GET / HTTP/1.1\r\n\r\n")
+
+	// the second connection isn't served while the first one is open
+	second.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	r := bufio.NewReader(second)
+	_, err = r.Peek(1)
+	var ne net.Error
+	assert.True(errors.As(err, &ne) && ne.Timeout(), err)
+
+	first.Close()
+	second.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, body, err = readResponse(r)
+	assert.NoError(err)
+	assert.Equal("ok", body)
+}
+
+func TestServer_MaxHeaderBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	logger := &testLogger{}
+	s := &Server{Handler: NewRouter(), MaxHeaderBytes: 1024, Logger: logger}
+	addr, _ := startServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nCookie: "+strings.Repeat("a", 2048)+"\r\n\r\n")
+
+	status, _, _, err := readResponse(bufio.NewReader(conn))
+	assert.NoError(err)
+	assert.Equal("HTTP/1.1 431 Request Header Fields Too Large", status)
+	assert.Len(logger.Messages(), 1)
+}
+
+// flakyListener fails to accept with a temporary error a number of times.
+type flakyListener struct {
+	net.Listener
+	failures int
+}
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "too many open files" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	if l.failures > 0 {
+		l.failures--
+		return nil, temporaryError{}
+	}
+	return l.Listener.Accept()
+}
+
+func TestServer_Serve_AcceptBackoff(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+
+	logger := &testLogger{}
+	s := &Server{Handler: NewRouter().Route(Get("/", sizedHandler("ok"))), Logger: logger}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Serve(&flakyListener{Listener: l, failures: 3})
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\n\r\n")
+	_, _, body, err := readResponse(bufio.NewReader(conn))
+	assert.NoError(err)
+	assert.Equal("ok", body)
+	assert.Len(logger.Messages(), 3)
+
+	assert.NoError(s.Shutdown(context.Background()))
+	assert.ErrorIs(<-errs, ErrServerClosed)
+}
+
+func TestServer_HandlerError(t *testing.T) {
+	logger := &testLogger{}
+	r := NewRouter().Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		return shepard.Err[Response[Body], error](errors.New("boom"))
+	})
+	s := &Server{Handler: r, Logger: logger}
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go serveTestConn(s, server)
+	go fmt.Fprint(client, "GET / HTTP/1.1\r\n\r\n")
+
+	status, err := bufio.NewReader(client).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 500 Internal Server Error\r\n", status)
+	assert.Eventually(t, func() bool { return len(logger.Messages()) == 1 }, time.Second, time.Millisecond)
+	assert.Contains(t, logger.Messages()[0], "boom")
+}
+
+func TestServer_HandlerPanic(t *testing.T) {
+	assert := assert.New(t)
+
+	logger := &testLogger{}
+	r := NewRouter()
+	r.Route(Get("/panic", func(req *Request[RequestBody]) Response[Body] {
+		panic("boom")
+	}))
+	r.Route(Get("/", sizedHandler("ok")))
+	s := &Server{Handler: r, Logger: logger}
+	addr, errs := startServer(t, s)
+
+	// the connection of the panicking handler is closed
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /panic HTTP/1.1\r\n\r\n")
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	assert.ErrorIs(err, io.EOF)
+	assert.Eventually(func() bool { return len(logger.Messages()) == 1 }, time.Second, time.Millisecond)
+	assert.Contains(logger.Messages()[0], "boom")
+
+	// the server keeps serving
+	conn, err = net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\n\r\n")
+	_, _, body, err := readResponse(bufio.NewReader(conn))
+	assert.NoError(err)
+	assert.Equal("ok", body)
+
+	assert.NoError(s.Shutdown(context.Background()))
+	assert.ErrorIs(<-errs, ErrServerClosed)
+}
+
+func TestServer_UnreadBody(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Server{Handler: NewRouter().Route(Post("/", sizedHandler("ignored")))}
+	addr, errs := startServer(t, s)
+
+	conn, err := net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	fmt.Fprint(conn, "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n4\r\ndata\r\n0\r\n\r\n")
+	_, _, body, err := readResponse(r)
+	assert.NoError(err)
+	assert.Equal("ignored", body)
+
+	// the unread body was discarded, so the connection is idle and closed on shutdown
+	assert.NoError(s.Shutdown(context.Background()))
+	assert.ErrorIs(<-errs, ErrServerClosed)
+	_, err = r.ReadByte()
+	assert.Equal(io.EOF, err)
+
+	// bodies too large to be discarded close the connection
+	s = &Server{Handler: NewRouter().Route(Post("/", sizedHandler("ignored")))}
+	addr, _ = startServer(t, s)
+	defer s.Shutdown(context.Background())
+	conn, err = net.Dial("tcp", addr)
+	assert.NoError(err)
+	defer conn.Close()
+	r = bufio.NewReader(conn)
+	go fmt.Fprintf(conn, "POST / HTTP/1.1\r\nContent-Length: %d\r\n\r\n%s", maxDiscardBodyBytes+1, strings.Repeat("a", maxDiscardBodyBytes+1))
+	_, _, _, err = readResponse(r)
+	assert.NoError(err)
+	_, err = r.ReadByte()
+	assert.Equal(io.EOF, err)
+}
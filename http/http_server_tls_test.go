@@ -0,0 +1,194 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// selfSignedCert generates a self-signed certificate for 127.0.0.1 and localhost,
+// writes it and its key to PEM files in a temporary directory and returns their paths and a pool trusting the certificate.
+func selfSignedCert(t *testing.T) (string, string, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return certFile, keyFile, pool
+}
+
+// urlHandler responds with the scheme, host and port of the request URL.
+func urlHandler(req *Request[RequestBody]) Response[Body] {
+	return sizedHandler(fmt.Sprintf("%s://%s:%d", req.URL.Scheme, req.URL.Host, req.URL.Port))(req)
+}
+
+func TestServer_ServeTLS(t *testing.T) {
+	assert := assert.New(t)
+
+	certFile, keyFile, pool := selfSignedCert(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	s := &Server{
+		Handler:               NewRouter().Route(Get("/", urlHandler)),
+		TLSConfig:             &tls.Config{MinVersion: tls.VersionTLS12},
+		TrustForwardedHeaders: true,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.ServeTLS(l, certFile, keyFile)
+	}()
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost", NextProtos: []string{"http/1.1"}})
+	assert.NoError(err)
+	defer conn.Close()
+	assert.Equal("http/1.1", conn.ConnectionState().NegotiatedProtocol)
+
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	status, _, body, err := readResponse(r)
+	assert.NoError(err)
+	assert.Equal("HTTP/1.1 200 OK", status)
+	assert.Equal("https://localhost:443", body)
+
+	// behind a proxy terminating TLS
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost:8443\r\nX-Forwarded-Proto: http\r\n\r\n")
+	_, _, body, err = readResponse(r)
+	assert.NoError(err)
+	assert.Equal("http://localhost:8443", body)
+
+	// the configured minimal version is kept
+	_, err = tls.Dial("tcp", l.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost", MaxVersion: tls.VersionTLS11})
+	assert.Error(err)
+
+	assert.NoError(s.Shutdown(context.Background()))
+	assert.ErrorIs(<-errs, ErrServerClosed)
+}
+
+func TestServer_ServeTLS_Config(t *testing.T) {
+	assert := assert.New(t)
+
+	certFile, keyFile, pool := selfSignedCert(t)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	assert.NoError(err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	s := &Server{
+		Handler:   NewRouter().Route(Get("/", urlHandler)),
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	go s.ServeTLS(l, "", "")
+	defer s.Shutdown(context.Background())
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	assert.NoError(err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: 127.0.0.1:8443\r\n\r\n")
+	_, _, body, err := readResponse(bufio.NewReader(conn))
+	assert.NoError(err)
+	assert.Equal("https://127.0.0.1:8443", body)
+}
+
+func TestServer_ServeTLS_Errors(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	err = (&Server{Handler: NewRouter()}).ServeTLS(l, "", "")
+	assert.ErrorContains(t, err, "no certificate")
+
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	err = (&Server{Handler: NewRouter()}).ServeTLS(l, "missing.pem", "missing.key")
+	assert.ErrorContains(t, err, "load certificate failed")
+}
+
+func TestServer_Serve_Scheme(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, trusted := range []bool{false, true} {
+		s := &Server{Handler: NewRouter().Route(Get("/", urlHandler)), TrustForwardedHeaders: trusted}
+		addr, _ := startServer(t, s)
+		defer s.Shutdown(context.Background())
+
+		conn, err := net.Dial("tcp", addr)
+		assert.NoError(err)
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		for _, tc := range []struct {
+			headers   string
+			expected  string
+			untrusted string
+		}{
+			{headers: "Host: example.com", expected: "http://example.com:80"},
+			{headers: "Host: example.com\r\nX-Forwarded-Proto: HTTPS", expected: "https://example.com:443", untrusted: "http://example.com:80"},
+			{headers: "Host: internal:8080\r\nX-Forwarded-Host: example.com\r\nX-Forwarded-Proto: https", expected: "https://example.com:443", untrusted: "http://internal:8080"},
+			{headers: "Host: [::1]:8080", expected: "http://::1:8080"},
+		} {
+			expected := tc.expected
+			if !trusted && tc.untrusted != "" {
+				// the forwarded headers of clients are ignored
+				expected = tc.untrusted
+			}
+			fmt.Fprint(conn, "GET / HTTP/1.1\r\n"+tc.headers+"\r\n\r\n")
+			_, _, body, err := readResponse(r)
+			assert.NoError(err)
+			assert.Equal(expected, body, strings.ReplaceAll(tc.headers, "\r\n", ", "))
+		}
+	}
+}
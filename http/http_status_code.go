@@ -5,6 +5,7 @@ import "strconv"
 type StatusCode int
 
 const (
+	StatusCodeSwitchingProtocols           StatusCode = 101
 	StatusCodeOk                           StatusCode = 200
 	StatusCodeCreated                      StatusCode = 201
 	StatusCodeAccepted                     StatusCode = 202
@@ -19,6 +20,7 @@ const (
 	StatusCodeNotModified                  StatusCode = 304
 	StatusCodeUseProxy                     StatusCode = 305
 	StatusCodeTemporaryRedirect            StatusCode = 307
+	StatusCodePermanentRedirect            StatusCode = 308
 	StatusCodeBadRequest                   StatusCode = 400
 	StatusCodeUnauthorized                 StatusCode = 401
 	StatusCodePaymentRequired              StatusCode = 402
@@ -37,6 +39,9 @@ const (
 	StatusCodeUnsupportedMediaType         StatusCode = 415
 	StatusCodeRequestedRangeNotSatisfiable StatusCode = 416
 	StatusCodeExpectationFailed            StatusCode = 417
+	StatusCodeUpgradeRequired              StatusCode = 426
+	StatusCodeTooManyRequests              StatusCode = 429
+	StatusCodeRequestHeaderFieldsTooLarge  StatusCode = 431
 	StatusCodeInternalServerError          StatusCode = 500
 	StatusCodeNotImplemented               StatusCode = 501
 	StatusCodeBadGateway                   StatusCode = 502
@@ -52,3 +57,59 @@ func (s StatusCode) Default() StatusCode {
 func (s StatusCode) String() string {
 	return strconv.Itoa(int(s))
 }
+
+// Reason returns the reason phrase of the status code, or an empty string if it is unknown.
+func (s StatusCode) Reason() string {
+	return reasonPhrases[s]
+}
+
+var reasonPhrases = map[StatusCode]string{
+	StatusCodeSwitchingProtocols:           "Switching Protocols",
+	StatusCodeOk:                           "OK",
+	StatusCodeCreated:                      "Created",
+	StatusCodeAccepted:                     "Accepted",
+	StatusCodeNonAuthoritativeInformation:  "Non-Authoritative Information",
+	StatusCodeNoContent:                    "No Content",
+	StatusCodeResetContent:                 "Reset Content",
+	StatusCodePartialContent:               "Partial Content",
+	StatusCodeMultipleChoices:              "Multiple Choices",
+	StatusCodeMovedPermanently:             "Moved Permanently",
+	StatusCodeFound:                        "Found",
+	StatusCodeSeeOther:                     "See Other",
+	StatusCodeNotModified:                  "Not Modified",
+	StatusCodeUseProxy:                     "Use Proxy",
+	StatusCodeTemporaryRedirect:            "Temporary Redirect",
+	StatusCodePermanentRedirect:            "Permanent Redirect",
+	StatusCodeBadRequest:                   "Bad Request",
+	StatusCodeUnauthorized:                 "Unauthorized",
+	StatusCodePaymentRequired:              "Payment Required",
+	StatusCodeForbidden:                    "Forbidden",
+	StatusCodeNotFound:                     "Not Found",
+	StatusCodeMethodNotAllowed:             "Method Not Allowed",
+	StatusCodeNotAcceptable:                "Not Acceptable",
+	StatusCodeProxyAuthenticationRequired:  "Proxy Authentication Required",
+	StatusCodeRequestTimeout:               "Request Timeout",
+	StatusCodeConflict:                     "Conflict",
+	StatusCodeGone:                         "Gone",
+	StatusCodeLengthRequired:               "Length Required",
+	StatusCodePreconditionFailed:           "Precondition Failed",
+	StatusCodeRequestEntityTooLarge:        "Request Entity Too Large",
+	StatusCodeRequestURITooLong:            "Request-URI Too Long",
+	StatusCodeUnsupportedMediaType:         "Unsupported Media Type",
+	StatusCodeRequestedRangeNotSatisfiable: "Requested Range Not Satisfiable",
+	StatusCodeExpectationFailed:            "Expectation Failed",
+	StatusCodeUpgradeRequired:              "Upgrade Required",
+	StatusCodeTooManyRequests:              "Too Many Requests",
+	StatusCodeRequestHeaderFieldsTooLarge:  "Request Header Fields Too Large",
+	StatusCodeInternalServerError:          "Internal Server Error",
+	StatusCodeNotImplemented:               "Not Implemented",
+	StatusCodeBadGateway:                   "Bad Gateway",
+	StatusCodeServiceUnavailable:           "Service Unavailable",
+	StatusCodeGatewayTimeout:               "Gateway Timeout",
+	StatusCodeHTTPVersionNotSupported:      "HTTP Version Not Supported",
+}
+
+// bodyAllowed returns false for status codes whose responses must not have a body.
+func (s StatusCode) bodyAllowed() bool {
+	return s >= 200 && s != StatusCodeNoContent && s != StatusCodeNotModified
+}
@@ -0,0 +1,382 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+)
+
+// FromStdHandler adapts a net/http handler, e.g. of net/http/pprof or Prometheus, to a Handler.
+//
+// The response is returned when std returns or flushes it for the first time, the rest of a flushed response is streamed.
+// A panic of std is propagated to the caller if the response wasn't flushed yet, otherwise it ends the body.
+func FromStdHandler(std stdhttp.Handler) Handler {
+	return func(req *Request[RequestBody]) Response[Body] {
+		return newStdResponseWriter().serve(std, req)
+	}
+}
+
+// FromStdMiddleware adapts a net/http middleware, e.g. of gorilla/handlers, to a Middleware.
+//
+// Changes of the middleware to the context, headers, path, query and body of the request are passed on to the next handlers.
+// An error of the next handlers is returned unless the middleware flushed the response before, then it's dropped.
+func FromStdMiddleware(mw func(stdhttp.Handler) stdhttp.Handler) Middleware {
+	return func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		writer := newStdResponseWriter()
+		inner := stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+			if updated := FromStdRequest(r); updated.IsOk() {
+				updateRequest(req, updated.Unwrap())
+			}
+			res := next()
+			if res.IsErr() {
+				writer.fail(res.UnwrapErr())
+				return
+			}
+			WriteStdResponse(w, res.Unwrap())
+		})
+
+		res := writer.serve(mw(inner), req)
+		if err := writer.error(); err != nil {
+			return shepard.Err[Response[Body], error](err)
+		}
+		return shepard.Ok[Response[Body], error](res)
+	}
+}
+
+// ServeHTTP implements net/http.Handler, so the router can be served by a net/http server or mounted into another net/http handler.
+// Errors of handlers and of writing the response are logged to the Logger of the Router.
+func (r *Router) ServeHTTP(w stdhttp.ResponseWriter, stdReq *stdhttp.Request) {
+	req := FromStdRequest(stdReq)
+	if req.IsErr() {
+		status := StatusCodeNotImplemented
+		if errors.Is(req.UnwrapErr(), ErrMalformedRequest) {
+			status = StatusCodeBadRequest
+		}
+		stdhttp.Error(w, status.Reason(), int(status))
+		return
+	}
+	request := req.Unwrap()
+
+	res := r.Serve(&request)
+	var bindErr *BindError
+	if res.IsErr() && errors.As(res.UnwrapErr(), &bindErr) {
+		res = shepard.Ok[Response[Body], error](bindErr.Response())
+	}
+	if res.IsErr() {
+		logf(r.root.logger, "[http.Router.ServeHTTP] handle request failed: %v", res.UnwrapErr())
+		stdhttp.Error(w, StatusCodeInternalServerError.Reason(), int(StatusCodeInternalServerError))
+		return
+	}
+	if err := WriteStdResponse(w, res.Unwrap()); err != nil {
+		logf(r.root.logger, "[http.Router.ServeHTTP] write response failed: %v", err)
+	}
+}
+
+// ToStdRequest converts req to a net/http server request. The body of req is shared with it.
+func ToStdRequest(req *Request[RequestBody]) *stdhttp.Request {
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	header := stdhttp.Header{}
+	// every value is added on its own, joining them with ", " would break headers like Cookie, which net/http splits on ";"
+	req.Headers.Iter().Foreach(func(_ int, h hashmap.Pair[*string, *slice.Slice[string]]) {
+		h.Value.Iter().Foreach(func(_ int, v string) {
+			header.Add(*h.Key, v)
+		})
+	})
+	host := header.Get("Host")
+	header.Del("Host")
+	if host == "" {
+		host = req.URL.hostPort()
+	}
+
+	var body io.ReadCloser = stdhttp.NoBody
+	var contentLength int64
+	if _, empty := req.body.(noBody); !empty && req.body != nil {
+		body = io.NopCloser(req.body)
+		contentLength = -1
+		if n, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+			contentLength = n
+		}
+	}
+
+	minor := 1
+	if req.Version == "1.0" {
+		minor = 0
+	}
+	u := &url.URL{Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+
+	stdReq := &stdhttp.Request{
+		Method:        req.Method.String(),
+		URL:           u,
+		Proto:         "HTTP/1." + strconv.Itoa(minor),
+		ProtoMajor:    1,
+		ProtoMinor:    minor,
+		Header:        header,
+		Body:          body,
+		ContentLength: contentLength,
+		Close:         req.closeConn,
+		RemoteAddr:    req.RemoteAddr,
+		Host:          host,
+		RequestURI:    u.RequestURI(),
+	}
+	return stdReq.WithContext(ctx)
+}
+
+// FromStdRequest converts a net/http server request to a Request. The body of r is shared with it.
+//
+// The scheme of the URL is "https" if r was received over TLS.
+func FromStdRequest(r *stdhttp.Request) shepard.Result[Request[RequestBody], error] {
+	method := TryMethodFromString(r.Method)
+	if method.IsErr() {
+		return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.FromStdRequest] %w", method.UnwrapErr()))
+	}
+
+	builder := NewRequestBuilder[RequestBody]().
+		Method(method.Unwrap()).
+		Version(Version(fmt.Sprintf("%d.%d", r.ProtoMajor, r.ProtoMinor)))
+
+	for key, values := range r.Header {
+		for _, value := range values {
+			builder.request.Headers.Add(key, value)
+		}
+	}
+
+	u := URL{Scheme: "http", Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+	if r.Host != "" {
+		builder.request.Headers.Set("Host", r.Host)
+		hostname, port, err := parseHost(r.Host, u.Scheme)
+		if err != nil {
+			return shepard.Err[Request[RequestBody], error](fmt.Errorf("[http.FromStdRequest] %s: %w", err, ErrMalformedRequest))
+		}
+		u.Host = hostname
+		u.Port = port
+	}
+	builder.URL(u)
+
+	builder.request.Context = r.Context()
+	builder.request.closeConn = r.Close
+	builder.request.RemoteAddr = r.RemoteAddr
+
+	var body RequestBody = noBody{}
+	if r.Body != nil && r.Body != stdhttp.NoBody {
+		body = r.Body
+	}
+	return builder.Body(body)
+}
+
+// WriteStdResponse writes res to a net/http response writer.
+//
+// Streaming bodies are flushed to the client whenever they are flushed by the handler, if w implements net/http.Flusher.
+//
+// Responses returned by Hijack take over the connection hijacked from w, which must implement net/http.Hijacker.
+func WriteStdResponse(w stdhttp.ResponseWriter, res Response[Body]) error {
+	if hijack, ok := hijackFor(res); ok {
+		return hijackStd(w, res, hijack)
+	}
+	header := w.Header()
+	res.Headers().Iter().Foreach(func(_ int, h hashmap.Pair[*string, *slice.Slice[string]]) {
+		values := make([]string, 0, h.Value.Len())
+		h.Value.Iter().Foreach(func(_ int, v string) {
+			values = append(values, v)
+		})
+		if *h.Key == "Set-Cookie" {
+			for _, value := range values {
+				header.Add(*h.Key, value)
+			}
+			return
+		}
+		header.Set(*h.Key, strings.Join(values, ", "))
+	})
+	status := res.StatusCode()
+
+	stream, streaming := res.Body().(StreamingBody)
+	if !streaming || stream.Closed() || !status.bodyAllowed() {
+		if streaming {
+			// the stream isn't sent, closing it stops its producer
+			defer stream.Close()
+		}
+		data := ReadBody(res.Body())
+		if !status.bodyAllowed() {
+			w.WriteHeader(int(status))
+			return nil
+		}
+		if header.Get("Content-Length") == "" {
+			header.Set("Content-Length", strconv.Itoa(len(data)))
+		}
+		w.WriteHeader(int(status))
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("[http.WriteStdResponse] write body failed: %w", err)
+		}
+		return nil
+	}
+
+	w.WriteHeader(int(status))
+	flusher, _ := w.(stdhttp.Flusher)
+	for done := false; !done; {
+		select {
+		case <-stream.Flushed():
+		case <-stream.Done():
+			done = true
+		}
+		if data := ReadBody(stream); len(data) > 0 {
+			if _, err := w.Write(data); err != nil {
+				stream.Close()
+				return fmt.Errorf("[http.WriteStdResponse] write body failed: %w", err)
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
+
+// updateRequest applies the changes a net/http middleware made to the converted request to req.
+func updateRequest(req *Request[RequestBody], updated Request[RequestBody]) {
+	req.Context = updated.Context
+	req.Headers = updated.Headers
+	req.body = updated.body
+	req.URL.Path = updated.URL.Path
+	if req.URL.RawQuery != updated.URL.RawQuery {
+		req.URL.RawQuery = updated.URL.RawQuery
+		req.URL.values = nil
+	}
+}
+
+// stdResponseWriter is a net/http.ResponseWriter building a Response.
+type stdResponseWriter struct {
+	mu         sync.Mutex
+	header     stdhttp.Header
+	status     int
+	body       *StreamBody
+	committed  bool
+	done       chan struct{}
+	res        Response[Body]
+	panicValue any
+	// err is the error of the next handlers of FromStdMiddleware
+	err error
+}
+
+var _ stdhttp.Flusher = (*stdResponseWriter)(nil)
+
+func newStdResponseWriter() *stdResponseWriter {
+	return &stdResponseWriter{
+		header: stdhttp.Header{},
+		body:   NewStreamBody(),
+		done:   make(chan struct{}),
+	}
+}
+
+func (w *stdResponseWriter) Header() stdhttp.Header {
+	return w.header
+}
+
+func (w *stdResponseWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *stdResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(stdhttp.StatusOK)
+	res := w.body.Write(slice.Init(p...))
+	if res.IsErr() {
+		return 0, res.UnwrapErr()
+	}
+	return len(p), nil
+}
+
+func (w *stdResponseWriter) Flush() {
+	w.WriteHeader(stdhttp.StatusOK)
+	w.commit()
+	w.body.Flush()
+}
+
+// commit builds the response from the status and the headers written so far and hands it to the caller of response.
+func (w *stdResponseWriter) commit() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed {
+		return
+	}
+	w.committed = true
+
+	status := w.status
+	if status == 0 {
+		status = stdhttp.StatusOK
+	}
+	res := NewHttpResponseBytes()
+	res.SetStatusCode(StatusCode(status))
+	for key, values := range w.header {
+		res.Headers().Add(key, values...)
+	}
+	res.SetBody(w.body)
+	w.res = res
+	close(w.done)
+}
+
+// serve runs std with req on its own goroutine and returns the response once it's committed.
+func (w *stdResponseWriter) serve(std stdhttp.Handler, req *Request[RequestBody]) Response[Body] {
+	stdReq := ToStdRequest(req)
+	go func() {
+		defer func() {
+			w.finish(recover())
+		}()
+		std.ServeHTTP(w, stdReq)
+	}()
+	return w.response()
+}
+
+// fail records err as the error of the handler, unless the response was committed already.
+func (w *stdResponseWriter) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.committed {
+		w.err = err
+	}
+}
+
+// error returns the error recorded by fail.
+func (w *stdResponseWriter) error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// finish completes the response after the handler returned or panicked with recovered.
+func (w *stdResponseWriter) finish(recovered any) {
+	w.mu.Lock()
+	if recovered != nil && !w.committed {
+		w.panicValue = recovered
+	}
+	w.mu.Unlock()
+	w.commit()
+	w.body.Close()
+}
+
+// response waits until the response is committed and returns it.
+func (w *stdResponseWriter) response() Response[Body] {
+	<-w.done
+	if w.panicValue != nil {
+		panic(w.panicValue)
+	}
+	return w.res
+}
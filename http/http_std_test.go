@@ -0,0 +1,222 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey string
+
+func TestFromStdHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	std := stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Add("X-Values", "a")
+		w.Header().Add("X-Values", "b")
+		w.WriteHeader(stdhttp.StatusCreated)
+		fmt.Fprintf(w, "%s %s?%s host=%s accept=%s body=%s", r.URL.Path, r.URL.Query().Get("q"), r.URL.RawQuery, r.Host, r.Header.Get("Accept"), body)
+	})
+
+	parser := NewRequestParser(strings.NewReader("POST /items?q=1 HTTP/1.1\r\nHost: example.com:8080\r\nAccept: text/html, application/json\r\nContent-Length: 4\r\n\r\ndata"), ParserOptions{})
+	req := parser.Next().Unwrap()
+	res := FromStdHandler(std)(&req)
+
+	assert.Equal(StatusCodeCreated, res.StatusCode())
+	assert.Equal(slice.Init("POST"), res.Headers().Get("X-Method"))
+	assert.Equal(slice.Init("a", "b"), res.Headers().Get("X-Values"))
+	assert.Equal("/items 1?q=1 host=example.com:8080 accept=text/html, application/json body=data", readBody(res))
+	assert.True(res.Body().Closed())
+}
+
+func TestFromStdHandler_Streaming(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	std := stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		io.WriteString(w, "first")
+		w.(stdhttp.Flusher).Flush()
+		<-release
+		io.WriteString(w, "second")
+	})
+
+	// the response is returned before the handler finished
+	res := FromStdHandler(std)(newTestRequest(MethodGet, "/"))
+	assert.Equal(StatusCodeOk, res.StatusCode())
+	stream := res.Body().(StreamingBody)
+	<-stream.Flushed()
+	assert.Equal("first", readBody(res))
+	assert.False(stream.Closed())
+
+	close(release)
+	<-stream.Done()
+	assert.Equal("second", readBody(res))
+}
+
+func TestFromStdHandler_Panic(t *testing.T) {
+	std := stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		panic("boom")
+	})
+	assert.PanicsWithValue(t, "boom", func() {
+		FromStdHandler(std)(newTestRequest(MethodGet, "/"))
+	})
+}
+
+func TestFromStdMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	tagging := func(next stdhttp.Handler) stdhttp.Handler {
+		return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+			w.Header().Set("X-Middleware", "std")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey("user"), "alice")))
+		})
+	}
+
+	r := NewRouter().
+		Use(FromStdMiddleware(tagging)).
+		Use(FromStdMiddleware(func(next stdhttp.Handler) stdhttp.Handler {
+			return stdhttp.StripPrefix("/api", next)
+		}))
+	r.Route(Get("/api/users", func(req *Request[RequestBody]) Response[Body] {
+		return textHandler(fmt.Sprintf("%s %v", req.URL.Path, req.Context.Value(ctxKey("user"))))(req)
+	}))
+	r.NotFound(func(req *Request[RequestBody]) Response[Body] {
+		return textHandler("not found: " + req.URL.Path)(req)
+	})
+
+	res := r.Serve(newTestRequest(MethodGet, "/api/users")).Unwrap()
+	assert.Equal(slice.Init("std"), res.Headers().Get("X-Middleware"))
+	assert.Equal("not found: /users", readBody(res))
+
+	// errors of the next handlers are passed through the middleware
+	errBoom := errors.New("boom")
+	failing := NewRouter().
+		Use(FromStdMiddleware(tagging)).
+		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+			return shepard.Err[Response[Body], error](errBoom)
+		})
+	assert.ErrorIs(failing.Serve(newTestRequest(MethodGet, "/")).UnwrapErr(), errBoom)
+
+	// errors after the middleware flushed the response are dropped
+	flushing := NewRouter().
+		Use(FromStdMiddleware(func(next stdhttp.Handler) stdhttp.Handler {
+			return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+				w.Write([]byte("early"))
+				w.(stdhttp.Flusher).Flush()
+				next.ServeHTTP(w, r)
+			})
+		})).
+		Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+			return shepard.Err[Response[Body], error](errBoom)
+		})
+	res = flushing.Serve(newTestRequest(MethodGet, "/")).Unwrap()
+	assert.Equal("early", readBody(res))
+}
+
+func TestRouter_ServeHTTP(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRouter()
+	r.Route(Get("/users/:id", paramsHandler("id")))
+	r.Route(Get("/stream", streamHandler("a", "b", "c")))
+	r.Route(Get("/url", urlHandler))
+
+	mux := stdhttp.NewServeMux()
+	mux.Handle("/", r)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	res, err := stdhttp.Get(server.URL + "/users/7")
+	assert.NoError(err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(200, res.StatusCode)
+	assert.Equal("id=7;", string(body))
+	assert.Equal(int64(5), res.ContentLength)
+
+	res, err = stdhttp.Get(server.URL + "/stream")
+	assert.NoError(err)
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal("abc", string(body))
+	assert.Equal([]string{"chunked"}, res.TransferEncoding)
+
+	res, err = stdhttp.Get(server.URL + "/missing")
+	assert.NoError(err)
+	res.Body.Close()
+	assert.Equal(404, res.StatusCode)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "https://example.com/url", nil))
+	assert.Equal("https://example.com:443", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("BREW", "/", nil))
+	assert.Equal(501, recorder.Code)
+
+	// errors of handlers are logged to the logger of the router
+	logger := &testLogger{}
+	failing := NewRouter().Use(func(req *Request[RequestBody], next Next) shepard.Result[Response[Body], error] {
+		return shepard.Err[Response[Body], error](errors.New("boom"))
+	})
+	failing.Logger(logger)
+	recorder = httptest.NewRecorder()
+	failing.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(500, recorder.Code)
+	assert.Equal([]string{"[http.Router.ServeHTTP] handle request failed: boom"}, logger.Messages())
+
+	// streams which aren't sent are closed
+	stream := NewStreamBody()
+	recorder = httptest.NewRecorder()
+	assert.NoError(WriteStdResponse(recorder, NewResponseBuilder(NewHttpResponseBytes()).Status(StatusCodeNotModified).Body(stream).Unwrap()))
+	assert.Equal(304, recorder.Code)
+	assert.True(stream.Closed())
+}
+
+func TestToStdRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewRequestParser(bufio.NewReader(strings.NewReader("GET /a%20b?x=1 HTTP/1.0\r\nX-Forwarded-Proto: https\r\nX-Forwarded-Host: example.com\r\n\r\n")), ParserOptions{TrustForwardedHeaders: true})
+	req := parser.Next().Unwrap()
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	stdReq := ToStdRequest(&req)
+	assert.Equal("192.0.2.1:1234", stdReq.RemoteAddr)
+	assert.Equal("GET", stdReq.Method)
+	assert.Equal("/a b", stdReq.URL.Path)
+	assert.Equal("/a%20b?x=1", stdReq.RequestURI)
+	assert.Equal("example.com", stdReq.Host)
+	assert.Equal(0, stdReq.ProtoMinor)
+	assert.Equal(stdhttp.NoBody, stdReq.Body)
+	assert.True(stdReq.Close)
+
+	converted := FromStdRequest(stdReq).Unwrap()
+	assert.Equal(MethodGet, converted.Method)
+	assert.Equal("example.com", converted.URL.Host)
+	assert.Equal(uint16(80), converted.URL.Port)
+	assert.Equal(Version("1.0"), converted.Version)
+	assert.Equal("192.0.2.1:1234", converted.RemoteAddr)
+	assert.False(converted.KeepAlive())
+
+	// several Cookie headers stay separate cookies
+	req = NewRequestParser(strings.NewReader("GET / HTTP/1.1\r\nHost: example.com\r\nCookie: a=1; b=2\r\nCookie: c=3\r\nAccept: text/html\r\nAccept: application/json\r\n\r\n"), ParserOptions{}).Next().Unwrap()
+	stdReq = ToStdRequest(&req)
+	cookies := stdReq.Cookies()
+	assert.Len(cookies, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		assert.Equal(name, cookies[i].Name)
+	}
+	assert.Equal([]string{"text/html", "application/json"}, stdReq.Header.Values("Accept"))
+}
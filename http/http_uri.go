@@ -2,6 +2,7 @@ package http
 
 import (
 	"errors"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
@@ -115,6 +116,45 @@ func (u *URL) Query() *Values {
 	return u.values
 }
 
+// String reassembles the URL. The port is omitted if it is the default port of the scheme.
+func (u *URL) String() string {
+	std := url.URL{
+		Scheme:   u.Scheme,
+		Host:     u.hostPort(),
+		Path:     u.Path,
+		RawQuery: u.RawQuery,
+		Fragment: u.Fragment,
+	}
+	if u.User != nil {
+		if u.User.Password != "" {
+			std.User = url.UserPassword(u.User.Username, u.User.Password)
+		} else {
+			std.User = url.User(u.User.Username)
+		}
+	}
+	return std.String()
+}
+
+// hostPort returns the host and the port of the URL as used in a Host header, without the default port of the scheme.
+func (u *URL) hostPort() string {
+	if u.Port != 0 && u.Port != defaultPort(u.Scheme) {
+		return net.JoinHostPort(u.Host, strconv.Itoa(int(u.Port)))
+	}
+	if strings.Contains(u.Host, ":") {
+		return "[" + u.Host + "]"
+	}
+	return u.Host
+}
+
+// requestTarget returns the escaped path and query of the URL as used in a request line.
+func (u *URL) requestTarget() string {
+	target := (&url.URL{Path: u.Path, RawQuery: u.RawQuery}).RequestURI()
+	if !strings.HasPrefix(target, "/") {
+		target = "/" + target
+	}
+	return target
+}
+
 func ParseURL(rawURL string) shepard.Result[URL, error] {
 
 	u, err := url.Parse(rawURL)
@@ -130,13 +170,8 @@ func ParseURL(rawURL string) shepard.Result[URL, error] {
 			return shepard.Err[URL, error](errors.New("invalid port"))
 		}
 		port = uint16(p)
-	} else {
-		switch u.Scheme {
-		case "http":
-			port = 80
-		case "https":
-			port = 443
-		}
+	} else if u.Scheme == "http" || u.Scheme == "https" {
+		port = defaultPort(u.Scheme)
 	}
 
 	password, _ := u.User.Password()
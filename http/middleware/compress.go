@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/num/unit"
+)
+
+// CompressOptions configures response compression.
+type CompressOptions struct {
+	// Level is the compression level, see compress/flate.
+	Level int
+	// MinBytes is the minimal size of buffered bodies to compress, smaller ones aren't worth it.
+	MinBytes unit.ByteSize
+	// ContentTypes are the media types to compress, a trailing "*" matches any subtype, e.g. "text/*".
+	ContentTypes []string
+}
+
+func (o CompressOptions) Default() CompressOptions {
+	return CompressOptions{
+		Level:    gzip.DefaultCompression,
+		MinBytes: unit.KiB,
+		ContentTypes: []string{
+			"text/*",
+			"application/json",
+			"application/problem+json",
+			"application/javascript",
+			"application/xml",
+			"image/svg+xml",
+		},
+	}
+}
+
+// encodings are the supported content codings in order of preference.
+var encodings = []string{"gzip", "deflate"}
+
+// Compress returns a middleware compressing responses with gzip or deflate, whichever is preferred by the Accept-Encoding
+// header of the request.
+//
+// Responses are left as they are if they already have a Content-Encoding, have no body, are partial or their Content-Type
+// isn't listed in opts. Streaming bodies are compressed as they are flushed, unless they have a Content-Length.
+// Strong ETags of compressed responses are made weak, since the compressed bytes may differ between compressions.
+func Compress(opts CompressOptions) http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		encoding := negotiateEncoding(req.Headers.Value("Accept-Encoding"))
+
+		potentialRes := next()
+		if potentialRes.IsErr() || req.Method == http.MethodHead {
+			return potentialRes
+		}
+		res := potentialRes.Unwrap()
+		appendHeader(res.Headers(), "Vary", "Accept-Encoding")
+		if encoding == "" || !compressible(res, opts.ContentTypes) {
+			return potentialRes
+		}
+
+		if stream, streaming := res.Body().(http.StreamingBody); streaming && !stream.Closed() {
+			if res.Headers().Has("Content-Length") {
+				return potentialRes
+			}
+			res.SetBody(compressStream(stream, encoding, opts.Level))
+		} else {
+			data := http.ReadBody(res.Body())
+			body := http.NewBytesBody()
+			if len(data) < int(opts.MinBytes) {
+				body.Write(slice.Init(data...))
+				body.Close()
+				res.SetBody(body)
+				return potentialRes
+			}
+			var buf bytes.Buffer
+			w := newCompressor(&buf, encoding, opts.Level)
+			w.Write(data)
+			w.Close()
+			body.Write(slice.Init(buf.Bytes()...))
+			body.Close()
+			res.SetBody(body)
+			if res.Headers().Has("Content-Length") {
+				res.SetHeader("Content-Length", strconv.Itoa(buf.Len()))
+			}
+		}
+
+		res.SetHeader("Content-Encoding", encoding)
+		if etag := res.Headers().Get("ETag").First(); etag.IsSome() && !strings.HasPrefix(*etag.Unwrap(), "W/") {
+			res.SetHeader("ETag", "W/"+*etag.Unwrap())
+		}
+		return potentialRes
+	}
+}
+
+// negotiateEncoding returns the supported encoding with the highest quality in the Accept-Encoding value, or an
+// empty string if none is acceptable.
+func negotiateEncoding(acceptEncoding string) string {
+	tokens := weightedTokens(acceptEncoding)
+	best, bestQ := "", 0.0
+	for _, encoding := range encodings {
+		q, ok := tokens[encoding]
+		if !ok {
+			q = tokens["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressible returns true if res has a body of one of the content types which isn't encoded yet.
+func compressible(res http.Response[http.Body], contentTypes []string) bool {
+	status := res.StatusCode()
+	if status < http.StatusCodeOk || status == http.StatusCodeNoContent || status == http.StatusCodeNotModified ||
+		status == http.StatusCodePartialContent || res.Headers().Has("Content-Encoding") {
+		return false
+	}
+	mediaType := ""
+	if contentType := res.Headers().ContentType(); contentType.IsSome() {
+		mediaType = contentType.Unwrap().Type
+	}
+	for _, t := range contentTypes {
+		if mediaType == t || (strings.HasSuffix(t, "*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressor is a flushable compressing writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func newCompressor(w io.Writer, encoding string, level int) compressor {
+	if encoding == "deflate" {
+		// "deflate" is the zlib format, see RFC 9110 section 8.4.1.2
+		zw, err := zlib.NewWriterLevel(w, level)
+		if err != nil {
+			zw = zlib.NewWriter(w)
+		}
+		return zw
+	}
+	gw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		gw = gzip.NewWriter(w)
+	}
+	return gw
+}
+
+// compressStream returns a streaming body with the compressed data of src, which is flushed whenever src is flushed.
+func compressStream(src http.StreamingBody, encoding string, level int) http.StreamingBody {
+	dst := http.NewStreamBody()
+	go func() {
+		var buf bytes.Buffer
+		w := newCompressor(&buf, encoding, level)
+		forward := func() {
+			if buf.Len() > 0 {
+				dst.Write(slice.Init(buf.Bytes()...))
+				buf.Reset()
+			}
+			dst.Flush()
+		}
+		for {
+			select {
+			case <-src.Flushed():
+				w.Write(http.ReadBody(src))
+				w.Flush()
+				forward()
+			case <-src.Done():
+				w.Write(http.ReadBody(src))
+				w.Close()
+				forward()
+				dst.Close()
+				return
+			}
+		}
+	}()
+	return dst
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+var largeText = strings.Repeat("shepard guards your data types. ", 100)
+
+func decompress(t *testing.T, encoding string, data []byte) string {
+	t.Helper()
+	var r io.Reader
+	var err error
+	if encoding == "gzip" {
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	} else {
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(text)
+}
+
+func newCompressRouter() *http.Router {
+	r := http.NewRouter().Use(Compress(CompressOptions{}.Default()))
+	r.Route(http.Get("/large", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		res := http.Text(http.StatusCodeOk, largeText)
+		res.SetHeader("ETag", `"v1"`)
+		return res
+	}))
+	r.Route(http.Get("/small", textHandler("small")))
+	r.Route(http.Get("/image", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		res := http.Text(http.StatusCodeOk, largeText)
+		res.SetHeader("Content-Type", "image/png")
+		return res
+	}))
+	r.Route(http.Get("/stream", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		body := http.NewStreamBody()
+		go func() {
+			for _, part := range []string{"first ", "second ", "third"} {
+				body.Write(slice.Init([]byte(part)...))
+				body.Flush()
+				time.Sleep(5 * time.Millisecond)
+			}
+			body.Close()
+		}()
+		res := http.Text(http.StatusCodeOk, "")
+		res.SetBody(body)
+		return res
+	}))
+	return r
+}
+
+func TestCompress(t *testing.T) {
+	assert := assert.New(t)
+	r := newCompressRouter()
+
+	for _, tc := range []struct{ accept, encoding string }{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip;q=0.5", "deflate"},
+		{"br, gzip, deflate", "gzip"},
+		{"*", "gzip"},
+		{"gzip;q=0, *;q=0.1", "deflate"},
+	} {
+		res := serve(r, testhttp.NewRequest(http.MethodGet, "/large", map[string]string{"Accept-Encoding": tc.accept}))
+		assert.Equal(slice.Init(tc.encoding), res.Headers().Get("Content-Encoding"), tc.accept)
+		assert.Equal(slice.Init("Accept-Encoding"), res.Headers().Get("Vary"))
+		assert.Equal(slice.Init(`W/"v1"`), res.Headers().Get("ETag"))
+		data := http.ReadBody(res.Body())
+		assert.Less(len(data), len(largeText))
+		assert.Equal(largeText, decompress(t, tc.encoding, data), tc.accept)
+	}
+
+	for _, tc := range []struct{ path, accept string }{
+		{"/large", ""},
+		{"/large", "br"},
+		{"/large", "identity"},
+		{"/large", "gzip;q=0"},
+		{"/small", "gzip"},
+		{"/image", "gzip"},
+	} {
+		res := serve(r, testhttp.NewRequest(http.MethodGet, tc.path, map[string]string{"Accept-Encoding": tc.accept}))
+		assert.False(res.Headers().Has("Content-Encoding"), tc)
+		assert.Equal(slice.Init("Accept-Encoding"), res.Headers().Get("Vary"))
+		assert.NotEmpty(testhttp.ReadBody(res))
+	}
+
+	res := serve(r, testhttp.NewRequest(http.MethodHead, "/large", map[string]string{"Accept-Encoding": "gzip"}))
+	assert.False(res.Headers().Has("Content-Encoding"))
+}
+
+func TestCompress_Stream(t *testing.T) {
+	assert := assert.New(t)
+	r := newCompressRouter()
+
+	res := serve(r, testhttp.NewRequest(http.MethodGet, "/stream", map[string]string{"Accept-Encoding": "gzip"}))
+	assert.Equal(slice.Init("gzip"), res.Headers().Get("Content-Encoding"))
+	stream, ok := res.Body().(http.StreamingBody)
+	assert.True(ok)
+
+	var data []byte
+	for done := false; !done; {
+		select {
+		case <-stream.Flushed():
+		case <-stream.Done():
+			done = true
+		}
+		data = append(data, http.ReadBody(stream)...)
+	}
+	assert.Equal("first second third", decompress(t, "gzip", data))
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// CORSOptions configures cross-origin resource sharing.
+type CORSOptions struct {
+	// AllowedOrigins are the origins allowed to access resources, e.g. "https://example.com". "*" allows all origins.
+	AllowedOrigins []string
+	// AllowOriginFunc decides whether an origin not listed in AllowedOrigins is allowed.
+	AllowOriginFunc func(origin string) bool
+	// AllowedMethods are the methods allowed for cross-origin requests.
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed for cross-origin requests, case-insensitively. "*" allows all headers.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts of other origins may read.
+	ExposedHeaders []string
+	// AllowCredentials allows cross-origin requests with cookies and authorization.
+	AllowCredentials bool
+	// MaxAge is how long the result of a preflight request may be cached, not sent if 0.
+	MaxAge time.Duration
+}
+
+func (o CORSOptions) Default() CORSOptions {
+	return CORSOptions{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet.String(), http.MethodHead.String(), http.MethodPost.String()},
+		AllowedHeaders: []string{"Accept", "Accept-Language", "Content-Language", "Content-Type", RequestIDHeader},
+	}
+}
+
+// CORS returns a middleware implementing cross-origin resource sharing with opts.
+//
+// Preflight requests, OPTIONS requests with an Access-Control-Request-Method header, are answered with 204 without
+// invoking the handler. Requests of disallowed origins are handled without CORS headers, so browsers block them.
+// Add it to the Router with Use, so preflight requests are answered for all routes.
+func CORS(opts CORSOptions) http.Middleware {
+	allowAll := false
+	origins := map[string]bool{}
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	allowAllHeaders := false
+	headers := map[string]bool{}
+	for _, header := range opts.AllowedHeaders {
+		if header == "*" {
+			allowAllHeaders = true
+		}
+		headers[strings.ToLower(header)] = true
+	}
+	methods := map[string]bool{}
+	for _, method := range opts.AllowedMethods {
+		methods[strings.ToUpper(method)] = true
+	}
+
+	allowed := func(origin string) bool {
+		return allowAll || origins[strings.ToLower(origin)] || (opts.AllowOriginFunc != nil && opts.AllowOriginFunc(origin))
+	}
+	// the origin is echoed unless all origins are allowed without credentials
+	allowOrigin := func(res http.Response[http.Body], origin string) {
+		if allowAll && !opts.AllowCredentials {
+			res.SetHeader("Access-Control-Allow-Origin", "*")
+			return
+		}
+		res.SetHeader("Access-Control-Allow-Origin", origin)
+		appendHeader(res.Headers(), "Vary", "Origin")
+		if opts.AllowCredentials {
+			res.SetHeader("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		origin := req.Headers.Value("Origin")
+		if origin == "" {
+			return next()
+		}
+
+		requestMethod := req.Headers.Value("Access-Control-Request-Method")
+		if req.Method == http.MethodOptions && requestMethod != "" {
+			res := http.NoContent()
+			appendHeader(res.Headers(), "Vary", "Origin")
+			appendHeader(res.Headers(), "Vary", "Access-Control-Request-Method")
+			appendHeader(res.Headers(), "Vary", "Access-Control-Request-Headers")
+			if !allowed(origin) || !methods[strings.ToUpper(requestMethod)] {
+				return shepard.Ok[http.Response[http.Body], error](res)
+			}
+
+			requestHeaders := []string{}
+			for _, header := range req.Headers.List("Access-Control-Request-Headers") {
+				if !allowAllHeaders && !headers[strings.ToLower(header)] {
+					return shepard.Ok[http.Response[http.Body], error](res)
+				}
+				requestHeaders = append(requestHeaders, header)
+			}
+
+			allowOrigin(res, origin)
+			res.SetHeader("Access-Control-Allow-Methods", opts.AllowedMethods...)
+			if len(requestHeaders) > 0 {
+				res.SetHeader("Access-Control-Allow-Headers", requestHeaders...)
+			}
+			if opts.MaxAge > 0 {
+				res.SetHeader("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+			}
+			return shepard.Ok[http.Response[http.Body], error](res)
+		}
+
+		res := next()
+		if res.IsErr() || !allowed(origin) {
+			return res
+		}
+		allowOrigin(res.Unwrap(), origin)
+		if len(opts.ExposedHeaders) > 0 {
+			res.Unwrap().SetHeader("Access-Control-Expose-Headers", opts.ExposedHeaders...)
+		}
+		return res
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSRouter(opts CORSOptions) *http.Router {
+	r := http.NewRouter().Use(CORS(opts))
+	r.Route(http.Get("/users", textHandler("users")))
+	r.Route(http.Put("/users", textHandler("updated")))
+	return r
+}
+
+func TestCORS(t *testing.T) {
+	assert := assert.New(t)
+	r := newCORSRouter(CORSOptions{}.Default())
+
+	// same origin requests aren't touched
+	res := serve(r, testhttp.NewRequest(http.MethodGet, "/users", nil))
+	assert.False(res.Headers().Has("Access-Control-Allow-Origin"))
+
+	res = serve(r, testhttp.NewRequest(http.MethodGet, "/users", map[string]string{"Origin": "https://app.example.com"}))
+	assert.Equal("users", testhttp.ReadBody(res))
+	assert.Equal(slice.Init("*"), res.Headers().Get("Access-Control-Allow-Origin"))
+	assert.False(res.Headers().Has("Access-Control-Allow-Credentials"))
+
+	// preflight
+	res = serve(r, testhttp.NewRequest(http.MethodOptions, "/users", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "content-type, X-Request-ID",
+	}))
+	assert.Equal(http.StatusCodeNoContent, res.StatusCode())
+	assert.Equal(slice.Init("*"), res.Headers().Get("Access-Control-Allow-Origin"))
+	assert.Equal(slice.Init("GET", "HEAD", "POST"), res.Headers().Get("Access-Control-Allow-Methods"))
+	assert.Equal(slice.Init("content-type", "X-Request-ID"), res.Headers().Get("Access-Control-Allow-Headers"))
+	assert.False(res.Headers().Has("Access-Control-Max-Age"))
+
+	// disallowed method and header
+	res = serve(r, testhttp.NewRequest(http.MethodOptions, "/users", map[string]string{
+		"Origin":                        "https://app.example.com",
+		"Access-Control-Request-Method": "PUT",
+	}))
+	assert.Equal(http.StatusCodeNoContent, res.StatusCode())
+	assert.False(res.Headers().Has("Access-Control-Allow-Origin"))
+	res = serve(r, testhttp.NewRequest(http.MethodOptions, "/users", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "Authorization",
+	}))
+	assert.False(res.Headers().Has("Access-Control-Allow-Origin"))
+
+	// plain OPTIONS requests reach the router
+	res = serve(r, testhttp.NewRequest(http.MethodOptions, "/users", map[string]string{"Origin": "https://app.example.com"}))
+	assert.True(res.Headers().Has("Allow"))
+}
+
+func TestCORS_Origins(t *testing.T) {
+	assert := assert.New(t)
+	r := newCORSRouter(CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".preview.example.com")
+		},
+		AllowedMethods:   []string{"GET", "PUT"},
+		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	for _, origin := range []string{"https://app.example.com", "https://APP.example.com", "https://pr-1.preview.example.com"} {
+		res := serve(r, testhttp.NewRequest(http.MethodGet, "/users", map[string]string{"Origin": origin}))
+		assert.Equal(slice.Init(origin), res.Headers().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(slice.Init("true"), res.Headers().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(slice.Init("X-Total-Count"), res.Headers().Get("Access-Control-Expose-Headers"))
+		assert.Equal(slice.Init("Origin"), res.Headers().Get("Vary"))
+	}
+
+	res := serve(r, testhttp.NewRequest(http.MethodGet, "/users", map[string]string{"Origin": "https://evil.example.org"}))
+	assert.Equal("users", testhttp.ReadBody(res))
+	assert.False(res.Headers().Has("Access-Control-Allow-Origin"))
+
+	res = serve(r, testhttp.NewRequest(http.MethodOptions, "/users", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "Authorization",
+	}))
+	assert.Equal(slice.Init("https://app.example.com"), res.Headers().Get("Access-Control-Allow-Origin"))
+	assert.Equal(slice.Init("Authorization"), res.Headers().Get("Access-Control-Allow-Headers"))
+	assert.Equal(slice.Init("600"), res.Headers().Get("Access-Control-Max-Age"))
+	assert.Equal(slice.Init("Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"), res.Headers().Get("Vary"))
+
+	res = serve(r, testhttp.NewRequest(http.MethodOptions, "/users", map[string]string{
+		"Origin":                        "https://evil.example.org",
+		"Access-Control-Request-Method": "GET",
+	}))
+	assert.False(res.Headers().Has("Access-Control-Allow-Origin"))
+}
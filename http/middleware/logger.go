@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// Logger returns a middleware logging every request to logger, slog.Default() if nil, after it was handled.
+//
+// The record has the method, path, status and latency of the request, and the request ID if RequestID runs before.
+// Server errors and errors of the handler are logged at error level, client errors at warn level and the rest at info level.
+func Logger(logger *slog.Logger) http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		start := time.Now()
+		method, path := req.Method.String(), req.URL.Path
+
+		res := next()
+
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("path", path),
+		}
+		level := slog.LevelInfo
+		if res.IsOk() {
+			status := res.Unwrap().StatusCode()
+			attrs = append(attrs, slog.Int("status", int(status)))
+			switch {
+			case status >= http.StatusCodeInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusCodeBadRequest:
+				level = slog.LevelWarn
+			}
+		} else {
+			attrs = append(attrs, slog.Any("error", res.UnwrapErr()))
+			level = slog.LevelError
+		}
+		attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+		if id := RequestIDFromContext(req.Context); id.IsSome() {
+			attrs = append(attrs, slog.String("request_id", id.Unwrap()))
+		}
+
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		ctx := req.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		l.LogAttrs(ctx, level, "request", attrs...)
+		return res
+	}
+}
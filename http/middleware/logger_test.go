@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	r := http.NewRouter().Use(Logger(logger)).Use(RequestID())
+	r.Route(http.Get("/users/:id", textHandler("user")))
+	r.Route(http.Get("/fail", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		return http.Error(http.StatusCodeServiceUnavailable, nil)
+	}))
+	r.Route(http.Get("/err", textHandler("unreachable")).Use(func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		return shepard.Err[http.Response[http.Body], error](errors.New("database down"))
+	}))
+
+	serve(r, testhttp.NewRequest(http.MethodGet, "/users/42", map[string]string{RequestIDHeader: "abc"}))
+	serve(r, testhttp.NewRequest(http.MethodGet, "/missing", nil))
+	serve(r, testhttp.NewRequest(http.MethodGet, "/fail", nil))
+	r.Serve(testhttp.NewRequest(http.MethodGet, "/err", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 4)
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		assert.NoError(json.Unmarshal([]byte(line), &records[i]))
+	}
+
+	assert.Equal("INFO", records[0]["level"])
+	assert.Equal("request", records[0]["msg"])
+	assert.Equal("GET", records[0]["method"])
+	assert.Equal("/users/42", records[0]["path"])
+	assert.Equal(200.0, records[0]["status"])
+	assert.Equal("abc", records[0]["request_id"])
+	assert.Contains(records[0], "latency")
+
+	assert.Equal("WARN", records[1]["level"])
+	assert.Equal(404.0, records[1]["status"])
+
+	assert.Equal("ERROR", records[2]["level"])
+	assert.Equal(503.0, records[2]["status"])
+
+	assert.Equal("ERROR", records[3]["level"])
+	assert.Equal("database down", records[3]["error"])
+	assert.NotContains(records[3], "status")
+}
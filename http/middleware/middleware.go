@@ -0,0 +1,44 @@
+// Package middleware implements common http.Middleware: access logging, panic recovery, request IDs, CORS and compression.
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/marlaone/shepard/http"
+)
+
+// appendHeader adds value to the list of the header key, unless it is already present.
+func appendHeader(h *http.Headers, key string, value string) {
+	for _, v := range h.List(key) {
+		if strings.EqualFold(v, value) {
+			return
+		}
+	}
+	h.Add(key, value)
+}
+
+// weightedTokens returns the tokens of a header value like Accept-Encoding with their quality, e.g. {"gzip": 1, "br": 0.5}.
+func weightedTokens(value string) map[string]float64 {
+	tokens := map[string]float64{}
+	for _, part := range strings.Split(value, ",") {
+		params := strings.Split(part, ";")
+		token := strings.ToLower(strings.TrimSpace(params[0]))
+		if token == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		tokens[token] = q
+	}
+	return tokens
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/marlaone/shepard/http"
+)
+
+func serve(r *http.Router, req *http.Request[http.RequestBody]) http.Response[http.Body] {
+	return r.Serve(req).Unwrap()
+}
+
+func textHandler(text string) http.Handler {
+	return func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		return http.Text(http.StatusCodeOk, text)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// Recover returns a middleware turning panics of the following middleware and the handler, e.g. of an Unwrap of a
+// shepard.Err, into 500 problem responses. The panic and its stack are logged to logger, slog.Default() if nil.
+//
+// Panics of goroutines started by the handler, e.g. writing a streaming body, aren't recovered.
+func Recover(logger *slog.Logger) http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) (res shepard.Result[http.Response[http.Body], error]) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			ctx := req.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			attrs := []slog.Attr{
+				slog.String("method", req.Method.String()),
+				slog.String("path", req.URL.Path),
+				slog.String("panic", fmt.Sprint(recovered)),
+				slog.String("stack", string(debug.Stack())),
+			}
+			if id := RequestIDFromContext(req.Context); id.IsSome() {
+				attrs = append(attrs, slog.String("request_id", id.Unwrap()))
+			}
+			l.LogAttrs(ctx, slog.LevelError, "panic recovered", attrs...)
+
+			res = shepard.Ok[http.Response[http.Body], error](http.Error(http.StatusCodeInternalServerError, nil))
+		}()
+		return next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	r := http.NewRouter().Use(RequestID()).Use(Recover(logger))
+	r.Route(http.Get("/panic", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		shepard.Err[int, error](errors.New("boom")).Unwrap()
+		return http.NoContent()
+	}))
+	r.Route(http.Get("/ok", textHandler("ok")))
+
+	res := serve(r, testhttp.NewRequest(http.MethodGet, "/panic", map[string]string{RequestIDHeader: "abc"}))
+	assert.Equal(http.StatusCodeInternalServerError, res.StatusCode())
+	assert.JSONEq(`{"type": "about:blank", "title": "Internal Server Error", "status": 500}`, testhttp.ReadBody(res))
+	assert.Contains(buf.String(), "panic recovered")
+	assert.Contains(buf.String(), "path=/panic")
+	assert.Contains(buf.String(), "request_id=abc")
+	assert.Contains(buf.String(), "recover_test.go")
+
+	buf.Reset()
+	assert.Equal("ok", testhttp.ReadBody(serve(r, testhttp.NewRequest(http.MethodGet, "/ok", nil))))
+	assert.Empty(buf.String())
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// RequestIDHeader is the header carrying the ID of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximal length of a request ID sent by a client, longer ones are replaced.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID returns a middleware propagating the X-Request-ID header of requests, e.g. set by a load balancer,
+// or generating a random ID if it's missing or invalid.
+//
+// The ID is stored in the context of the request, see RequestIDFromContext, and set as X-Request-ID header of the
+// request and the response.
+func RequestID() http.Middleware {
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		id := req.Headers.Value(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		req.Headers.Set(RequestIDHeader, id)
+		req.Context = ContextWithRequestID(req.Context, id)
+
+		res := next()
+		if res.IsOk() {
+			res.Unwrap().SetHeader(RequestIDHeader, id)
+		}
+		return res
+	}
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by RequestID.
+func RequestIDFromContext(ctx context.Context) shepard.Option[string] {
+	if ctx == nil {
+		return shepard.None[string]()
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return shepard.Some(id)
+	}
+	return shepard.None[string]()
+}
+
+// validRequestID returns true for non-empty IDs of printable ASCII characters, which are safe to log and echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	assert := assert.New(t)
+
+	var seen string
+	r := http.NewRouter().Use(RequestID()).Route(http.Get("/", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		seen = RequestIDFromContext(req.Context).Unwrap()
+		return http.NoContent()
+	}))
+
+	res := serve(r, testhttp.NewRequest(http.MethodGet, "/", map[string]string{"x-request-id": "lb-1234"}))
+	assert.Equal("lb-1234", seen)
+	assert.Equal(slice.Init("lb-1234"), res.Headers().Get(RequestIDHeader))
+
+	for _, id := range []string{"", "with space", strings.Repeat("a", 129)} {
+		res = serve(r, testhttp.NewRequest(http.MethodGet, "/", map[string]string{RequestIDHeader: id}))
+		assert.Len(seen, 32, id)
+		assert.NotEqual(id, seen)
+		assert.Equal(slice.Init(seen), res.Headers().Get(RequestIDHeader))
+	}
+
+	previous := seen
+	serve(r, testhttp.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEqual(previous, seen)
+
+	assert.True(RequestIDFromContext(context.Background()).IsNone())
+	assert.Equal("id", RequestIDFromContext(ContextWithRequestID(nil, "id")).Unwrap())
+}
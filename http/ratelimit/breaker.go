@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// BreakerOptions configures a circuit breaker.
+type BreakerOptions struct {
+	// Failures is the number of consecutive failed requests opening the circuit.
+	Failures int
+	// OpenFor is how long the circuit stays open before a probe request is let through.
+	OpenFor time.Duration
+	// IsFailure returns true for responses counting as failures, 5xx responses if nil. Errors always count as failures.
+	IsFailure func(res http.Response[http.Body]) bool
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+func (o BreakerOptions) Default() BreakerOptions {
+	return BreakerOptions{
+		Failures: 5,
+		OpenFor:  30 * time.Second,
+	}
+}
+
+type breakerState int
+
+const (
+	// breakerClosed lets all requests through
+	breakerClosed breakerState = iota
+	// breakerOpen rejects all requests until OpenFor elapsed
+	breakerOpen
+	// breakerHalfOpen rejects all requests while a single probe request is handled, which closes the circuit if it
+	// succeeds
+	breakerHalfOpen
+)
+
+// breaker is the state of a circuit breaker.
+type breaker struct {
+	opts BreakerOptions
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow returns whether a request may be handled and whether it's the probe of a half-open circuit. Rejected requests
+// get the time until the next probe.
+func (b *breaker) allow(now time.Time) (bool, bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if wait := b.openedAt.Add(b.opts.OpenFor).Sub(now); wait > 0 {
+			return false, false, wait
+		}
+		b.state = breakerHalfOpen
+		return true, true, 0
+	case breakerHalfOpen:
+		// the circuit opens again for OpenFor if the probe fails
+		return false, false, b.opts.OpenFor
+	default:
+		return true, false, 0
+	}
+}
+
+// record counts the outcome of a request let through by allow.
+func (b *breaker) record(probe bool, failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		if failed {
+			b.state, b.openedAt = breakerOpen, now
+			return
+		}
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+	// requests started before the circuit opened don't change it anymore
+	if b.state != breakerClosed {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	if b.failures++; b.failures >= b.opts.Failures {
+		b.state, b.openedAt = breakerOpen, now
+	}
+}
+
+// Breaker returns a middleware protecting the following middleware and the handler with a circuit breaker, e.g. to
+// fail fast while a dependency is down.
+//
+// After Failures consecutive failures the circuit opens and requests are answered with 503 and a Retry-After header.
+// Once OpenFor elapsed, the circuit is half-open: a single probe request is let through while others are still
+// rejected. It closes the circuit if it succeeds and opens it again otherwise. The state is kept in memory, it's not
+// shared between the instances of a service.
+func Breaker(opts BreakerOptions) http.Middleware {
+	if opts.Failures <= 0 || opts.OpenFor <= 0 {
+		panic(fmt.Sprintf("ratelimit: invalid breaker of %d failures open for %s", opts.Failures, opts.OpenFor))
+	}
+	isFailure := opts.IsFailure
+	if isFailure == nil {
+		isFailure = func(res http.Response[http.Body]) bool {
+			return res.StatusCode() >= 500
+		}
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	b := &breaker{opts: opts}
+
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		allowed, probe, retryAfter := b.allow(now())
+		if !allowed {
+			res := http.Error(http.StatusCodeServiceUnavailable, nil)
+			res.SetHeader("Retry-After", strconv.Itoa(max(ceilSeconds(retryAfter), 1)))
+			return shepard.Ok[http.Response[http.Body], error](res)
+		}
+
+		// a panicking handler counts as failure as well
+		failed := true
+		defer func() {
+			b.record(probe, failed, now())
+		}()
+		res := next()
+		failed = res.IsErr() || isFailure(res.Unwrap())
+		return res
+	}
+}
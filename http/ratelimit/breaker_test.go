@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	status := http.StatusCodeInternalServerError
+	calls := 0
+	var started, block chan struct{}
+	r := http.NewRouter().Use(Breaker(BreakerOptions{Failures: 3, OpenFor: 10 * time.Second, Now: func() time.Time { return now }}))
+	r.Route(http.Get("/", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		calls++
+		if block != nil {
+			started <- struct{}{}
+			<-block
+		}
+		return http.Text(status, "")
+	}))
+	serve := func() http.Response[http.Body] {
+		return r.Serve(newTestRequest("", nil)).Unwrap()
+	}
+
+	// successes reset the count of consecutive failures
+	serve()
+	serve()
+	status = http.StatusCodeOk
+	serve()
+	status = http.StatusCodeBadGateway
+	serve()
+	serve()
+	assert.Equal(5, calls)
+
+	// the third consecutive failure opens the circuit
+	assert.Equal(http.StatusCodeBadGateway, serve().StatusCode())
+	res := serve()
+	assert.Equal(http.StatusCodeServiceUnavailable, res.StatusCode())
+	assert.Equal(slice.Init("10"), res.Headers().Get("Retry-After"))
+	assert.Equal(6, calls)
+
+	now = now.Add(4 * time.Second)
+	assert.Equal(slice.Init("6"), serve().Headers().Get("Retry-After"))
+
+	// a failed probe opens it again
+	now = now.Add(6 * time.Second)
+	assert.Equal(http.StatusCodeBadGateway, serve().StatusCode())
+	assert.Equal(7, calls)
+	now = now.Add(9 * time.Second)
+	assert.Equal(http.StatusCodeServiceUnavailable, serve().StatusCode())
+	assert.Equal(7, calls)
+
+	// other requests are rejected while the probe is handled
+	now = now.Add(time.Second)
+	status = http.StatusCodeOk
+	started, block = make(chan struct{}), make(chan struct{})
+	probed := make(chan http.Response[http.Body])
+	go func() {
+		probed <- serve()
+	}()
+	<-started
+	res = serve()
+	assert.Equal(http.StatusCodeServiceUnavailable, res.StatusCode())
+	assert.Equal(slice.Init("10"), res.Headers().Get("Retry-After"))
+	close(block)
+	assert.Equal(http.StatusCodeOk, (<-probed).StatusCode())
+	block = nil
+
+	// a successful probe closes the circuit
+	assert.Equal(http.StatusCodeOk, serve().StatusCode())
+	assert.Equal(9, calls)
+
+	assert.Panics(func() { Breaker(BreakerOptions{}) })
+}
+
+func TestBreaker_IsFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	r := http.NewRouter().Use(Breaker(BreakerOptions{
+		Failures:  1,
+		OpenFor:   time.Minute,
+		IsFailure: func(res http.Response[http.Body]) bool { return res.StatusCode() == http.StatusCodeTooManyRequests },
+	}))
+	r.Route(http.Get("/", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		return http.Error(http.StatusCodeTooManyRequests, nil)
+	}))
+	r.Route(http.Get("/error", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		return http.Error(http.StatusCodeInternalServerError, nil)
+	}))
+
+	assert.Equal(http.StatusCodeInternalServerError, r.Serve(testhttp.NewRequest(http.MethodGet, "/error", nil)).Unwrap().StatusCode())
+	assert.Equal(http.StatusCodeTooManyRequests, r.Serve(newTestRequest("", nil)).Unwrap().StatusCode())
+	assert.Equal(http.StatusCodeServiceUnavailable, r.Serve(testhttp.NewRequest(http.MethodGet, "/error", nil)).Unwrap().StatusCode())
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// BulkheadOptions configures a bulkhead.
+type BulkheadOptions struct {
+	// MaxConcurrent is the number of requests handled at once.
+	MaxConcurrent int
+	// MaxWait is how long a request waits for a slot before it's rejected, it's rejected at once if 0.
+	MaxWait time.Duration
+	// RetryAfter is sent in the Retry-After header of rejections, if it isn't 0.
+	RetryAfter time.Duration
+}
+
+func (o BulkheadOptions) Default() BulkheadOptions {
+	return BulkheadOptions{
+		MaxConcurrent: 100,
+		RetryAfter:    time.Second,
+	}
+}
+
+// Bulkhead returns a middleware limiting the number of requests handled concurrently by the following middleware and
+// the handler, e.g. to protect a slow dependency. Requests without a free slot are answered with 503.
+//
+// A slot is released when the handler returns, the body of a streaming response may still be written afterwards.
+func Bulkhead(opts BulkheadOptions) http.Middleware {
+	if opts.MaxConcurrent <= 0 {
+		panic(fmt.Sprintf("ratelimit: invalid bulkhead size %d", opts.MaxConcurrent))
+	}
+	slots := make(chan struct{}, opts.MaxConcurrent)
+
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		if !acquire(slots, req, opts.MaxWait) {
+			res := http.Error(http.StatusCodeServiceUnavailable, nil)
+			if opts.RetryAfter > 0 {
+				res.SetHeader("Retry-After", strconv.Itoa(ceilSeconds(opts.RetryAfter)))
+			}
+			return shepard.Ok[http.Response[http.Body], error](res)
+		}
+		defer func() {
+			<-slots
+		}()
+		return next()
+	}
+}
+
+// acquire takes a slot, waiting up to maxWait or until the request is canceled.
+func acquire(slots chan struct{}, req *http.Request[http.RequestBody], maxWait time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	if maxWait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	var done <-chan struct{}
+	if req.Context != nil {
+		done = req.Context.Done()
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkhead(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	r := http.NewRouter().Use(Bulkhead(BulkheadOptions{MaxConcurrent: 2, MaxWait: 20 * time.Millisecond, RetryAfter: 2 * time.Second}))
+	r.Route(http.Get("/", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		started <- struct{}{}
+		<-release
+		return http.NoContent()
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Serve(newTestRequest("", nil))
+		}()
+	}
+	<-started
+	<-started
+
+	// both slots are taken
+	start := time.Now()
+	res := r.Serve(newTestRequest("", nil)).Unwrap()
+	assert.Equal(http.StatusCodeServiceUnavailable, res.StatusCode())
+	assert.Equal(slice.Init("2"), res.Headers().Get("Retry-After"))
+	assert.GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+
+	// canceled requests stop waiting
+	req := newTestRequest("", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req.Context = ctx
+	assert.Equal(http.StatusCodeServiceUnavailable, r.Serve(req).Unwrap().StatusCode())
+
+	// released slots are available again
+	close(release)
+	wg.Wait()
+	assert.Equal(http.StatusCodeNoContent, r.Serve(newTestRequest("", nil)).Unwrap().StatusCode())
+	<-started
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"net"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/http/auth"
+)
+
+// KeyFunc returns the key a request is limited by, or shepard.None if the request isn't limited.
+type KeyFunc func(req *http.Request[http.RequestBody]) shepard.Option[string]
+
+// ByIP limits requests by the IP address of the client connection.
+//
+// Behind a reverse proxy all requests come from the proxy, use ByHeader with a header set by the proxy then,
+// e.g. "X-Real-IP".
+func ByIP() KeyFunc {
+	return func(req *http.Request[http.RequestBody]) shepard.Option[string] {
+		if req.RemoteAddr == "" {
+			return shepard.None[string]()
+		}
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		return shepard.Some("ip:" + host)
+	}
+}
+
+// ByHeader limits requests by the value of the header name. Requests without the header aren't limited.
+func ByHeader(name string) KeyFunc {
+	return func(req *http.Request[http.RequestBody]) shepard.Option[string] {
+		value := req.Headers.Value(name)
+		if value == "" {
+			return shepard.None[string]()
+		}
+		return shepard.Some("header:" + strings.ToLower(name) + ":" + value)
+	}
+}
+
+// ByPrincipal limits requests by the principal authenticated by a middleware of package auth, which has to run before.
+// Requests without principal aren't limited.
+func ByPrincipal() KeyFunc {
+	return func(req *http.Request[http.RequestBody]) shepard.Option[string] {
+		principal := auth.PrincipalFromContext(req.Context)
+		if principal.IsNone() {
+			return shepard.None[string]()
+		}
+		return shepard.Some("principal:" + principal.Unwrap().Scheme + ":" + principal.Unwrap().Subject)
+	}
+}
@@ -0,0 +1,171 @@
+// Package ratelimit implements rate limiting middleware with token buckets and sliding windows, a bulkhead limiting
+// concurrent requests and a circuit breaker rejecting requests while the handler keeps failing.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// Limit is the number of requests allowed per period.
+type Limit struct {
+	// Requests is the number of requests per Period.
+	Requests int
+	// Period is the period of the limit.
+	Period time.Duration
+	// Burst is the size of a token bucket, the number of requests allowed at once. Requests if 0.
+	// Sliding windows ignore it.
+	Burst int
+}
+
+// PerSecond returns a limit of n requests per second.
+func PerSecond(n int) Limit {
+	return Limit{Requests: n, Period: time.Second}
+}
+
+// PerMinute returns a limit of n requests per minute.
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute}
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// rate returns the number of tokens added to a token bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// policy returns the limit in the format of the RateLimit-Policy header, e.g. "100;w=60".
+func (l Limit) policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int(math.Ceil(l.Period.Seconds())))
+}
+
+// Decision is the result of counting a request.
+type Decision struct {
+	// Allowed is true if the request is within the limit.
+	Allowed bool
+	// Limit is the number of requests allowed at once.
+	Limit int
+	// Remaining is the number of requests still allowed.
+	Remaining int
+	// Reset is the time until the full limit is available again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, if the request isn't allowed.
+	RetryAfter time.Duration
+}
+
+// Options configures a rate limiting middleware.
+type Options struct {
+	// Limit is the limit per key.
+	Limit Limit
+	// Key returns the key whose limit a request counts against, e.g. ByIP. Requests without key aren't limited.
+	Key KeyFunc
+	// Store keeps the state of the limiter, a new MemoryStore if nil.
+	Store Store
+	// Name separates the keys of several limiters sharing a store.
+	Name string
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+func (o Options) Default() Options {
+	return Options{
+		Limit: PerSecond(10),
+		Key:   ByIP(),
+	}
+}
+
+// TokenBucket returns a middleware limiting requests per key with a token bucket: bursts of Limit.Burst requests are
+// allowed, after which Limit.Requests requests per Limit.Period are allowed.
+//
+// Responses have RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers. Requests exceeding
+// the limit are answered with 429 and a Retry-After header.
+func TokenBucket(opts Options) http.Middleware {
+	return limiter(opts, Store.TakeToken)
+}
+
+// SlidingWindow returns a middleware limiting requests per key to Limit.Requests in any Limit.Period, approximated by
+// weighting the requests of the previous fixed window.
+//
+// Responses have the headers of TokenBucket.
+func SlidingWindow(opts Options) http.Middleware {
+	return limiter(opts, Store.AddToWindow)
+}
+
+// counter counts a request for a key in a store, see Store.
+type counter func(s Store, ctx context.Context, key string, limit Limit, now time.Time) shepard.Result[Decision, error]
+
+func limiter(opts Options, count counter) http.Middleware {
+	if opts.Limit.Requests <= 0 || opts.Limit.Period <= 0 {
+		panic(fmt.Sprintf("ratelimit: invalid limit %d per %s", opts.Limit.Requests, opts.Limit.Period))
+	}
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	keyFunc := opts.Key
+	if keyFunc == nil {
+		keyFunc = ByIP()
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		key := keyFunc(req)
+		if key.IsNone() {
+			return next()
+		}
+		ctx := req.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		decision := count(store, ctx, opts.Name+":"+key.Unwrap(), opts.Limit, now())
+		if decision.IsErr() {
+			return shepard.Err[http.Response[http.Body], error](fmt.Errorf("[ratelimit.limiter] count request failed: %w", decision.UnwrapErr()))
+		}
+		d := decision.Unwrap()
+
+		if !d.Allowed {
+			res := http.Error(http.StatusCodeTooManyRequests, nil)
+			setHeaders(res, opts.Limit, d)
+			res.SetHeader("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+			return shepard.Ok[http.Response[http.Body], error](res)
+		}
+
+		res := next()
+		if res.IsOk() {
+			setHeaders(res.Unwrap(), opts.Limit, d)
+		}
+		return res
+	}
+}
+
+// setHeaders sets the RateLimit headers of the IETF draft "RateLimit header fields for HTTP".
+func setHeaders(res http.Response[http.Body], limit Limit, d Decision) {
+	res.SetHeader("RateLimit-Limit", strconv.Itoa(d.Limit))
+	res.SetHeader("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	res.SetHeader("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+	res.SetHeader("RateLimit-Policy", limit.policy())
+}
+
+// ceilSeconds returns d in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
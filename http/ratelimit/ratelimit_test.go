@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/http/auth"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRequest returns a GET request for "/" from remoteAddr with headers.
+func newTestRequest(remoteAddr string, headers map[string]string) *http.Request[http.RequestBody] {
+	req := testhttp.NewRequest(http.MethodGet, "/", headers)
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func newLimitedRouter(mw ...http.Middleware) *http.Router {
+	r := http.NewRouter()
+	for _, m := range mw {
+		r.Use(m)
+	}
+	return r.Route(http.Get("/", func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		return http.Text(http.StatusCodeOk, "ok")
+	}))
+}
+
+func TestTokenBucket(t *testing.T) {
+	assert := assert.New(t)
+
+	now := testStart
+	opts := Options{}.Default()
+	opts.Limit = Limit{Requests: 1, Period: 10 * time.Second, Burst: 2}
+	opts.Now = func() time.Time { return now }
+	r := newLimitedRouter(TokenBucket(opts))
+
+	res := r.Serve(newTestRequest("192.0.2.1:1234", nil)).Unwrap()
+	assert.Equal(http.StatusCodeOk, res.StatusCode())
+	assert.Equal(slice.Init("2"), res.Headers().Get("RateLimit-Limit"))
+	assert.Equal(slice.Init("1"), res.Headers().Get("RateLimit-Remaining"))
+	assert.Equal(slice.Init("10"), res.Headers().Get("RateLimit-Reset"))
+	assert.Equal(slice.Init("1;w=10"), res.Headers().Get("RateLimit-Policy"))
+
+	// the port doesn't matter
+	res = r.Serve(newTestRequest("192.0.2.1:5678", nil)).Unwrap()
+	assert.Equal(http.StatusCodeOk, res.StatusCode())
+	res = r.Serve(newTestRequest("192.0.2.1:1234", nil)).Unwrap()
+	assert.Equal(http.StatusCodeTooManyRequests, res.StatusCode())
+	assert.Equal(slice.Init("10"), res.Headers().Get("Retry-After"))
+	assert.Equal(slice.Init("0"), res.Headers().Get("RateLimit-Remaining"))
+	assert.Equal(slice.Init(http.ContentTypeProblemJSON), res.Headers().Get("Content-Type"))
+
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("[2001:db8::1]:1234", nil)).Unwrap().StatusCode())
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("192.0.2.1:1234", nil)).Unwrap().StatusCode())
+
+	// requests without key aren't limited
+	for i := 0; i < 5; i++ {
+		assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", nil)).Unwrap().StatusCode())
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewMemoryStore()
+	opts := Options{Limit: PerMinute(2), Key: ByHeader("X-Tenant"), Store: store, Now: func() time.Time { return testStart }}
+	r := newLimitedRouter(SlidingWindow(opts))
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", map[string]string{"x-tenant": "acme"})).Unwrap().StatusCode())
+	}
+	res := r.Serve(newTestRequest("", map[string]string{"X-Tenant": "acme"})).Unwrap()
+	assert.Equal(http.StatusCodeTooManyRequests, res.StatusCode())
+	assert.Equal(slice.Init("2;w=60"), res.Headers().Get("RateLimit-Policy"))
+	assert.Equal(slice.Init("90"), res.Headers().Get("Retry-After"))
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", map[string]string{"X-Tenant": "other"})).Unwrap().StatusCode())
+
+	// limiters with another name don't share the counts
+	opts.Name = "uploads"
+	r = newLimitedRouter(SlidingWindow(opts))
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", map[string]string{"X-Tenant": "acme"})).Unwrap().StatusCode())
+}
+
+func TestByPrincipal(t *testing.T) {
+	assert := assert.New(t)
+
+	authenticate := func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		if user := req.Headers.Get("User").First(); user.IsSome() {
+			req.Context = auth.ContextWithPrincipal(req.Context, auth.Principal{Subject: *user.Unwrap(), Scheme: auth.SchemeBasic})
+		}
+		return next()
+	}
+	r := newLimitedRouter(authenticate, TokenBucket(Options{Limit: PerMinute(1), Key: ByPrincipal()}))
+
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", map[string]string{"User": "alice"})).Unwrap().StatusCode())
+	assert.Equal(http.StatusCodeTooManyRequests, r.Serve(newTestRequest("", map[string]string{"User": "alice"})).Unwrap().StatusCode())
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", map[string]string{"User": "bob"})).Unwrap().StatusCode())
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", nil)).Unwrap().StatusCode())
+	assert.Equal(http.StatusCodeOk, r.Serve(newTestRequest("", nil)).Unwrap().StatusCode())
+}
+
+type failingStore struct{}
+
+func (failingStore) TakeToken(context.Context, string, Limit, time.Time) shepard.Result[Decision, error] {
+	return shepard.Err[Decision, error](errors.New("connection refused"))
+}
+
+func (failingStore) AddToWindow(context.Context, string, Limit, time.Time) shepard.Result[Decision, error] {
+	return shepard.Err[Decision, error](errors.New("connection refused"))
+}
+
+func TestLimiter_StoreError(t *testing.T) {
+	r := newLimitedRouter(TokenBucket(Options{Limit: PerSecond(1), Key: ByIP(), Store: failingStore{}}))
+	res := r.Serve(newTestRequest("192.0.2.1:1234", nil))
+	assert.ErrorContains(t, res.UnwrapErr(), "connection refused")
+
+	assert.Panics(t, func() { TokenBucket(Options{}) })
+}
@@ -0,0 +1,185 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
+)
+
+// Store keeps the state of the rate limiters, e.g. in memory or in Redis to share it between instances.
+//
+// Both methods count a request for key and must be atomic per key.
+type Store interface {
+	// TakeToken takes a token from the token bucket of key, which holds limit.Burst tokens and is refilled with
+	// limit.Requests tokens per limit.Period.
+	TakeToken(ctx context.Context, key string, limit Limit, now time.Time) shepard.Result[Decision, error]
+	// AddToWindow counts a request in the sliding window of key, which allows limit.Requests requests per limit.Period.
+	AddToWindow(ctx context.Context, key string, limit Limit, now time.Time) shepard.Result[Decision, error]
+}
+
+// sweepInterval is the minimal interval between removals of idle state by a MemoryStore.
+const sweepInterval = time.Minute
+
+// tokenBucket is the state of a token bucket.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	// idle is the time the bucket is full again, its state can be dropped after it
+	idle time.Time
+}
+
+// slidingWindow is the state of a sliding window counter.
+type slidingWindow struct {
+	start    time.Time
+	previous int
+	current  int
+	idle     time.Time
+}
+
+// MemoryStore is a Store keeping the state in memory. Idle state is removed periodically.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   hashmap.HashMap[string, tokenBucket]
+	windows   hashmap.HashMap[string, slidingWindow]
+	lastSweep time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: hashmap.New[string, tokenBucket](),
+		windows: hashmap.New[string, slidingWindow](),
+	}
+}
+
+// Len returns the number of keys with state.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buckets.Len() + s.windows.Len()
+}
+
+func (s *MemoryStore) TakeToken(_ context.Context, key string, limit Limit, now time.Time) shepard.Result[Decision, error] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	burst := float64(limit.burst())
+	rate := limit.rate()
+	bucket := tokenBucket{tokens: burst, updated: now}
+	if existing := s.buckets.Get(key); existing.IsSome() {
+		bucket = *existing.Unwrap()
+		elapsed := now.Sub(bucket.updated).Seconds()
+		if elapsed > 0 {
+			bucket.tokens = math.Min(burst, bucket.tokens+elapsed*rate)
+			bucket.updated = now
+		}
+	}
+
+	decision := Decision{Limit: limit.burst()}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - bucket.tokens) / rate)
+	}
+	decision.Remaining = int(bucket.tokens)
+	decision.Reset = seconds((burst - bucket.tokens) / rate)
+	bucket.idle = now.Add(decision.Reset)
+	s.buckets.Insert(key, bucket)
+	return shepard.Ok[Decision, error](decision)
+}
+
+func (s *MemoryStore) AddToWindow(_ context.Context, key string, limit Limit, now time.Time) shepard.Result[Decision, error] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	period := limit.Period
+	start := now.Truncate(period)
+	window := slidingWindow{start: start}
+	if existing := s.windows.Get(key); existing.IsSome() {
+		window = *existing.Unwrap()
+		switch {
+		case window.start.Equal(start):
+		case window.start.Add(period).Equal(start):
+			window = slidingWindow{start: start, previous: window.current}
+		default:
+			window = slidingWindow{start: start}
+		}
+	}
+
+	decision := Decision{Limit: limit.Requests, Reset: start.Add(period).Sub(now)}
+	elapsed := now.Sub(start)
+	if window.count(elapsed, period)+1 <= float64(limit.Requests) {
+		window.current++
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = window.retryAfter(elapsed, period, limit.Requests)
+	}
+	decision.Remaining = int(math.Max(0, math.Floor(float64(limit.Requests)-window.count(elapsed, period))))
+	// the window influences the count until the end of the following window
+	window.idle = start.Add(2 * period)
+	s.windows.Insert(key, window)
+	return shepard.Ok[Decision, error](decision)
+}
+
+// count returns the weighted number of requests in the window ending elapsed after the start of the current window.
+func (w slidingWindow) count(elapsed, period time.Duration) float64 {
+	weight := 1 - float64(elapsed)/float64(period)
+	return float64(w.previous)*weight + float64(w.current)
+}
+
+// retryAfter returns how long it takes until another request fits into the window.
+func (w slidingWindow) retryAfter(elapsed, period time.Duration, limit int) time.Duration {
+	free := float64(limit - 1 - w.current)
+	if free >= 0 && w.previous > 0 {
+		// the weight of the previous window decreases enough during the current one
+		at := (1 - free/float64(w.previous)) * float64(period)
+		return time.Duration(math.Ceil(at)) - elapsed
+	}
+	// the current window becomes the previous one, whose weight has to decrease enough
+	at := 0.0
+	if float64(w.current) > float64(limit-1) {
+		at = (1 - float64(limit-1)/float64(w.current)) * float64(period)
+	}
+	return period - elapsed + time.Duration(math.Ceil(at))
+}
+
+// sweep removes the state of keys which are idle at now, at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	idleBuckets := []string{}
+	s.buckets.Iter().Foreach(func(_ int, pair hashmap.Pair[*string, *tokenBucket]) {
+		if !now.Before(pair.Value.idle) {
+			idleBuckets = append(idleBuckets, *pair.Key)
+		}
+	})
+	for _, key := range idleBuckets {
+		s.buckets.Remove(key)
+	}
+
+	idleWindows := []string{}
+	s.windows.Iter().Foreach(func(_ int, pair hashmap.Pair[*string, *slidingWindow]) {
+		if !now.Before(pair.Value.idle) {
+			idleWindows = append(idleWindows, *pair.Key)
+		}
+	})
+	for _, key := range idleWindows {
+		s.windows.Remove(key)
+	}
+}
+
+// seconds converts a number of seconds to a duration, rounded up so waiting for it is never too short.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
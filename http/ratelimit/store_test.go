@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMemoryStore_TakeToken(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 3}
+
+	// the burst is available at once
+	for i := 2; i >= 0; i-- {
+		d := s.TakeToken(ctx, "a", limit, testStart).Unwrap()
+		assert.True(d.Allowed)
+		assert.Equal(3, d.Limit)
+		assert.Equal(i, d.Remaining)
+	}
+	d := s.TakeToken(ctx, "a", limit, testStart).Unwrap()
+	assert.False(d.Allowed)
+	assert.Equal(0, d.Remaining)
+	assert.Equal(500*time.Millisecond, d.RetryAfter)
+	assert.Equal(1500*time.Millisecond, d.Reset)
+
+	// other keys have their own bucket
+	assert.True(s.TakeToken(ctx, "b", limit, testStart).Unwrap().Allowed)
+
+	// two tokens are refilled per second
+	d = s.TakeToken(ctx, "a", limit, testStart.Add(500*time.Millisecond)).Unwrap()
+	assert.True(d.Allowed)
+	assert.False(s.TakeToken(ctx, "a", limit, testStart.Add(500*time.Millisecond)).Unwrap().Allowed)
+	d = s.TakeToken(ctx, "a", limit, testStart.Add(time.Hour)).Unwrap()
+	assert.True(d.Allowed)
+	assert.Equal(2, d.Remaining)
+
+	// idle buckets are removed, "b" is full again
+	assert.Equal(1, s.Len())
+	s.TakeToken(ctx, "c", limit, testStart.Add(2*time.Hour))
+	assert.Equal(1, s.Len())
+}
+
+func TestMemoryStore_AddToWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMemoryStore()
+	ctx := context.Background()
+	limit := PerMinute(10)
+
+	for i := 9; i >= 0; i-- {
+		d := s.AddToWindow(ctx, "a", limit, testStart.Add(30*time.Second)).Unwrap()
+		assert.True(d.Allowed)
+		assert.Equal(i, d.Remaining)
+		assert.Equal(30*time.Second, d.Reset)
+	}
+	d := s.AddToWindow(ctx, "a", limit, testStart.Add(30*time.Second)).Unwrap()
+	assert.False(d.Allowed)
+	// the ten requests weigh less than nine after 6s of the next window
+	assert.Equal(36*time.Second, d.RetryAfter)
+
+	// the requests of the previous window are weighted by the overlap of the sliding window
+	d = s.AddToWindow(ctx, "a", limit, testStart.Add(time.Minute+6*time.Second)).Unwrap()
+	assert.True(d.Allowed)
+	assert.Equal(0, d.Remaining)
+	d = s.AddToWindow(ctx, "a", limit, testStart.Add(time.Minute+6*time.Second)).Unwrap()
+	assert.False(d.Allowed)
+	// the previous window weighs 10*(1-t/60), there's room for a second request if it weighs at most 8
+	assert.Equal(6*time.Second, d.RetryAfter)
+	assert.True(s.AddToWindow(ctx, "a", limit, testStart.Add(time.Minute+12*time.Second)).Unwrap().Allowed)
+
+	// windows older than the previous one don't count
+	d = s.AddToWindow(ctx, "a", limit, testStart.Add(10*time.Minute)).Unwrap()
+	assert.True(d.Allowed)
+	assert.Equal(9, d.Remaining)
+}
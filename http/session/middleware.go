@@ -0,0 +1,245 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+)
+
+// Options configures the session middleware.
+type Options struct {
+	// Keys encrypt and sign the session cookie with AES-GCM, they must be 16, 24 or 32 bytes long. The first key seals
+	// new cookies, the others are accepted as well, so keys can be rotated.
+	Keys [][]byte
+	// Store keeps the session data, a new MemoryStore if nil. CookieStore keeps it in the cookie.
+	Store Store
+	// MaxAge is the lifetime of a session after it was last modified.
+	MaxAge time.Duration
+
+	// Name is the name of the session cookie.
+	Name     string
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+func (o Options) Default() Options {
+	return Options{
+		MaxAge:   24 * time.Hour,
+		Name:     "session",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLax,
+	}
+}
+
+// record is the data of a session kept in a Store.
+type record struct {
+	Values  map[string]string `json:"v,omitempty"`
+	Flashes []string          `json:"f,omitempty"`
+	// Expires is checked on load, as cookies sealed by a CookieStore can't be revoked
+	Expires int64 `json:"e"`
+}
+
+// Middleware returns a middleware loading the session of a request into its context, see FromContext, and saving it
+// with the response if it was modified.
+//
+// Invalid, tampered and expired cookies start a new session. Errors of the store are returned as errors of the
+// middleware.
+func Middleware(opts Options) http.Middleware {
+	aeads := make([]cipher.AEAD, 0, len(opts.Keys))
+	for i, key := range opts.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic(fmt.Sprintf("session: invalid key %d: %s", i, err))
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(fmt.Sprintf("session: invalid key %d: %s", i, err))
+		}
+		aeads = append(aeads, aead)
+	}
+	if len(aeads) == 0 {
+		panic("session: no keys")
+	}
+	if opts.MaxAge <= 0 {
+		panic(fmt.Sprintf("session: invalid max age %s", opts.MaxAge))
+	}
+	if opts.Name == "" {
+		opts.Name = "session"
+	}
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	m := &manager{opts: opts, aeads: aeads, store: store, now: now}
+
+	return func(req *http.Request[http.RequestBody], next http.Next) shepard.Result[http.Response[http.Body], error] {
+		ctx := req.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		s := m.load(ctx, req)
+		if s.IsErr() {
+			return shepard.Err[http.Response[http.Body], error](s.UnwrapErr())
+		}
+		req.Context = ContextWithSession(ctx, s.Unwrap())
+
+		res := next()
+		if res.IsErr() {
+			return res
+		}
+		if err := m.save(ctx, res.Unwrap(), s.Unwrap()); err != nil {
+			return shepard.Err[http.Response[http.Body], error](err)
+		}
+		return res
+	}
+}
+
+type manager struct {
+	opts  Options
+	aeads []cipher.AEAD
+	store Store
+	now   func() time.Time
+}
+
+// load returns the session referenced by the session cookie of req, a new session if there's no valid one.
+func (m *manager) load(ctx context.Context, req *http.Request[http.RequestBody]) shepard.Result[*Session, error] {
+	s := newSession()
+	cookie := req.Cookie(m.opts.Name)
+	if cookie.IsNone() {
+		return shepard.Ok[*Session, error](s)
+	}
+	value := m.open(cookie.Unwrap().Value)
+	if value.IsNone() {
+		return shepard.Ok[*Session, error](s)
+	}
+
+	data := m.store.Load(ctx, value.Unwrap())
+	if data.IsErr() {
+		return shepard.Err[*Session, error](fmt.Errorf("[session.Middleware] load session failed: %w", data.UnwrapErr()))
+	}
+	if data.Unwrap().IsNone() {
+		return shepard.Ok[*Session, error](s)
+	}
+	var rec record
+	if err := json.Unmarshal(data.Unwrap().Unwrap(), &rec); err != nil || !m.now().Before(time.Unix(rec.Expires, 0)) {
+		return shepard.Ok[*Session, error](s)
+	}
+
+	s.value = value.Unwrap()
+	if rec.Values != nil {
+		s.values = rec.Values
+	}
+	s.flashes = rec.Flashes
+	return shepard.Ok[*Session, error](s)
+}
+
+// save stores s if it was modified and sets the session cookie on res, or deletes the session if it was destroyed.
+func (m *manager) save(ctx context.Context, res http.Response[http.Body], s *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		if s.value == "" {
+			return nil
+		}
+		if err := m.store.Delete(ctx, s.value); err != nil {
+			return fmt.Errorf("[session.Middleware] delete session failed: %w", err)
+		}
+		return m.setCookie(res, "", -1)
+	}
+	if !s.modified {
+		return nil
+	}
+
+	value := s.value
+	if s.renew && value != "" {
+		if err := m.store.Delete(ctx, value); err != nil {
+			return fmt.Errorf("[session.Middleware] delete session failed: %w", err)
+		}
+		value = ""
+	}
+	expires := m.now().Add(m.opts.MaxAge)
+	data, err := json.Marshal(record{Values: s.values, Flashes: s.flashes, Expires: expires.Unix()})
+	if err != nil {
+		return fmt.Errorf("[session.Middleware] encode session failed: %w", err)
+	}
+	saved := m.store.Save(ctx, value, data, expires)
+	if saved.IsErr() {
+		return fmt.Errorf("[session.Middleware] save session failed: %w", saved.UnwrapErr())
+	}
+	s.value = saved.Unwrap()
+	s.modified = false
+	s.renew = false
+
+	sealed := m.seal(s.value)
+	if sealed.IsErr() {
+		return sealed.UnwrapErr()
+	}
+	return m.setCookie(res, sealed.Unwrap(), int(m.opts.MaxAge/time.Second))
+}
+
+func (m *manager) setCookie(res http.Response[http.Body], value string, maxAge int) error {
+	err := http.SetCookie(res, http.Cookie{
+		Name:     m.opts.Name,
+		Value:    value,
+		Path:     m.opts.Path,
+		Domain:   m.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.opts.Secure,
+		HttpOnly: m.opts.HttpOnly,
+		SameSite: m.opts.SameSite,
+	})
+	if err != nil {
+		return fmt.Errorf("[session.Middleware] set cookie failed: %w", err)
+	}
+	return nil
+}
+
+// seal encrypts value with the first key. The cookie name is authenticated as well, so a sealed value can't be moved to
+// another cookie.
+func (m *manager) seal(value string) shepard.Result[string, error] {
+	aead := m.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return shepard.Err[string, error](fmt.Errorf("[session.Middleware] generate nonce failed: %w", err))
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(m.opts.Name))
+	return shepard.Ok[string, error](base64.RawURLEncoding.EncodeToString(sealed))
+}
+
+// open decrypts a value sealed with any of the keys.
+func (m *manager) open(cookie string) shepard.Option[string] {
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil {
+		return shepard.None[string]()
+	}
+	for _, aead := range m.aeads {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+		value, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(m.opts.Name))
+		if err == nil {
+			return shepard.Some(string(value))
+		}
+	}
+	return shepard.None[string]()
+}
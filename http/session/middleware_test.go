@@ -0,0 +1,188 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestRequest returns a GET request for uri with the session cookie, if it isn't empty.
+func newTestRequest(uri string, cookie string) *http.Request[http.RequestBody] {
+	req := testhttp.NewRequest(http.MethodGet, uri, nil)
+	if cookie != "" {
+		req.Headers.Set("Cookie", "session="+cookie)
+	}
+	return req
+}
+
+// newSessionRouter returns a router logging in on "/login", logging out on "/logout", adding a flash message on "/flash"
+// and responding with the user and the flash messages on "/".
+func newSessionRouter(opts Options) *http.Router {
+	handler := func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		s := FromContext(req.Context).Unwrap()
+		flashes := []string{}
+		switch req.URL.Path {
+		case "/":
+			flashes = s.Flashes()
+		case "/login":
+			s.Set("user", *req.URL.Query().Get("user").First().Unwrap())
+			s.Renew()
+		case "/logout":
+			s.Destroy()
+		case "/flash":
+			s.AddFlash("saved")
+		}
+		return http.Text(http.StatusCodeOk, s.Get("user").UnwrapOr("anonymous")+" "+strings.Join(flashes, ","))
+	}
+	r := http.NewRouter().Use(Middleware(opts))
+	for _, path := range []string{"/", "/login", "/logout", "/flash"} {
+		r.Route(http.Get(path, handler))
+	}
+	return r
+}
+
+// sessionCookie returns the session cookie set by res, shepard.None if there's none.
+func sessionCookie(res http.Response[http.Body]) shepard.Option[http.Cookie] {
+	for _, cookie := range http.ResponseCookies(res) {
+		if cookie.Name == "session" {
+			return shepard.Some(cookie)
+		}
+	}
+	return shepard.None[http.Cookie]()
+}
+
+func TestMiddleware(t *testing.T) {
+	for _, store := range []Store{NewMemoryStore(), CookieStore{}} {
+		assert := assert.New(t)
+
+		opts := Options{}.Default()
+		opts.Keys = [][]byte{testKey}
+		opts.Store = store
+		r := newSessionRouter(opts)
+
+		// sessions which aren't modified don't set a cookie
+		res := r.Serve(newTestRequest("/", "")).Unwrap()
+		assert.Equal("anonymous ", testhttp.ReadBody(res))
+		assert.True(sessionCookie(res).IsNone())
+
+		res = r.Serve(newTestRequest("/login?user=alice", "")).Unwrap()
+		cookie := sessionCookie(res).Unwrap()
+		assert.Equal("/", cookie.Path)
+		assert.Equal(86400, cookie.MaxAge)
+		assert.True(cookie.HttpOnly)
+		assert.Equal(http.SameSiteLax, cookie.SameSite)
+		// the value is encrypted
+		assert.NotContains(cookie.Value, "alice")
+
+		// flashes are shown once
+		res = r.Serve(newTestRequest("/flash", cookie.Value)).Unwrap()
+		res = r.Serve(newTestRequest("/flash", sessionCookie(res).Unwrap().Value)).Unwrap()
+		flashed := sessionCookie(res).Unwrap().Value
+		res = r.Serve(newTestRequest("/", flashed)).Unwrap()
+		assert.Equal("alice saved,saved", testhttp.ReadBody(res))
+		assert.Equal("alice ", testhttp.ReadBody(r.Serve(newTestRequest("/", sessionCookie(res).Unwrap().Value)).Unwrap()))
+
+		// tampered cookies start a new session
+		// the last character may only carry padding bits, a character in the middle changes the sealed bytes
+		tampered := []byte(cookie.Value)
+		if tampered[len(tampered)/2] == 'A' {
+			tampered[len(tampered)/2] = 'B'
+		} else {
+			tampered[len(tampered)/2] = 'A'
+		}
+		assert.Equal("anonymous ", testhttp.ReadBody(r.Serve(newTestRequest("/", string(tampered))).Unwrap()))
+		assert.Equal("anonymous ", testhttp.ReadBody(r.Serve(newTestRequest("/", "invalid")).Unwrap()))
+
+		res = r.Serve(newTestRequest("/logout", cookie.Value)).Unwrap()
+		assert.Equal(-1, sessionCookie(res).Unwrap().MaxAge)
+		assert.Equal("", sessionCookie(res).Unwrap().Value)
+	}
+}
+
+func TestMiddleware_ServerSide(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewMemoryStore()
+	r := newSessionRouter(Options{Keys: [][]byte{testKey}, Store: store, MaxAge: time.Hour})
+
+	first := sessionCookie(r.Serve(newTestRequest("/flash", "")).Unwrap()).Unwrap().Value
+	assert.Equal(1, store.Len())
+
+	// logging in moves the session to a new id
+	second := sessionCookie(r.Serve(newTestRequest("/login?user=bob", first)).Unwrap()).Unwrap().Value
+	assert.Equal(1, store.Len())
+	assert.Equal("anonymous ", testhttp.ReadBody(r.Serve(newTestRequest("/", first)).Unwrap()))
+	assert.Equal("bob saved", testhttp.ReadBody(r.Serve(newTestRequest("/", second)).Unwrap()))
+
+	// destroyed sessions are revoked
+	r.Serve(newTestRequest("/logout", second))
+	assert.Equal(0, store.Len())
+	assert.Equal("anonymous ", testhttp.ReadBody(r.Serve(newTestRequest("/", second)).Unwrap()))
+}
+
+func TestMiddleware_Expiry(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	opts := Options{Keys: [][]byte{testKey}, Store: CookieStore{}, MaxAge: time.Hour, Now: func() time.Time { return now }}
+	r := newSessionRouter(opts)
+
+	cookie := sessionCookie(r.Serve(newTestRequest("/login?user=alice", "")).Unwrap()).Unwrap().Value
+	now = now.Add(59 * time.Minute)
+	assert.Equal("alice ", testhttp.ReadBody(r.Serve(newTestRequest("/", cookie)).Unwrap()))
+	now = now.Add(time.Minute)
+	assert.Equal("anonymous ", testhttp.ReadBody(r.Serve(newTestRequest("/", cookie)).Unwrap()))
+}
+
+func TestMiddleware_KeyRotation(t *testing.T) {
+	assert := assert.New(t)
+
+	oldKey := []byte("fedcba9876543210")
+	old := newSessionRouter(Options{Keys: [][]byte{oldKey}, Store: CookieStore{}, MaxAge: time.Hour})
+	cookie := sessionCookie(old.Serve(newTestRequest("/login?user=alice", "")).Unwrap()).Unwrap().Value
+
+	r := newSessionRouter(Options{Keys: [][]byte{testKey, oldKey}, Store: CookieStore{}, MaxAge: time.Hour})
+	assert.Equal("alice ", testhttp.ReadBody(r.Serve(newTestRequest("/", cookie)).Unwrap()))
+	rotated := newSessionRouter(Options{Keys: [][]byte{testKey}, Store: CookieStore{}, MaxAge: time.Hour})
+	assert.Equal("anonymous ", testhttp.ReadBody(rotated.Serve(newTestRequest("/", cookie)).Unwrap()))
+
+	// values sealed for another cookie name aren't accepted
+	other := newSessionRouter(Options{Keys: [][]byte{oldKey}, Store: CookieStore{}, MaxAge: time.Hour, Name: "other"})
+	req := newTestRequest("/", "")
+	req.Headers.Set("Cookie", "other="+cookie)
+	assert.Equal("anonymous ", testhttp.ReadBody(other.Serve(req).Unwrap()))
+
+	assert.Panics(func() { Middleware(Options{MaxAge: time.Hour}) })
+	assert.Panics(func() { Middleware(Options{Keys: [][]byte{[]byte("short")}, MaxAge: time.Hour}) })
+	assert.Panics(func() { Middleware(Options{Keys: [][]byte{testKey}}) })
+}
+
+type failingStore struct{ CookieStore }
+
+func (failingStore) Load(context.Context, string) shepard.Result[shepard.Option[[]byte], error] {
+	return shepard.Err[shepard.Option[[]byte], error](errors.New("connection refused"))
+}
+
+func (failingStore) Save(context.Context, string, []byte, time.Time) shepard.Result[string, error] {
+	return shepard.Err[string, error](errors.New("connection refused"))
+}
+
+func TestMiddleware_StoreError(t *testing.T) {
+	assert := assert.New(t)
+
+	r := newSessionRouter(Options{Keys: [][]byte{testKey}, Store: failingStore{}, MaxAge: time.Hour})
+	assert.ErrorContains(r.Serve(newTestRequest("/login?user=alice", "")).UnwrapErr(), "save session failed: connection refused")
+
+	cookie := sessionCookie(newSessionRouter(Options{Keys: [][]byte{testKey}, Store: CookieStore{}, MaxAge: time.Hour}).
+		Serve(newTestRequest("/login?user=alice", "")).Unwrap()).Unwrap().Value
+	assert.ErrorContains(r.Serve(newTestRequest("/", cookie)).UnwrapErr(), "load session failed: connection refused")
+}
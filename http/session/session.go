@@ -0,0 +1,136 @@
+// Package session implements sessions kept between requests with a cookie, either in the cookie itself or in a server
+// side store, and flash messages shown once on the following request.
+package session
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/marlaone/shepard"
+)
+
+type contextKey struct{}
+
+// Session holds the values of a client between requests. It's safe for concurrent use.
+type Session struct {
+	mu      sync.Mutex
+	values  map[string]string
+	flashes []string
+
+	// value is the cookie value the session was loaded from, empty for new sessions
+	value     string
+	modified  bool
+	renew     bool
+	destroyed bool
+}
+
+func newSession() *Session {
+	return &Session{values: map[string]string{}}
+}
+
+// ContextWithSession returns a copy of ctx carrying s.
+func ContextWithSession(ctx context.Context, s *Session) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the session of a request handled by the session middleware.
+func FromContext(ctx context.Context) shepard.Option[*Session] {
+	if ctx == nil {
+		return shepard.None[*Session]()
+	}
+	s, ok := ctx.Value(contextKey{}).(*Session)
+	if !ok {
+		return shepard.None[*Session]()
+	}
+	return shepard.Some(s)
+}
+
+// IsNew returns true if the client didn't send a valid session cookie.
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value == ""
+}
+
+// Get returns the value of key.
+func (s *Session) Get(key string) shepard.Option[string] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	if !ok {
+		return shepard.None[string]()
+	}
+	return shepard.Some(value)
+}
+
+// Set sets the value of key.
+func (s *Session) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete removes key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// Keys returns the sorted keys of the session.
+func (s *Session) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddFlash adds a message shown once, e.g. on the page a form redirects to.
+func (s *Session) AddFlash(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flashes = append(s.flashes, message)
+	s.modified = true
+}
+
+// Flashes returns the flash messages and removes them from the session.
+func (s *Session) Flashes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes := s.flashes
+	if len(flashes) > 0 {
+		s.flashes = nil
+		s.modified = true
+	}
+	return flashes
+}
+
+// Renew keeps the values but moves them to a new session ID, which prevents session fixation when the privileges
+// of a session change, e.g. on login.
+func (s *Session) Renew() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renew = true
+	s.modified = true
+}
+
+// Destroy removes all values and deletes the session cookie, e.g. on logout.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]string{}
+	s.flashes = nil
+	s.destroyed = true
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.True(t, FromContext(context.Background()).IsNone())
+	assert.True(t, FromContext(nil).IsNone())
+
+	s := newSession()
+	assert.Same(t, s, FromContext(ContextWithSession(nil, s)).Unwrap())
+}
+
+func TestSession(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newSession()
+	assert.True(s.IsNew())
+	assert.True(s.Get("user").IsNone())
+	s.Delete("user")
+	assert.False(s.modified)
+
+	s.Set("user", "alice")
+	s.Set("theme", "dark")
+	assert.True(s.modified)
+	assert.Equal("alice", s.Get("user").Unwrap())
+	assert.Equal([]string{"theme", "user"}, s.Keys())
+	s.Delete("theme")
+	assert.Equal([]string{"user"}, s.Keys())
+
+	s.modified = false
+	assert.Empty(s.Flashes())
+	assert.False(s.modified)
+	s.AddFlash("saved")
+	s.AddFlash("mailed")
+	assert.Equal([]string{"saved", "mailed"}, s.Flashes())
+	assert.Empty(s.Flashes())
+
+	s.Destroy()
+	assert.True(s.destroyed)
+	assert.Empty(s.Keys())
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
+)
+
+// ErrSessionTooLarge is returned by a CookieStore for sessions which don't fit into a cookie.
+var ErrSessionTooLarge = errors.New("session too large")
+
+// Store keeps the data of sessions, referenced by the value of the session cookie.
+type Store interface {
+	// Load returns the data referenced by value, shepard.None if it doesn't exist or expired.
+	Load(ctx context.Context, value string) shepard.Result[shepard.Option[[]byte], error]
+	// Save stores data until expires and returns the cookie value referencing it. value is the cookie value of the
+	// session, empty for new sessions and sessions moving to a new ID.
+	Save(ctx context.Context, value string, data []byte, expires time.Time) shepard.Result[string, error]
+	// Delete removes the data referenced by value.
+	Delete(ctx context.Context, value string) error
+}
+
+// maxCookieData is the size of the data a CookieStore accepts, leaving room for the encryption overhead, name and
+// attributes within the 4096 bytes browsers store per cookie.
+const maxCookieData = 2800
+
+// CookieStore is a Store keeping the data in the cookie itself, so sessions work without server side state.
+// Destroyed sessions can't be revoked, they remain valid until they expire.
+type CookieStore struct{}
+
+var _ Store = CookieStore{}
+
+func (CookieStore) Load(_ context.Context, value string) shepard.Result[shepard.Option[[]byte], error] {
+	return shepard.Ok[shepard.Option[[]byte], error](shepard.Some([]byte(value)))
+}
+
+func (CookieStore) Save(_ context.Context, _ string, data []byte, _ time.Time) shepard.Result[string, error] {
+	if len(data) > maxCookieData {
+		return shepard.Err[string, error](fmt.Errorf("[session.CookieStore.Save] %d bytes exceed %d: %w", len(data), maxCookieData, ErrSessionTooLarge))
+	}
+	return shepard.Ok[string, error](string(data))
+}
+
+func (CookieStore) Delete(context.Context, string) error {
+	return nil
+}
+
+// memoryEntry is the data of a session in a MemoryStore.
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// MemoryStore is a Store keeping the data in memory under random session IDs. Expired sessions are removed periodically.
+type MemoryStore struct {
+	mu        sync.Mutex
+	sessions  hashmap.HashMap[string, memoryEntry]
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// sweepInterval is the minimal interval between removals of expired sessions by a MemoryStore.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: hashmap.New[string, memoryEntry](),
+		now:      time.Now,
+	}
+}
+
+// Len returns the number of stored sessions.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions.Len()
+}
+
+func (s *MemoryStore) Load(_ context.Context, value string) shepard.Result[shepard.Option[[]byte], error] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+
+	entry := s.sessions.Get(value)
+	if entry.IsNone() || !now.Before(entry.Unwrap().expires) {
+		return shepard.Ok[shepard.Option[[]byte], error](shepard.None[[]byte]())
+	}
+	return shepard.Ok[shepard.Option[[]byte], error](shepard.Some(entry.Unwrap().data))
+}
+
+func (s *MemoryStore) Save(_ context.Context, value string, data []byte, expires time.Time) shepard.Result[string, error] {
+	if value == "" {
+		id := make([]byte, 32)
+		if _, err := rand.Read(id); err != nil {
+			return shepard.Err[string, error](fmt.Errorf("[session.MemoryStore.Save] generate id failed: %w", err))
+		}
+		value = base64.RawURLEncoding.EncodeToString(id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(s.now())
+	s.sessions.Insert(value, memoryEntry{data: data, expires: expires})
+	return shepard.Ok[string, error](value)
+}
+
+func (s *MemoryStore) Delete(_ context.Context, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions.Remove(value)
+	return nil
+}
+
+// sweep removes the sessions which expired at now, at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	expired := []string{}
+	s.sessions.Iter().Foreach(func(_ int, pair hashmap.Pair[*string, *memoryEntry]) {
+		if !now.Before(pair.Value.expires) {
+			expired = append(expired, *pair.Key)
+		}
+	})
+	for _, key := range expired {
+		s.sessions.Remove(key)
+	}
+}
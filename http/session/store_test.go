@@ -0,0 +1,55 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	id := s.Save(ctx, "", []byte("a"), now.Add(time.Hour)).Unwrap()
+	assert.Len(id, 43)
+	assert.Equal([]byte("a"), s.Load(ctx, id).Unwrap().Unwrap())
+	assert.True(s.Load(ctx, "unknown").Unwrap().IsNone())
+	assert.NotEqual(id, s.Save(ctx, "", []byte("b"), now.Add(time.Minute)).Unwrap())
+
+	// saving keeps the id
+	assert.Equal(id, s.Save(ctx, id, []byte("c"), now.Add(time.Hour)).Unwrap())
+	assert.Equal([]byte("c"), s.Load(ctx, id).Unwrap().Unwrap())
+
+	// expired sessions aren't loaded and are removed
+	now = now.Add(2 * time.Minute)
+	assert.Equal(2, s.Len())
+	assert.True(s.Load(ctx, id).Unwrap().IsSome())
+	assert.Equal(1, s.Len())
+	now = now.Add(time.Hour)
+	assert.True(s.Load(ctx, id).Unwrap().IsNone())
+
+	s.now = func() time.Time { return now.Add(-time.Hour) }
+	assert.NoError(s.Delete(ctx, id))
+	assert.True(s.Load(ctx, id).Unwrap().IsNone())
+	assert.Equal(0, s.Len())
+}
+
+func TestCookieStore(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	value := CookieStore{}.Save(ctx, "old", []byte(`{"v":{}}`), time.Now()).Unwrap()
+	assert.Equal(`{"v":{}}`, value)
+	assert.Equal([]byte(value), CookieStore{}.Load(ctx, value).Unwrap().Unwrap())
+	assert.NoError(CookieStore{}.Delete(ctx, value))
+
+	res := CookieStore{}.Save(ctx, "", []byte(strings.Repeat("a", maxCookieData+1)), time.Now())
+	assert.ErrorIs(res.UnwrapErr(), ErrSessionTooLarge)
+}
@@ -0,0 +1,181 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/hashmap"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/http"
+)
+
+// DialOptions configures the connections opened by Dial.
+type DialOptions struct {
+	Options
+	// Headers are added to the handshake request, e.g. Authorization or Origin.
+	Headers map[string]string
+	// TLSConfig configures the connections to wss URLs.
+	TLSConfig *tls.Config
+	// DialContext opens the connections, net.Dialer.DialContext if nil. http.Server.DialPipe connects to a server in the
+	// same process.
+	DialContext func(ctx context.Context, network string, addr string) (net.Conn, error)
+}
+
+func (o DialOptions) Default() DialOptions {
+	return DialOptions{Options: Options{}.Default()}
+}
+
+// Dial opens a WebSocket connection to rawURL, a ws or wss URL. The deadline and cancellation of ctx apply to the
+// handshake only.
+//
+// An error wrapping ErrHandshake is returned if the server didn't switch protocols, e.g. because it rejected the
+// origin of the request.
+func Dial(ctx context.Context, rawURL string, opts DialOptions) shepard.Result[*Conn, error] {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return shepard.Err[*Conn, error](fmt.Errorf("[websocket.Dial] invalid url %q: %w", rawURL, err))
+	}
+	port := "80"
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		port = "443"
+	default:
+		return shepard.Err[*Conn, error](fmt.Errorf("[websocket.Dial] unsupported scheme %q", u.Scheme))
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+
+	dial := opts.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	conn, err := dial(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return shepard.Err[*Conn, error](fmt.Errorf("[websocket.Dial] dial failed: %w", err))
+	}
+	if u.Scheme == "wss" {
+		config := &tls.Config{}
+		if opts.TLSConfig != nil {
+			config = opts.TLSConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return shepard.Err[*Conn, error](fmt.Errorf("[websocket.Dial] tls handshake failed: %w", err))
+		}
+		conn = tlsConn
+	}
+
+	// the handshake is aborted once ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	c, err := handshake(conn, u, opts)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return shepard.Err[*Conn, error](fmt.Errorf("[websocket.Dial] %w", err))
+	}
+	if !stop() {
+		c.closeConn()
+		return shepard.Err[*Conn, error](fmt.Errorf("[websocket.Dial] %w", ctx.Err()))
+	}
+	conn.SetDeadline(time.Time{})
+	return shepard.Ok[*Conn, error](c)
+}
+
+// handshake sends the handshake request for u and validates the response of the server.
+func handshake(conn net.Conn, u *url.URL, opts DialOptions) (*Conn, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate key failed: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	headers := http.Headers{}.Default()
+	for k, v := range opts.Headers {
+		headers.Set(k, v)
+	}
+	headers.Set("Host", u.Host)
+	headers.Set("Upgrade", "websocket")
+	headers.Set("Connection", "Upgrade")
+	headers.Set("Sec-WebSocket-Key", key)
+	headers.Set("Sec-WebSocket-Version", "13")
+	if len(opts.Subprotocols) > 0 {
+		headers.Set("Sec-WebSocket-Protocol", strings.Join(opts.Subprotocols, ", "))
+	}
+	if opts.Compression {
+		headers.Set("Sec-WebSocket-Extensions", deflateExtension)
+	}
+
+	w := bufio.NewWriter(conn)
+	w.WriteString("GET " + u.RequestURI() + " HTTP/1.1\r\n")
+	headers.Iter().Foreach(func(_ int, header hashmap.Pair[*string, *slice.Slice[string]]) {
+		header.Value.Iter().Foreach(func(_ int, value string) {
+			w.WriteString(*header.Key + ": " + value + "\r\n")
+		})
+	})
+	w.WriteString("\r\n")
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("write handshake failed: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+	tp := textproto.NewReader(r)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("read handshake failed: %w", err)
+	}
+	_, status, _ := strings.Cut(line, " ")
+	status, _, _ = strings.Cut(status, " ")
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("read handshake failed: %w", err)
+	}
+	if code, _ := strconv.Atoi(status); http.StatusCode(code) != http.StatusCodeSwitchingProtocols {
+		return nil, fmt.Errorf("unexpected status %q: %w", status, ErrHandshake)
+	}
+	res := http.Headers{}.Default()
+	for k, values := range header {
+		res.Set(k, values...)
+	}
+
+	if !hasToken(res.List("Upgrade"), "websocket") || !hasToken(res.List("Connection"), "upgrade") {
+		return nil, fmt.Errorf("server didn't upgrade to websocket: %w", ErrHandshake)
+	}
+	if res.Value("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, fmt.Errorf("invalid Sec-WebSocket-Accept header: %w", ErrHandshake)
+	}
+	subprotocol := res.Value("Sec-WebSocket-Protocol")
+	if subprotocol != "" && selectSubprotocol(opts.Subprotocols, []string{subprotocol}) == "" {
+		return nil, fmt.Errorf("unexpected subprotocol %q: %w", subprotocol, ErrHandshake)
+	}
+	compress, err := deflateAccepted(&res)
+	if err != nil {
+		return nil, err
+	}
+	if compress && !opts.Compression {
+		return nil, fmt.Errorf("unexpected extension permessage-deflate: %w", ErrHandshake)
+	}
+	return newConn(conn, r, false, opts.Options, subprotocol, compress), nil
+}
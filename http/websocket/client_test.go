@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeServerDialer returns a dialer of connections answering the handshake request with the response returned by respond.
+func fakeServerDialer(respond func(key string) string) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			tp := textproto.NewReader(bufio.NewReader(server))
+			tp.ReadLine()
+			header, err := tp.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			fmt.Fprint(server, respond(header.Get("Sec-WebSocket-Key")))
+			// wait for the client to close the connection
+			server.Read(make([]byte, 1))
+		}()
+		return client, nil
+	}
+}
+
+func TestDial(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := Options{}.Default()
+	opts.Subprotocols = []string{"chat.v2", "chat.v1"}
+	s := newTestServer(opts, func(req *http.Request[http.RequestBody], c *Conn) {
+		c.WriteMessage(TextMessage, []byte(req.Headers.Value("Authorization")+" "+c.Subprotocol()))
+	})
+
+	dialOpts := DialOptions{}.Default()
+	dialOpts.Subprotocols = []string{"chat.v1", "chat.v2"}
+	dialOpts.Headers = map[string]string{"Authorization": "Bearer token"}
+	c := dialTest(t, s, dialOpts)
+	assert.Equal("chat.v2", c.Subprotocol())
+	assert.Equal("Bearer token chat.v2", string(c.ReadMessage().Unwrap().Data))
+	assert.Equal(CloseNormalClosure, closeCode(c.ReadMessage().UnwrapErr()))
+}
+
+func TestDial_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestServer(Options{}.Default(), echo(nil))
+
+	assert.ErrorContains(Dial(context.Background(), "http://example.com/ws", DialOptions{}.Default()).UnwrapErr(), "unsupported scheme")
+	assert.Error(Dial(context.Background(), "ws://%zz", DialOptions{}.Default()).UnwrapErr())
+
+	// rejected handshakes
+	dialOpts := DialOptions{}.Default()
+	dialOpts.DialContext = s.DialPipe
+	dialOpts.Headers = map[string]string{"Origin": "https://evil.example"}
+	err := Dial(context.Background(), "ws://example.com/ws", dialOpts).UnwrapErr()
+	assert.ErrorIs(err, ErrHandshake)
+	assert.ErrorContains(err, "403")
+	dialOpts.Headers = nil
+	assert.ErrorIs(Dial(context.Background(), "ws://example.com/missing", dialOpts).UnwrapErr(), ErrHandshake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(Dial(ctx, "ws://example.com/ws", dialOpts).UnwrapErr(), context.Canceled)
+
+	// invalid responses
+	accepted := func(key string, extra string) string {
+		return "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + acceptKey(key) + "\r\n" + extra + "\r\n"
+	}
+	responses := map[string]func(key string) string{
+		"invalid accept": func(key string) string {
+			return "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: invalid\r\n\r\n"
+		},
+		"no upgrade": func(key string) string {
+			return "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+		},
+		"unexpected subprotocol": func(key string) string {
+			return accepted(key, "Sec-WebSocket-Protocol: other\r\n")
+		},
+		"unexpected extension": func(key string) string {
+			return accepted(key, "Sec-WebSocket-Extensions: permessage-deflate\r\n")
+		},
+	}
+	for name, respond := range responses {
+		dialOpts := DialOptions{}.Default()
+		dialOpts.DialContext = fakeServerDialer(respond)
+		assert.ErrorIs(Dial(context.Background(), "ws://example.com/ws", dialOpts).UnwrapErr(), ErrHandshake, name)
+	}
+
+	// servers not answering within the deadline of ctx
+	dialOpts = DialOptions{}.Default()
+	dialOpts.DialContext = fakeServerDialer(func(key string) string {
+		time.Sleep(time.Second)
+		return accepted(key, "")
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(Dial(ctx, "ws://example.com/ws", dialOpts).UnwrapErr(), context.DeadlineExceeded)
+}
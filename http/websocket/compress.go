@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/marlaone/shepard/http"
+)
+
+// deflateExtension is the permessage-deflate extension of RFC 7692. Both sides compress each message on its own,
+// without a context taken over from previous messages, so connections don't keep a compressor and decompressor.
+const deflateExtension = "permessage-deflate; server_no_context_takeover; client_no_context_takeover"
+
+// deflateTrailer is removed from compressed messages by the sender. It's appended by the receiver together with a final
+// empty block, which ends the deflate stream.
+var (
+	deflateTrailer = []byte{0x00, 0x00, 0xff, 0xff}
+	deflateEnd     = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+)
+
+// flateWriters pools flate writers by compression level, from flate.HuffmanOnly (-2) to flate.BestCompression (9).
+var flateWriters [flate.BestCompression - flate.HuffmanOnly + 1]sync.Pool
+
+// compress returns data compressed for a message of the permessage-deflate extension.
+func compress(data []byte, level int) ([]byte, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		level = flate.DefaultCompression
+	}
+	var buf bytes.Buffer
+	pool := &flateWriters[level-flate.HuffmanOnly]
+	fw, ok := pool.Get().(*flate.Writer)
+	if ok {
+		fw.Reset(&buf)
+	} else {
+		var err error
+		if fw, err = flate.NewWriter(&buf, level); err != nil {
+			return nil, err
+		}
+	}
+	defer pool.Put(fw)
+
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), deflateTrailer), nil
+}
+
+// decompress returns the data of a compressed message, at most maxSize bytes.
+func decompress(data []byte, maxSize uint64) ([]byte, error) {
+	fr := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateEnd)))
+	defer fr.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(fr, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress message failed: %v: %w", err, ErrInvalidPayload)
+	}
+	if uint64(len(decompressed)) > maxSize {
+		return nil, ErrMessageTooBig
+	}
+	return decompressed, nil
+}
+
+// acceptDeflate returns true if one of the extensions offered by a client is permessage-deflate with parameters the
+// server supports. Window sizes smaller than the default can't be used for compression, while decompression handles
+// any window.
+func acceptDeflate(offers []string) bool {
+	for _, offer := range offers {
+		name, params := parseExtension(offer)
+		if name != "permessage-deflate" {
+			continue
+		}
+		accepted := true
+		for key, value := range params {
+			switch key {
+			case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+			case "server_max_window_bits":
+				accepted = accepted && value == "15"
+			default:
+				accepted = false
+			}
+		}
+		if accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// deflateAccepted returns whether the server accepted the permessage-deflate extension offered by the client, and an
+// error if it responded with extensions the client didn't offer.
+func deflateAccepted(headers *http.Headers) (bool, error) {
+	extensions := headers.List("Sec-WebSocket-Extensions")
+	if len(extensions) == 0 {
+		return false, nil
+	}
+	if len(extensions) > 1 {
+		return false, fmt.Errorf("unexpected extensions %q: %w", extensions, ErrHandshake)
+	}
+	name, params := parseExtension(extensions[0])
+	if name != "permessage-deflate" {
+		return false, fmt.Errorf("unexpected extension %q: %w", name, ErrHandshake)
+	}
+	for key, value := range params {
+		switch key {
+		case "server_no_context_takeover", "client_no_context_takeover", "server_max_window_bits":
+		case "client_max_window_bits":
+			if value != "15" {
+				return false, fmt.Errorf("unsupported window size %q: %w", value, ErrHandshake)
+			}
+		default:
+			return false, fmt.Errorf("unexpected extension parameter %q: %w", key, ErrHandshake)
+		}
+	}
+	return true, nil
+}
+
+// parseExtension returns the lower case name and parameters of an extension of a Sec-WebSocket-Extensions header,
+// e.g. "permessage-deflate; client_max_window_bits".
+func parseExtension(extension string) (string, map[string]string) {
+	parts := strings.Split(extension, ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return strings.ToLower(strings.TrimSpace(parts[0])), params
+}
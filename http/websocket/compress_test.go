@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/marlaone/shepard/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompress_RoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, data := range [][]byte{{}, []byte("hello"), bytes.Repeat([]byte("hello world "), 1000)} {
+		for _, level := range []int{-2, 1, 9, 42} {
+			compressed, err := compress(data, level)
+			assert.NoError(err)
+
+			decompressed, err := decompress(compressed, uint64(DefaultMaxMessageSize))
+			assert.NoError(err)
+			assert.True(bytes.Equal(data, decompressed), "%d bytes at level %d", len(data), level)
+		}
+	}
+
+	compressed, _ := compress(bytes.Repeat([]byte("a"), 10000), 1)
+	assert.Less(len(compressed), 100)
+}
+
+func TestDecompress_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	compressed, _ := compress(make([]byte, 1000), 1)
+	_, err := decompress(compressed, 999)
+	assert.ErrorIs(err, ErrMessageTooBig)
+	data, err := decompress(compressed, 1000)
+	assert.NoError(err)
+	assert.Len(data, 1000)
+
+	_, err = decompress([]byte{0xff, 0xff, 0xff}, uint64(DefaultMaxMessageSize))
+	assert.ErrorIs(err, ErrInvalidPayload)
+}
+
+func TestAcceptDeflate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(acceptDeflate(nil))
+	assert.False(acceptDeflate([]string{"x-webkit-deflate-frame"}))
+	assert.True(acceptDeflate([]string{"permessage-deflate"}))
+	assert.True(acceptDeflate([]string{"permessage-deflate; client_max_window_bits"}))
+	assert.True(acceptDeflate([]string{"Permessage-Deflate; server_no_context_takeover; client_no_context_takeover"}))
+	// smaller windows can't be used for compression, the next offer is accepted
+	assert.False(acceptDeflate([]string{"permessage-deflate; server_max_window_bits=10"}))
+	assert.True(acceptDeflate([]string{"permessage-deflate; server_max_window_bits=10", "permessage-deflate"}))
+	assert.False(acceptDeflate([]string{"permessage-deflate; unknown"}))
+}
+
+func TestDeflateAccepted(t *testing.T) {
+	assert := assert.New(t)
+
+	accepted := func(value string) (bool, error) {
+		headers := http.Headers{}.Default()
+		if value != "" {
+			headers.Set("Sec-WebSocket-Extensions", value)
+		}
+		return deflateAccepted(&headers)
+	}
+
+	ok, err := accepted("")
+	assert.False(ok)
+	assert.NoError(err)
+	ok, err = accepted(deflateExtension)
+	assert.True(ok)
+	assert.NoError(err)
+	ok, err = accepted("permessage-deflate; server_max_window_bits=10")
+	assert.True(ok)
+	assert.NoError(err)
+
+	for _, value := range []string{"x-unknown", "permessage-deflate, x-unknown", "permessage-deflate; client_max_window_bits=10", "permessage-deflate; unknown"} {
+		_, err := accepted(value)
+		assert.ErrorIs(err, ErrHandshake, value)
+	}
+}
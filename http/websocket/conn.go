@@ -0,0 +1,315 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/marlaone/shepard"
+)
+
+// controlTimeout limits the time writing a control frame may block, e.g. a ping to a peer which doesn't read.
+const controlTimeout = 5 * time.Second
+
+// Conn is a WebSocket connection. Messages are read by a single goroutine, while writes are safe for concurrent use.
+type Conn struct {
+	conn        net.Conn
+	r           *bufio.Reader
+	server      bool
+	opts        Options
+	subprotocol string
+	// compress is true if the permessage-deflate extension was negotiated
+	compress bool
+
+	// mu guards writes, a message isn't interleaved with other frames. A failed write closes the connection, as
+	// the peer may have received part of a frame.
+	mu        sync.Mutex
+	w         *bufio.Writer
+	closeSent bool
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newConn(conn net.Conn, r *bufio.Reader, server bool, opts Options, subprotocol string, compress bool) *Conn {
+	c := &Conn{
+		conn:        conn,
+		r:           r,
+		server:      server,
+		opts:        opts,
+		subprotocol: subprotocol,
+		compress:    compress,
+		w:           bufio.NewWriter(conn),
+		done:        make(chan struct{}),
+	}
+	if opts.PingInterval > 0 {
+		go c.keepAlive()
+	}
+	return c
+}
+
+// Subprotocol returns the negotiated subprotocol, an empty string if none was.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns the network address of the peer.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// ReadMessage reads the next data message, assembling its fragments. Pings are answered while waiting for it.
+//
+// A *CloseError is returned when the peer closes the connection, after its close frame was answered. Protocol
+// violations, invalid text and messages exceeding Options.MaxMessageSize close the connection with the matching close
+// code, their errors wrap ErrProtocol, ErrInvalidPayload and ErrMessageTooBig.
+func (c *Conn) ReadMessage() shepard.Result[Message, error] {
+	msg, err := c.readMessage()
+	if err != nil {
+		return shepard.Err[Message, error](err)
+	}
+	return shepard.Ok[Message, error](msg)
+}
+
+func (c *Conn) readMessage() (Message, error) {
+	maxSize := uint64(c.opts.MaxMessageSize)
+	if maxSize == 0 {
+		maxSize = uint64(DefaultMaxMessageSize)
+	}
+	msg := Message{}
+	started := false
+	compressed := false
+
+	for {
+		if c.opts.PingInterval > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.opts.PingInterval + c.opts.PongTimeout))
+		}
+		f, err := readFrame(c.r, c.server, maxSize-uint64(len(msg.Data)))
+		if err != nil {
+			return Message{}, c.fail(err)
+		}
+		if f.rsv1 && (!c.compress || f.opcode.control() || f.opcode == opContinuation) {
+			return Message{}, c.fail(fmt.Errorf("unexpected compressed frame: %w", ErrProtocol))
+		}
+
+		switch f.opcode {
+		case opPing:
+			c.writeControl(opPong, f.payload)
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return Message{}, c.handleClose(f.payload)
+		case opText, opBinary:
+			if started {
+				return Message{}, c.fail(fmt.Errorf("message started before the previous one was finished: %w", ErrProtocol))
+			}
+			started = true
+			compressed = f.rsv1
+			msg.Type = MessageType(f.opcode)
+			msg.Data = f.payload
+		case opContinuation:
+			if !started {
+				return Message{}, c.fail(fmt.Errorf("continuation frame without a message: %w", ErrProtocol))
+			}
+			msg.Data = append(msg.Data, f.payload...)
+		default:
+			return Message{}, c.fail(fmt.Errorf("unknown opcode %d: %w", f.opcode, ErrProtocol))
+		}
+		if !f.fin {
+			continue
+		}
+
+		if compressed {
+			if msg.Data, err = decompress(msg.Data, maxSize); err != nil {
+				return Message{}, c.fail(err)
+			}
+		}
+		if msg.Type == TextMessage && !utf8.Valid(msg.Data) {
+			return Message{}, c.fail(fmt.Errorf("text message isn't valid utf-8: %w", ErrInvalidPayload))
+		}
+		return msg, nil
+	}
+}
+
+// handleClose answers the close frame of the peer and closes the connection.
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	if len(payload) == 1 {
+		return c.fail(fmt.Errorf("close frame payload of 1 byte: %w", ErrProtocol))
+	}
+	if len(payload) >= 2 {
+		closeErr.Code = CloseCode(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !closeErr.Code.sendable() {
+			return c.fail(fmt.Errorf("invalid close code %d: %w", uint16(closeErr.Code), ErrProtocol))
+		}
+		if !utf8.Valid(payload[2:]) {
+			return c.fail(fmt.Errorf("close reason isn't valid utf-8: %w", ErrInvalidPayload))
+		}
+		payload = payload[:2]
+	}
+	// the close frame is echoed, unless it answers a close frame sent before
+	c.writeControl(opClose, payload)
+	c.closeConn()
+	return closeErr
+}
+
+// fail closes the connection after a read error, sending the matching close code for invalid frames and messages.
+func (c *Conn) fail(err error) error {
+	var code CloseCode
+	switch {
+	case errors.Is(err, ErrProtocol):
+		code = CloseProtocolError
+	case errors.Is(err, ErrInvalidPayload):
+		code = CloseInvalidPayloadData
+	case errors.Is(err, ErrMessageTooBig):
+		code = CloseMessageTooBig
+	}
+	if code != 0 {
+		c.writeControl(opClose, closePayload(code, ""))
+	}
+	c.closeConn()
+	return fmt.Errorf("[websocket.ReadMessage] read message failed: %w", err)
+}
+
+// WriteMessage sends a data message. It's compressed if the permessage-deflate extension was negotiated, and split
+// into frames of Options.FragmentSize.
+func (c *Conn) WriteMessage(t MessageType, data []byte) error {
+	if t != TextMessage && t != BinaryMessage {
+		return fmt.Errorf("[websocket.WriteMessage] invalid message type %d", int(t))
+	}
+	if c.compress {
+		compressed, err := compress(data, c.opts.CompressionLevel)
+		if err != nil {
+			return fmt.Errorf("[websocket.WriteMessage] compress message failed: %w", err)
+		}
+		data = compressed
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeSent {
+		return fmt.Errorf("[websocket.WriteMessage] %w", ErrClosed)
+	}
+	c.conn.SetWriteDeadline(time.Time{})
+	f := frame{rsv1: c.compress, opcode: opcode(t)}
+	for {
+		n := len(data)
+		if c.opts.FragmentSize > 0 && n > c.opts.FragmentSize {
+			n = c.opts.FragmentSize
+		}
+		f.payload, data = data[:n], data[n:]
+		f.fin = len(data) == 0
+		if err := writeFrame(c.w, f, !c.server); err != nil {
+			c.closeConn()
+			return fmt.Errorf("[websocket.WriteMessage] write frame failed: %w", err)
+		}
+		if f.fin {
+			break
+		}
+		f.rsv1 = false
+		f.opcode = opContinuation
+	}
+	if err := c.w.Flush(); err != nil {
+		c.closeConn()
+		return fmt.Errorf("[websocket.WriteMessage] write frame failed: %w", err)
+	}
+	return nil
+}
+
+// Ping sends a ping with data of at most 125 bytes. The pong is handled by ReadMessage.
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return fmt.Errorf("[websocket.Ping] ping data of %d bytes: %w", len(data), ErrProtocol)
+	}
+	if err := c.writeControl(opPing, data); err != nil {
+		return fmt.Errorf("[websocket.Ping] %w", err)
+	}
+	return nil
+}
+
+// WriteClose starts the close handshake with code and a reason of at most 123 bytes. ReadMessage returns a *CloseError
+// once the peer answered, messages sent by the peer before are still returned.
+func (c *Conn) WriteClose(code CloseCode, reason string) error {
+	if !code.sendable() {
+		return fmt.Errorf("[websocket.WriteClose] invalid close code %d", uint16(code))
+	}
+	if len(reason) > maxControlPayload-2 {
+		return fmt.Errorf("[websocket.WriteClose] close reason of %d bytes: %w", len(reason), ErrProtocol)
+	}
+	if err := c.writeControl(opClose, closePayload(code, reason)); err != nil {
+		return fmt.Errorf("[websocket.WriteClose] %w", err)
+	}
+	return nil
+}
+
+// Close sends a close frame with CloseNormalClosure, unless one was sent before, and closes the connection without
+// waiting for the answer of the peer.
+func (c *Conn) Close() error {
+	c.writeControl(opClose, closePayload(CloseNormalClosure, ""))
+	return c.closeConn()
+}
+
+// writeControl sends a control frame, no frames are sent after a close frame.
+func (c *Conn) writeControl(op opcode, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	if op == opClose {
+		c.closeSent = true
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(controlTimeout))
+	err := writeFrame(c.w, frame{fin: true, opcode: op, payload: payload}, !c.server)
+	if err == nil {
+		err = c.w.Flush()
+	}
+	if err != nil {
+		c.closeConn()
+		return fmt.Errorf("write frame failed: %w", err)
+	}
+	return nil
+}
+
+// closeConn closes the underlying connection and stops the keepalive.
+func (c *Conn) closeConn() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// keepAlive sends a ping every Options.PingInterval until the connection is closed. ReadMessage closes the connection
+// if nothing, not even the pong, was received within the interval and Options.PongTimeout.
+func (c *Conn) keepAlive() {
+	ticker := time.NewTicker(c.opts.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.writeControl(opPing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// closePayload returns the payload of a close frame with code and reason, an empty one for CloseNoStatusReceived.
+func closePayload(code CloseCode, reason string) []byte {
+	if code == CloseNoStatusReceived {
+		return nil
+	}
+	payload := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(reason)), uint16(code))
+	return append(payload, reason...)
+}
@@ -0,0 +1,336 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer returns a server upgrading requests of /ws with opts and handling the connections with fn.
+func newTestServer(opts Options, fn func(req *http.Request[http.RequestBody], c *Conn)) *http.Server {
+	r := http.NewRouter()
+	r.Route(http.Get("/ws", Handler(opts, fn)))
+	return &http.Server{Handler: r}
+}
+
+// dialTest connects to s through a pipe.
+func dialTest(t *testing.T, s *http.Server, opts DialOptions) *Conn {
+	t.Helper()
+	opts.DialContext = s.DialPipe
+	c := Dial(context.Background(), "ws://example.com/ws", opts)
+	if c.IsErr() {
+		t.Fatal(c.UnwrapErr())
+	}
+	t.Cleanup(func() {
+		c.Unwrap().closeConn()
+	})
+	return c.Unwrap()
+}
+
+// echo sends back the messages of c until it's closed, the error ending the connection is sent to errs.
+func echo(errs chan<- error) func(req *http.Request[http.RequestBody], c *Conn) {
+	return func(req *http.Request[http.RequestBody], c *Conn) {
+		for {
+			msg := c.ReadMessage()
+			if msg.IsErr() {
+				if errs != nil {
+					errs <- msg.UnwrapErr()
+				}
+				return
+			}
+			c.WriteMessage(msg.Unwrap().Type, msg.Unwrap().Data)
+		}
+	}
+}
+
+// closeCode returns the code of the *CloseError err, 0 if it's none.
+func closeCode(err error) CloseCode {
+	var closeErr *CloseError
+	if errors.As(err, &closeErr) {
+		return closeErr.Code
+	}
+	return 0
+}
+
+func TestConn_Echo(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestServer(Options{}.Default(), echo(nil))
+	c := dialTest(t, s, DialOptions{}.Default())
+
+	assert.NoError(c.WriteMessage(TextMessage, []byte("hello")))
+	assert.Equal(Message{Type: TextMessage, Data: []byte("hello")}, c.ReadMessage().Unwrap())
+	assert.NoError(c.WriteMessage(BinaryMessage, []byte{0, 1, 2}))
+	assert.Equal(Message{Type: BinaryMessage, Data: []byte{0, 1, 2}}, c.ReadMessage().Unwrap())
+	assert.NoError(c.WriteMessage(BinaryMessage, nil))
+	assert.Equal(Message{Type: BinaryMessage, Data: []byte{}}, c.ReadMessage().Unwrap())
+	large := bytes.Repeat([]byte("x"), 100000)
+	assert.NoError(c.WriteMessage(BinaryMessage, large))
+	assert.Equal(large, c.ReadMessage().Unwrap().Data)
+
+	assert.Error(c.WriteMessage(MessageType(3), nil))
+	assert.Equal("pipe", c.RemoteAddr().String())
+	assert.Equal("", c.Subprotocol())
+}
+
+func TestConn_Fragmentation(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := Options{}.Default()
+	opts.FragmentSize = 3
+	s := newTestServer(opts, echo(nil))
+	dialOpts := DialOptions{}.Default()
+	dialOpts.FragmentSize = 4
+	c := dialTest(t, s, dialOpts)
+
+	// fragments sent by the client are assembled by the server, the echo is split into frames of 3 bytes
+	assert.NoError(c.WriteMessage(TextMessage, []byte("hello world")))
+	for i, expected := range []string{"hel", "lo ", "wor", "ld"} {
+		f, err := readFrame(c.r, false, uint64(DefaultMaxMessageSize))
+		assert.NoError(err)
+		assert.Equal(expected, string(f.payload))
+		assert.Equal(i == 3, f.fin)
+		if i == 0 {
+			assert.Equal(opText, f.opcode)
+		} else {
+			assert.Equal(opContinuation, f.opcode)
+		}
+	}
+
+	// control frames may be sent between fragments
+	writeFrame(c.w, frame{opcode: opBinary, payload: []byte("ab")}, true)
+	writeFrame(c.w, frame{fin: true, opcode: opPing, payload: []byte("ping")}, true)
+	writeFrame(c.w, frame{fin: true, opcode: opContinuation, payload: []byte("c")}, true)
+	c.w.Flush()
+	f, err := readFrame(c.r, false, uint64(DefaultMaxMessageSize))
+	assert.NoError(err)
+	assert.Equal(frame{fin: true, opcode: opPong, payload: []byte("ping")}, f)
+	assert.Equal(Message{Type: BinaryMessage, Data: []byte("abc")}, c.ReadMessage().Unwrap())
+}
+
+func TestConn_Ping(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestServer(Options{}.Default(), echo(nil))
+	c := dialTest(t, s, DialOptions{}.Default())
+
+	assert.NoError(c.Ping([]byte("data")))
+	f, err := readFrame(c.r, false, uint64(DefaultMaxMessageSize))
+	assert.NoError(err)
+	assert.Equal(frame{fin: true, opcode: opPong, payload: []byte("data")}, f)
+
+	assert.ErrorIs(c.Ping(make([]byte, 126)), ErrProtocol)
+
+	// pongs are skipped by ReadMessage
+	assert.NoError(c.Ping(nil))
+	assert.NoError(c.WriteMessage(TextMessage, []byte("after pong")))
+	assert.Equal("after pong", string(c.ReadMessage().Unwrap().Data))
+}
+
+func TestConn_KeepAlive(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := Options{}.Default()
+	opts.PingInterval = 20 * time.Millisecond
+	opts.PongTimeout = 20 * time.Millisecond
+	errs := make(chan error, 1)
+	s := newTestServer(opts, echo(errs))
+
+	// a client reading its messages answers the pings and keeps the connection alive
+	dialOpts := DialOptions{}.Default()
+	dialOpts.PingInterval = 0
+	c := dialTest(t, s, dialOpts)
+	read := make(chan Message)
+	go func() {
+		for {
+			msg := c.ReadMessage()
+			if msg.IsErr() {
+				close(read)
+				return
+			}
+			read <- msg.Unwrap()
+		}
+	}()
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(c.WriteMessage(TextMessage, []byte("alive")))
+	assert.Equal("alive", string((<-read).Data))
+	c.Close()
+	<-errs
+
+	// a client ignoring the pings is disconnected
+	c = dialTest(t, s, dialOpts)
+	go func() {
+		for {
+			if _, err := readFrame(c.r, false, uint64(DefaultMaxMessageSize)); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case err := <-errs:
+		var netErr net.Error
+		assert.True(errors.As(err, &netErr) && netErr.Timeout(), err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection wasn't closed")
+	}
+}
+
+func TestConn_Close(t *testing.T) {
+	assert := assert.New(t)
+
+	errs := make(chan error, 1)
+	s := newTestServer(Options{}.Default(), echo(errs))
+
+	// closed by the client
+	c := dialTest(t, s, DialOptions{}.Default())
+	assert.NoError(c.WriteMessage(TextMessage, []byte("before")))
+	assert.NoError(c.WriteClose(4000, "bye"))
+	assert.ErrorIs(c.WriteMessage(TextMessage, []byte("after")), ErrClosed)
+	assert.ErrorIs(c.WriteClose(CloseNormalClosure, ""), ErrClosed)
+	// messages sent before the close frame are still read
+	assert.Equal("before", string(c.ReadMessage().Unwrap().Data))
+	err := c.ReadMessage().UnwrapErr()
+	assert.Equal(&CloseError{Code: 4000}, err)
+	assert.Equal(&CloseError{Code: 4000, Reason: "bye"}, <-errs)
+	assert.Error(c.ReadMessage().UnwrapErr())
+
+	// closed by the server
+	s = newTestServer(Options{}.Default(), func(req *http.Request[http.RequestBody], c *Conn) {
+		c.WriteClose(CloseGoingAway, "restart")
+		errs <- c.ReadMessage().UnwrapErr()
+	})
+	c = dialTest(t, s, DialOptions{}.Default())
+	err = c.ReadMessage().UnwrapErr()
+	assert.Equal(&CloseError{Code: CloseGoingAway, Reason: "restart"}, err)
+	assert.Equal("websocket closed: 1001 (going away): restart", err.Error())
+	assert.Equal(&CloseError{Code: CloseGoingAway}, <-errs)
+
+	// the connection is closed normally when the handler returns
+	s = newTestServer(Options{}.Default(), func(req *http.Request[http.RequestBody], c *Conn) {})
+	c = dialTest(t, s, DialOptions{}.Default())
+	assert.Equal(CloseNormalClosure, closeCode(c.ReadMessage().UnwrapErr()))
+
+	// close frames without a code
+	s = newTestServer(Options{}.Default(), echo(errs))
+	c = dialTest(t, s, DialOptions{}.Default())
+	c.writeControl(opClose, nil)
+	assert.Equal(CloseNoStatusReceived, closeCode(<-errs))
+
+	assert.Error(c.WriteClose(CloseNoStatusReceived, ""))
+	assert.Error(c.WriteClose(999, ""))
+	assert.ErrorIs(c.WriteClose(4000, strings.Repeat("x", 124)), ErrProtocol)
+}
+
+func TestConn_Violations(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := Options{}.Default()
+	opts.MaxMessageSize = 10
+	errs := make(chan error, 1)
+	s := newTestServer(opts, echo(errs))
+
+	tests := []struct {
+		name   string
+		frames []frame
+		masked bool
+		code   CloseCode
+		err    error
+	}{
+		{"unmasked frame", []frame{{fin: true, opcode: opText}}, false, CloseProtocolError, ErrProtocol},
+		{"unknown opcode", []frame{{fin: true, opcode: 0x3}}, true, CloseProtocolError, ErrProtocol},
+		{"continuation without message", []frame{{fin: true, opcode: opContinuation}}, true, CloseProtocolError, ErrProtocol},
+		{"unfinished message", []frame{{opcode: opText}, {fin: true, opcode: opText}}, true, CloseProtocolError, ErrProtocol},
+		{"compressed without extension", []frame{{fin: true, rsv1: true, opcode: opText}}, true, CloseProtocolError, ErrProtocol},
+		{"invalid utf-8", []frame{{fin: true, opcode: opText, payload: []byte{'a', 0xff}}}, true, CloseInvalidPayloadData, ErrInvalidPayload},
+		{"split utf-8", []frame{{opcode: opText, payload: []byte{0xc3}}, {fin: true, opcode: opContinuation, payload: []byte{0xa4}}}, true, 0, nil},
+		{"too big", []frame{{fin: true, opcode: opBinary, payload: make([]byte, 11)}}, true, CloseMessageTooBig, ErrMessageTooBig},
+		{"too big fragments", []frame{{opcode: opBinary, payload: make([]byte, 6)}, {fin: true, opcode: opContinuation, payload: make([]byte, 5)}}, true, CloseMessageTooBig, ErrMessageTooBig},
+		{"invalid close code", []frame{{fin: true, opcode: opClose, payload: []byte{0x03, 0xed}}}, true, CloseProtocolError, ErrProtocol},
+		{"close payload of 1 byte", []frame{{fin: true, opcode: opClose, payload: []byte{0x03}}}, true, CloseProtocolError, ErrProtocol},
+		{"invalid close reason", []frame{{fin: true, opcode: opClose, payload: []byte{0x03, 0xe8, 0xff}}}, true, CloseInvalidPayloadData, ErrInvalidPayload},
+	}
+	for _, test := range tests {
+		c := dialTest(t, s, DialOptions{}.Default())
+		for _, f := range test.frames {
+			writeFrame(c.w, f, test.masked)
+		}
+		c.w.Flush()
+
+		if test.err == nil {
+			assert.Equal(Message{Type: TextMessage, Data: []byte("ä")}, c.ReadMessage().Unwrap(), test.name)
+			c.Close()
+			<-errs
+			continue
+		}
+		assert.Equal(test.code, closeCode(c.ReadMessage().UnwrapErr()), test.name)
+		assert.ErrorIs(<-errs, test.err, test.name)
+	}
+}
+
+func TestConn_Compression(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := Options{}.Default()
+	opts.Compression = true
+	opts.MaxMessageSize = 1000
+	errs := make(chan error, 1)
+	s := newTestServer(opts, echo(errs))
+
+	dialOpts := DialOptions{}.Default()
+	dialOpts.Compression = true
+	dialOpts.FragmentSize = 8
+	c := dialTest(t, s, dialOpts)
+	assert.True(c.compress)
+
+	data := bytes.Repeat([]byte("compressed "), 50)
+	assert.NoError(c.WriteMessage(TextMessage, data))
+	assert.Equal(Message{Type: TextMessage, Data: data}, c.ReadMessage().Unwrap())
+
+	// the compressed message is sent in a single frame with RSV1 set
+	assert.NoError(c.WriteMessage(TextMessage, data))
+	f, err := readFrame(c.r, false, uint64(DefaultMaxMessageSize))
+	assert.NoError(err)
+	assert.True(f.fin)
+	assert.True(f.rsv1)
+	assert.Less(len(f.payload), len(data))
+
+	// the limit applies to the decompressed message
+	assert.NoError(c.WriteMessage(BinaryMessage, make([]byte, 1001)))
+	assert.Equal(CloseMessageTooBig, closeCode(c.ReadMessage().UnwrapErr()))
+	assert.ErrorIs(<-errs, ErrMessageTooBig)
+
+	// compression is only used if both sides enable it
+	c = dialTest(t, s, DialOptions{}.Default())
+	assert.False(c.compress)
+	assert.NoError(c.WriteMessage(TextMessage, []byte("plain")))
+	assert.Equal("plain", string(c.ReadMessage().Unwrap().Data))
+
+	s = newTestServer(Options{}.Default(), echo(nil))
+	c = dialTest(t, s, dialOpts)
+	assert.False(c.compress)
+	assert.NoError(c.WriteMessage(TextMessage, []byte("plain")))
+	assert.Equal("plain", string(c.ReadMessage().Unwrap().Data))
+}
+
+func TestConn_HugeFrameLength(t *testing.T) {
+	assert := assert.New(t)
+
+	// a zero MaxMessageSize uses the default limit
+	opts := Options{}.Default()
+	opts.MaxMessageSize = 0
+	errs := make(chan error, 1)
+	s := newTestServer(opts, echo(errs))
+
+	c := dialTest(t, s, DialOptions{}.Default())
+	c.w.Write([]byte{0x82, 0x80 | 127, 0x40, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4})
+	c.w.Flush()
+	assert.Equal(CloseMessageTooBig, closeCode(c.ReadMessage().UnwrapErr()))
+	assert.ErrorIs(<-errs, ErrMessageTooBig)
+}
@@ -0,0 +1,185 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// opcode is the type of a frame.
+type opcode byte
+
+const (
+	opContinuation opcode = 0x0
+	opText         opcode = 0x1
+	opBinary       opcode = 0x2
+	opClose        opcode = 0x8
+	opPing         opcode = 0x9
+	opPong         opcode = 0xa
+)
+
+// control returns true for close, ping and pong frames, which may be sent between the fragments of a message.
+func (o opcode) control() bool {
+	return o&0x8 != 0
+}
+
+const (
+	finBit  = 0x80
+	rsv1Bit = 0x40
+	// rsv2Bit and rsv3Bit aren't used by any supported extension
+	rsv23Bits = 0x30
+	maskBit   = 0x80
+
+	// maxControlPayload is the maximum payload size of control frames.
+	maxControlPayload = 125
+	// payloadChunkSize is the size of the chunks payloads are read in, so the length in a frame header can't make the
+	// reader allocate more memory than the peer actually sent.
+	payloadChunkSize = 64 << 10
+)
+
+// frame is a WebSocket frame with an unmasked payload.
+type frame struct {
+	fin bool
+	// rsv1 marks the first frame of a compressed message
+	rsv1    bool
+	opcode  opcode
+	payload []byte
+}
+
+// readFrame reads a frame from r. Frames sent by clients must be masked, frames sent by servers mustn't, masked tells
+// which one is expected. The payload of data frames is limited to maxPayload bytes.
+func readFrame(r *bufio.Reader, masked bool, maxPayload uint64) (frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{fin: head[0]&finBit != 0, rsv1: head[0]&rsv1Bit != 0, opcode: opcode(head[0] & 0x0f)}
+	if head[0]&rsv23Bits != 0 {
+		return frame{}, fmt.Errorf("reserved bits set: %w", ErrProtocol)
+	}
+	if head[1]&maskBit == 0 && masked {
+		return frame{}, fmt.Errorf("unmasked client frame: %w", ErrProtocol)
+	}
+	if head[1]&maskBit != 0 && !masked {
+		return frame{}, fmt.Errorf("masked server frame: %w", ErrProtocol)
+	}
+
+	length := uint64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, unexpectedEOF(err)
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, unexpectedEOF(err)
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+		if length>>63 != 0 {
+			return frame{}, fmt.Errorf("invalid payload length: %w", ErrProtocol)
+		}
+	}
+
+	if f.opcode.control() {
+		if !f.fin {
+			return frame{}, fmt.Errorf("fragmented control frame: %w", ErrProtocol)
+		}
+		if length > maxControlPayload {
+			return frame{}, fmt.Errorf("control frame payload of %d bytes: %w", length, ErrProtocol)
+		}
+	} else if length > maxPayload {
+		return frame{}, ErrMessageTooBig
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return frame{}, unexpectedEOF(err)
+		}
+	}
+	payload, err := readPayload(r, length)
+	if err != nil {
+		return frame{}, err
+	}
+	f.payload = payload
+	if masked {
+		maskBytes(key, f.payload)
+	}
+	return f, nil
+}
+
+// readPayload reads a payload of length bytes in chunks of payloadChunkSize, the buffer grows as the data arrives.
+func readPayload(r io.Reader, length uint64) ([]byte, error) {
+	payload := make([]byte, 0, min(length, payloadChunkSize))
+	for uint64(len(payload)) < length {
+		start := len(payload)
+		n := int(min(length-uint64(start), payloadChunkSize))
+		payload = slices.Grow(payload, n)[:start+n]
+		if _, err := io.ReadFull(r, payload[start:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+	return payload, nil
+}
+
+// writeFrame writes f to w, masking the payload with a random key if mask is true. The payload of f isn't modified.
+func writeFrame(w *bufio.Writer, f frame, mask bool) error {
+	b0 := byte(f.opcode)
+	if f.fin {
+		b0 |= finBit
+	}
+	if f.rsv1 {
+		b0 |= rsv1Bit
+	}
+	var b1 byte
+	if mask {
+		b1 = maskBit
+	}
+
+	length := len(f.payload)
+	switch {
+	case length < 126:
+		w.Write([]byte{b0, b1 | byte(length)})
+	case length <= 0xffff:
+		w.Write([]byte{b0, b1 | 126, byte(length >> 8), byte(length)})
+	default:
+		head := []byte{b0, b1 | 127, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(head[2:], uint64(length))
+		w.Write(head)
+	}
+
+	if !mask {
+		_, err := w.Write(f.payload)
+		return err
+	}
+	var key [4]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return fmt.Errorf("generate mask failed: %w", err)
+	}
+	w.Write(key[:])
+	payload := append([]byte(nil), f.payload...)
+	maskBytes(key, payload)
+	_, err := w.Write(payload)
+	return err
+}
+
+// maskBytes masks or unmasks b with key.
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
+
+// unexpectedEOF turns io.EOF into io.ErrUnexpectedEOF, a connection closed in the middle of a frame is an error.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrame_RoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, size := range []int{0, 125, 126, 0xffff, 0x10000} {
+		for _, masked := range []bool{false, true} {
+			payload := bytes.Repeat([]byte{'a'}, size)
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			assert.NoError(writeFrame(w, frame{fin: true, rsv1: true, opcode: opBinary, payload: payload}, masked))
+			assert.NoError(w.Flush())
+			// the payload of the caller isn't masked in place
+			assert.Equal(bytes.Repeat([]byte{'a'}, size), payload)
+
+			f, err := readFrame(bufio.NewReader(&buf), masked, uint64(DefaultMaxMessageSize))
+			assert.NoError(err, "size %d masked %v", size, masked)
+			assert.True(f.fin)
+			assert.True(f.rsv1)
+			assert.Equal(opBinary, f.opcode)
+			assert.Equal(payload, f.payload)
+			assert.Equal(0, buf.Len())
+		}
+	}
+}
+
+func TestFrame_Header(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeFrame(w, frame{fin: true, opcode: opText, payload: []byte("hi")}, false)
+	writeFrame(w, frame{opcode: opText, payload: make([]byte, 126)}, false)
+	w.Flush()
+
+	assert.Equal([]byte{0x81, 0x02, 'h', 'i'}, buf.Bytes()[:4])
+	assert.Equal([]byte{0x01, 126, 0x00, 126}, buf.Bytes()[4:8])
+}
+
+func TestReadFrame_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	read := func(data []byte, masked bool, maxPayload uint64) error {
+		_, err := readFrame(bufio.NewReader(bytes.NewReader(data)), masked, maxPayload)
+		return err
+	}
+	max := uint64(DefaultMaxMessageSize)
+
+	// unmasked client frame, masked server frame
+	assert.ErrorIs(read([]byte{0x81, 0x00}, true, max), ErrProtocol)
+	assert.ErrorIs(read([]byte{0x81, 0x80, 0, 0, 0, 0}, false, max), ErrProtocol)
+	// reserved bits
+	assert.ErrorIs(read([]byte{0xa1, 0x00}, false, max), ErrProtocol)
+	// fragmented and oversized control frames
+	assert.ErrorIs(read([]byte{0x09, 0x00}, false, max), ErrProtocol)
+	assert.ErrorIs(read([]byte{0x89, 126, 0x00, 126}, false, max), ErrProtocol)
+	// payload length with the most significant bit set
+	assert.ErrorIs(read([]byte{0x82, 127, 0x80, 0, 0, 0, 0, 0, 0, 0}, false, max), ErrProtocol)
+	// data frames larger than the limit
+	assert.ErrorIs(read([]byte{0x82, 0x03, 1, 2, 3}, false, 2), ErrMessageTooBig)
+	assert.NoError(read([]byte{0x82, 0x03, 1, 2, 3}, false, 3))
+
+	// huge 64-bit lengths are rejected before allocating the payload
+	assert.ErrorIs(read([]byte{0x82, 127, 0x40, 0, 0, 0, 0, 0, 0, 0}, false, max), ErrMessageTooBig)
+	assert.ErrorIs(read([]byte{0x82, 127, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, false, math.MaxInt64), io.ErrUnexpectedEOF)
+
+	// truncated frames
+	assert.ErrorIs(read([]byte{}, false, max), io.EOF)
+	assert.True(errors.Is(read([]byte{0x82, 0x03, 1}, false, max), io.ErrUnexpectedEOF))
+	assert.True(errors.Is(read([]byte{0x82, 126, 0}, false, max), io.ErrUnexpectedEOF))
+}
+
+func TestReadPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	// the buffer grows with the data received, not with the announced length
+	payload, err := readPayload(bytes.NewReader(make([]byte, 10)), math.MaxInt64)
+	assert.ErrorIs(err, io.ErrUnexpectedEOF)
+	assert.Nil(payload)
+
+	data := bytes.Repeat([]byte("0123456789"), payloadChunkSize/4)
+	payload, err = readPayload(bytes.NewReader(data), uint64(len(data)))
+	assert.NoError(err)
+	assert.Equal(data, payload)
+	payload, err = readPayload(bytes.NewReader(nil), 0)
+	assert.NoError(err)
+	assert.Empty(payload)
+}
@@ -0,0 +1,126 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/marlaone/shepard/http"
+)
+
+// acceptGUID is appended to the key of a handshake request before hashing it into the Sec-WebSocket-Accept header.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// acceptKey returns the value of the Sec-WebSocket-Accept header answering key.
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Upgrade answers the WebSocket handshake request req, fn is called with the connection once the response was
+// written. The connection is closed when fn returns.
+//
+// Invalid handshakes are answered with 400 Bad Request or 405 Method Not Allowed, requests of other protocols or an
+// unsupported protocol version with 426 Upgrade Required and requests rejected by Options.CheckOrigin with 403 Forbidden.
+func Upgrade(req *http.Request[http.RequestBody], opts Options, fn func(c *Conn)) http.Response[http.Body] {
+	if req.Method != http.MethodGet {
+		res := http.Error(http.StatusCodeMethodNotAllowed, errors.New("websocket handshake must be a GET request"))
+		res.SetHeader("Allow", "GET")
+		return res
+	}
+	if req.Version == "1.0" {
+		return http.Error(http.StatusCodeBadRequest, errors.New("websocket handshake requires HTTP/1.1"))
+	}
+	if !hasToken(req.Headers.List("Upgrade"), "websocket") || !hasToken(req.Headers.List("Connection"), "upgrade") {
+		res := http.Error(http.StatusCodeUpgradeRequired, errors.New("websocket handshake required"))
+		res.SetHeader("Upgrade", "websocket")
+		res.SetHeader("Connection", "Upgrade")
+		return res
+	}
+	if req.Headers.Value("Sec-WebSocket-Version") != "13" {
+		res := http.Error(http.StatusCodeUpgradeRequired, errors.New("unsupported websocket version"))
+		res.SetHeader("Sec-WebSocket-Version", "13")
+		return res
+	}
+	key := strings.TrimSpace(req.Headers.Value("Sec-WebSocket-Key"))
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return http.Error(http.StatusCodeBadRequest, errors.New("invalid Sec-WebSocket-Key header"))
+	}
+	checkOrigin := opts.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(req) {
+		return http.Error(http.StatusCodeForbidden, errors.New("websocket origin not allowed"))
+	}
+
+	res := http.NewHttpResponseBytes()
+	res.SetStatusCode(http.StatusCodeSwitchingProtocols)
+	res.SetHeader("Upgrade", "websocket")
+	res.SetHeader("Connection", "Upgrade")
+	res.SetHeader("Sec-WebSocket-Accept", acceptKey(key))
+	subprotocol := selectSubprotocol(opts.Subprotocols, req.Headers.List("Sec-WebSocket-Protocol"))
+	if subprotocol != "" {
+		res.SetHeader("Sec-WebSocket-Protocol", subprotocol)
+	}
+	compress := opts.Compression && acceptDeflate(req.Headers.List("Sec-WebSocket-Extensions"))
+	if compress {
+		res.SetHeader("Sec-WebSocket-Extensions", deflateExtension)
+	}
+
+	return http.Hijack(res, func(conn net.Conn, r *bufio.Reader) {
+		c := newConn(conn, r, true, opts, subprotocol, compress)
+		defer c.Close()
+		fn(c)
+	})
+}
+
+// Handler returns a handler answering WebSocket handshakes with Upgrade, fn is called with the request and the
+// connection.
+func Handler(opts Options, fn func(req *http.Request[http.RequestBody], c *Conn)) http.Handler {
+	return func(req *http.Request[http.RequestBody]) http.Response[http.Body] {
+		return Upgrade(req, opts, func(c *Conn) {
+			fn(req, c)
+		})
+	}
+}
+
+// sameOrigin returns true if the Origin header of req is missing, as for clients other than browsers, or its host
+// matches the host of req.
+func sameOrigin(req *http.Request[http.RequestBody]) bool {
+	origin := req.Headers.Value("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, req.Headers.Value("Host"))
+}
+
+// selectSubprotocol returns the first supported subprotocol offered by the client.
+func selectSubprotocol(supported []string, offered []string) string {
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// hasToken returns true if values contain token, ignoring case.
+func hasToken(values []string, token string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package websocket
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/testutils/testhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func handshakeHeaders() map[string]string {
+	return map[string]string{
+		"Host":                  "example.com",
+		"Upgrade":               "websocket",
+		"Connection":            "keep-alive, Upgrade",
+		"Sec-WebSocket-Key":     "dGhlIHNhbXBsZSBub25jZQ==",
+		"Sec-WebSocket-Version": "13",
+	}
+}
+
+func TestAcceptKey(t *testing.T) {
+	// example of RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestUpgrade(t *testing.T) {
+	assert := assert.New(t)
+
+	headers := handshakeHeaders()
+	headers["Sec-WebSocket-Protocol"] = "chat.v1, chat.v2"
+	headers["Sec-WebSocket-Extensions"] = "permessage-deflate; client_max_window_bits"
+	opts := Options{}.Default()
+	opts.Subprotocols = []string{"chat.v2", "chat.v1"}
+	opts.Compression = true
+	res := Upgrade(testhttp.NewRequest(http.MethodGet, "/ws", headers), opts, func(c *Conn) {})
+
+	assert.Equal(http.StatusCodeSwitchingProtocols, res.StatusCode())
+	assert.Equal("websocket", res.Headers().Value("Upgrade"))
+	assert.Equal("Upgrade", res.Headers().Value("Connection"))
+	assert.Equal("s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Headers().Value("Sec-WebSocket-Accept"))
+	assert.Equal("chat.v2", res.Headers().Value("Sec-WebSocket-Protocol"))
+	assert.Equal(deflateExtension, res.Headers().Value("Sec-WebSocket-Extensions"))
+
+	// nothing is negotiated without support of the server
+	res = Upgrade(testhttp.NewRequest(http.MethodGet, "/ws", headers), Options{}.Default(), func(c *Conn) {})
+	assert.Equal(http.StatusCodeSwitchingProtocols, res.StatusCode())
+	assert.False(res.Headers().Has("Sec-WebSocket-Protocol"))
+	assert.False(res.Headers().Has("Sec-WebSocket-Extensions"))
+}
+
+func TestUpgrade_Rejected(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name    string
+		modify  func(req *http.Request[http.RequestBody])
+		status  http.StatusCode
+		headers map[string]string
+	}{
+		{"method", func(req *http.Request[http.RequestBody]) { req.Method = http.MethodPost }, http.StatusCodeMethodNotAllowed, map[string]string{"Allow": "GET"}},
+		{"http/1.0", func(req *http.Request[http.RequestBody]) { req.Version = "1.0" }, http.StatusCodeBadRequest, nil},
+		{"no upgrade", func(req *http.Request[http.RequestBody]) { req.Headers.Del("Upgrade") }, http.StatusCodeUpgradeRequired, map[string]string{"Upgrade": "websocket"}},
+		{"other protocol", func(req *http.Request[http.RequestBody]) { req.Headers.Set("Upgrade", "h2c") }, http.StatusCodeUpgradeRequired, nil},
+		{"no connection upgrade", func(req *http.Request[http.RequestBody]) { req.Headers.Set("Connection", "keep-alive") }, http.StatusCodeUpgradeRequired, nil},
+		{"version", func(req *http.Request[http.RequestBody]) { req.Headers.Set("Sec-WebSocket-Version", "8") }, http.StatusCodeUpgradeRequired, map[string]string{"Sec-WebSocket-Version": "13"}},
+		{"no key", func(req *http.Request[http.RequestBody]) { req.Headers.Del("Sec-WebSocket-Key") }, http.StatusCodeBadRequest, nil},
+		{"short key", func(req *http.Request[http.RequestBody]) { req.Headers.Set("Sec-WebSocket-Key", "c2hvcnQ=") }, http.StatusCodeBadRequest, nil},
+		{"cross origin", func(req *http.Request[http.RequestBody]) { req.Headers.Set("Origin", "https://evil.example") }, http.StatusCodeForbidden, nil},
+	}
+	for _, test := range tests {
+		req := testhttp.NewRequest(http.MethodGet, "/ws", handshakeHeaders())
+		test.modify(req)
+		called := false
+		res := Upgrade(req, Options{}.Default(), func(c *Conn) {
+			called = true
+		})
+		assert.Equal(test.status, res.StatusCode(), test.name)
+		for key, value := range test.headers {
+			assert.Equal(value, res.Headers().Value(key), test.name)
+		}
+		assert.False(called, test.name)
+	}
+}
+
+func TestUpgrade_CheckOrigin(t *testing.T) {
+	assert := assert.New(t)
+
+	headers := handshakeHeaders()
+	headers["Origin"] = "https://example.com"
+	res := Upgrade(testhttp.NewRequest(http.MethodGet, "/ws", headers), Options{}.Default(), func(c *Conn) {})
+	assert.Equal(http.StatusCodeSwitchingProtocols, res.StatusCode())
+
+	headers["Origin"] = "https://app.example"
+	res = Upgrade(testhttp.NewRequest(http.MethodGet, "/ws", headers), Options{}.Default(), func(c *Conn) {})
+	assert.Equal(http.StatusCodeForbidden, res.StatusCode())
+
+	opts := Options{}.Default()
+	opts.CheckOrigin = func(req *http.Request[http.RequestBody]) bool {
+		return req.Headers.Value("Origin") == "https://app.example"
+	}
+	res = Upgrade(testhttp.NewRequest(http.MethodGet, "/ws", headers), opts, func(c *Conn) {})
+	assert.Equal(http.StatusCodeSwitchingProtocols, res.StatusCode())
+}
+
+func TestHandler_Std(t *testing.T) {
+	assert := assert.New(t)
+
+	r := http.NewRouter()
+	r.Route(http.Get("/ws", Handler(Options{}.Default(), func(req *http.Request[http.RequestBody], c *Conn) {
+		c.WriteMessage(TextMessage, []byte("hello "+req.URL.RawQuery))
+		echo(nil)(req, c)
+	})))
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	c := Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/ws?name=std", DialOptions{}.Default())
+	if c.IsErr() {
+		t.Fatal(c.UnwrapErr())
+	}
+	conn := c.Unwrap()
+	defer conn.Close()
+	assert.Equal("hello name=std", string(conn.ReadMessage().Unwrap().Data))
+	assert.NoError(conn.WriteMessage(BinaryMessage, []byte{1, 2, 3}))
+	assert.Equal(Message{Type: BinaryMessage, Data: []byte{1, 2, 3}}, conn.ReadMessage().Unwrap())
+	assert.NoError(conn.WriteClose(CloseNormalClosure, ""))
+	assert.Equal(CloseNormalClosure, closeCode(conn.ReadMessage().UnwrapErr()))
+}
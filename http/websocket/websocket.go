@@ -0,0 +1,158 @@
+// Package websocket implements the WebSocket protocol of RFC 6455 on top of the http server: an upgrade handler taking
+// over the connection after the handshake, a client, fragmentation, ping/pong keepalive, close codes and the
+// permessage-deflate extension of RFC 7692.
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/marlaone/shepard/http"
+	"github.com/marlaone/shepard/num/unit"
+)
+
+// MessageType is the type of a data message.
+type MessageType int
+
+const (
+	// TextMessage is a message of UTF-8 encoded text.
+	TextMessage MessageType = 1
+	// BinaryMessage is a message of binary data.
+	BinaryMessage MessageType = 2
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case TextMessage:
+		return "text"
+	case BinaryMessage:
+		return "binary"
+	}
+	return fmt.Sprintf("MessageType(%d)", int(t))
+}
+
+// Message is a data message, possibly received in several fragments.
+type Message struct {
+	Type MessageType
+	Data []byte
+}
+
+// CloseCode is the status code of a close frame.
+type CloseCode uint16
+
+const (
+	CloseNormalClosure      CloseCode = 1000
+	CloseGoingAway          CloseCode = 1001
+	CloseProtocolError      CloseCode = 1002
+	CloseUnsupportedData    CloseCode = 1003
+	CloseNoStatusReceived   CloseCode = 1005
+	CloseAbnormalClosure    CloseCode = 1006
+	CloseInvalidPayloadData CloseCode = 1007
+	ClosePolicyViolation    CloseCode = 1008
+	CloseMessageTooBig      CloseCode = 1009
+	CloseMandatoryExtension CloseCode = 1010
+	CloseInternalServerErr  CloseCode = 1011
+)
+
+func (c CloseCode) String() string {
+	switch c {
+	case CloseNormalClosure:
+		return "normal closure"
+	case CloseGoingAway:
+		return "going away"
+	case CloseProtocolError:
+		return "protocol error"
+	case CloseUnsupportedData:
+		return "unsupported data"
+	case CloseNoStatusReceived:
+		return "no status received"
+	case CloseAbnormalClosure:
+		return "abnormal closure"
+	case CloseInvalidPayloadData:
+		return "invalid payload data"
+	case ClosePolicyViolation:
+		return "policy violation"
+	case CloseMessageTooBig:
+		return "message too big"
+	case CloseMandatoryExtension:
+		return "mandatory extension"
+	case CloseInternalServerErr:
+		return "internal server error"
+	}
+	return fmt.Sprintf("close code %d", uint16(c))
+}
+
+// sendable returns true if the code may be sent in a close frame. Codes 3000-4999 are free for libraries and applications.
+func (c CloseCode) sendable() bool {
+	switch {
+	case c >= 1000 && c <= 1003, c >= 1007 && c <= 1014:
+		return true
+	case c >= 3000 && c <= 4999:
+		return true
+	}
+	return false
+}
+
+// CloseError is returned by Conn.ReadMessage when the peer closed the connection, with the code and reason of its
+// close frame.
+type CloseError struct {
+	Code   CloseCode
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed: %d (%s)", uint16(e.Code), e.Code)
+	}
+	return fmt.Sprintf("websocket closed: %d (%s): %s", uint16(e.Code), e.Code, e.Reason)
+}
+
+var (
+	// ErrProtocol is returned for frames violating the protocol.
+	ErrProtocol = errors.New("websocket protocol error")
+	// ErrMessageTooBig is returned for messages exceeding Options.MaxMessageSize.
+	ErrMessageTooBig = errors.New("websocket message too big")
+	// ErrInvalidPayload is returned for text messages which aren't valid UTF-8 and corrupt compressed messages.
+	ErrInvalidPayload = errors.New("websocket invalid payload data")
+	// ErrClosed is returned for writes after the close frame was sent.
+	ErrClosed = errors.New("websocket closed")
+	// ErrHandshake is returned by Dial if the server rejected the handshake.
+	ErrHandshake = errors.New("websocket handshake failed")
+)
+
+// DefaultMaxMessageSize limits the size of received messages if Options.MaxMessageSize is zero.
+const DefaultMaxMessageSize = 32 * unit.MiB
+
+// Options configures WebSocket connections.
+type Options struct {
+	// Subprotocols are the supported subprotocols in order of preference. Servers choose the first one offered by the
+	// client, clients offer all of them.
+	Subprotocols []string
+	// Compression negotiates the permessage-deflate extension, compressing data messages if the peer supports it.
+	Compression bool
+	// CompressionLevel is the flate compression level.
+	CompressionLevel int
+	// MaxMessageSize limits the size of received messages after decompression, DefaultMaxMessageSize if zero.
+	MaxMessageSize unit.ByteSize
+	// FragmentSize is the payload size of the frames larger messages are split into, messages are sent in a single
+	// frame if it's 0.
+	FragmentSize int
+	// PingInterval is the interval of the pings keeping the connection alive, no pings are sent if it's 0.
+	PingInterval time.Duration
+	// PongTimeout is the time the peer has to answer a ping, the connection is closed if nothing is received in
+	// PingInterval plus PongTimeout.
+	PongTimeout time.Duration
+	// CheckOrigin decides if a handshake request is accepted, by default requests whose Origin header doesn't match their
+	// host are rejected. It's ignored by Dial.
+	CheckOrigin func(req *http.Request[http.RequestBody]) bool
+}
+
+func (o Options) Default() Options {
+	return Options{
+		CompressionLevel: 1,
+		MaxMessageSize:   DefaultMaxMessageSize,
+		PingInterval:     30 * time.Second,
+		PongTimeout:      10 * time.Second,
+	}
+}
@@ -0,0 +1,15 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+)
+
+// CheckedAbs computes the absolute value of num, checking for overflow. If overflow happens, shepard.None is returned.
+//
+// Overflow happens only for the smallest value of a signed integer type.
+func CheckedAbs[T Number](num T) shepard.Option[T] {
+	if num < 0 {
+		return CheckedNeg(num)
+	}
+	return shepard.Some(num)
+}
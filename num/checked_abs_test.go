@@ -0,0 +1,18 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedAbs(t *testing.T) {
+	assert.Equal(t, shepard.Some(5), num.CheckedAbs(-5))
+	assert.Equal(t, shepard.Some(5), num.CheckedAbs(5))
+	assert.Equal(t, shepard.None[int16](), num.CheckedAbs(int16(math.MinInt16)))
+	assert.Equal(t, shepard.Some[uint16](math.MaxUint16), num.CheckedAbs(uint16(math.MaxUint16)))
+	assert.Equal(t, shepard.Some(2.5), num.CheckedAbs(-2.5))
+}
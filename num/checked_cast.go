@@ -0,0 +1,24 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+)
+
+// CheckedCast converts num from From to To, checking that the value can be represented exactly by To.
+// If it can't, shepard.None is returned.
+//
+// Values are rejected if they are out of range of To, change their sign or lose precision, e.g. a fractional float cast to an integer type.
+// NaN can only be cast between floating point types.
+func CheckedCast[From Number, To Number](num From) shepard.Option[To] {
+	c := To(num)
+	if num != num {
+		if isFloat[To]() {
+			return shepard.Some(c)
+		}
+		return shepard.None[To]()
+	}
+	if From(c) != num || (num < 0) != (c < 0) {
+		return shepard.None[To]()
+	}
+	return shepard.Some(c)
+}
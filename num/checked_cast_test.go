@@ -0,0 +1,26 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedCast(t *testing.T) {
+	assert.Equal(t, shepard.Some[uint16](8080), num.CheckedCast[int64, uint16](8080))
+	assert.Equal(t, shepard.None[uint16](), num.CheckedCast[int64, uint16](65536))
+	assert.Equal(t, shepard.None[uint16](), num.CheckedCast[int64, uint16](-1))
+	assert.Equal(t, shepard.None[int64](), num.CheckedCast[uint64, int64](math.MaxUint64))
+	assert.Equal(t, shepard.Some[uint8](math.MaxInt8), num.CheckedCast[int8, uint8](math.MaxInt8))
+	assert.Equal(t, shepard.Some(42.0), num.CheckedCast[int, float64](42))
+	assert.Equal(t, shepard.Some(42), num.CheckedCast[float64, int](42))
+	assert.Equal(t, shepard.None[int](), num.CheckedCast[float64, int](1.5))
+	assert.Equal(t, shepard.None[int32](), num.CheckedCast[float64, int32](1e10))
+	assert.Equal(t, shepard.Some[float32](0.5), num.CheckedCast[float64, float32](0.5))
+	assert.Equal(t, shepard.None[float32](), num.CheckedCast[float64, float32](math.MaxFloat64))
+	assert.True(t, num.CheckedCast[float64, float32](math.NaN()).IsSome())
+	assert.Equal(t, shepard.None[int](), num.CheckedCast[float64, int](math.NaN()))
+}
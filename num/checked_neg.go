@@ -0,0 +1,22 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+)
+
+// CheckedNeg negates num, checking for overflow. If overflow happens, shepard.None is returned.
+//
+// Overflow happens when negating the smallest value of a signed type and when negating any non-zero value of an unsigned type.
+func CheckedNeg[T Number](num T) shepard.Option[T] {
+	if num == 0 {
+		return shepard.Some[T](0)
+	}
+	if !isSigned[T]() {
+		return shepard.None[T]()
+	}
+	c := -num
+	if !isFloat[T]() && c == num {
+		return shepard.None[T]()
+	}
+	return shepard.Some(c)
+}
@@ -0,0 +1,19 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedNeg(t *testing.T) {
+	assert.Equal(t, shepard.Some(-5), num.CheckedNeg(5))
+	assert.Equal(t, shepard.None[int8](), num.CheckedNeg(int8(math.MinInt8)))
+	assert.Equal(t, shepard.Some[int8](-math.MaxInt8), num.CheckedNeg(int8(math.MaxInt8)))
+	assert.Equal(t, shepard.Some[uint](0), num.CheckedNeg(uint(0)))
+	assert.Equal(t, shepard.None[uint](), num.CheckedNeg(uint(1)))
+	assert.Equal(t, shepard.Some(math.MaxFloat64), num.CheckedNeg(-math.MaxFloat64))
+}
@@ -0,0 +1,45 @@
+package num
+
+import (
+	"math"
+
+	"github.com/marlaone/shepard"
+)
+
+// CheckedPow raises num to the power of exp, checking for overflow. If overflow happens, shepard.None is returned.
+//
+// Floats overflow to ±Inf only, rounding doesn't count as overflow.
+func CheckedPow[T Number](num T, exp uint) shepard.Option[T] {
+	mul := CheckedMul[T]
+	if isFloat[T]() {
+		mul = checkedFloatMul[T]
+	}
+	result := shepard.Some[T](1)
+	base := num
+	for exp > 0 {
+		if exp&1 == 1 {
+			result = mul(result.Unwrap(), base)
+			if result.IsNone() {
+				return result
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			squared := mul(base, base)
+			if squared.IsNone() {
+				return squared
+			}
+			base = squared.Unwrap()
+		}
+	}
+	return result
+}
+
+// checkedFloatMul multiplies two floats, returning shepard.None if the product is infinite.
+func checkedFloatMul[T Number](num T, v T) shepard.Option[T] {
+	c := num * v
+	if math.IsInf(float64(c), 0) {
+		return shepard.None[T]()
+	}
+	return shepard.Some[T](c)
+}
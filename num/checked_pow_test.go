@@ -0,0 +1,28 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedPow(t *testing.T) {
+	assert.Equal(t, shepard.Some(1024), num.CheckedPow(2, 10))
+	assert.Equal(t, shepard.Some(-27), num.CheckedPow(-3, 3))
+	assert.Equal(t, shepard.Some(1), num.CheckedPow(7, 0))
+	assert.Equal(t, shepard.Some[uint8](128), num.CheckedPow(uint8(2), 7))
+	assert.Equal(t, shepard.None[uint8](), num.CheckedPow(uint8(2), 8))
+	assert.Equal(t, shepard.Some[int64](1e18), num.CheckedPow(int64(10), 18))
+	assert.Equal(t, shepard.None[int64](), num.CheckedPow(int64(10), 19))
+	assert.Equal(t, shepard.Some(2.25), num.CheckedPow(1.5, 2))
+	assert.Equal(t, shepard.None[float64](), num.CheckedPow(math.MaxFloat64, 2))
+	// rounded products aren't overflows
+	assert.InDelta(t, 0.01, num.CheckedPow(0.1, 2).Unwrap(), 1e-15)
+	assert.InDelta(t, 1.331, num.CheckedPow(1.1, 3).Unwrap(), 1e-15)
+	assert.InDelta(t, 0.09, num.CheckedPow(float32(0.3), 2).Unwrap(), 1e-6)
+	assert.Equal(t, shepard.None[float32](), num.CheckedPow(float32(1e20), 2))
+	assert.Equal(t, shepard.None[float64](), num.CheckedPow(-math.MaxFloat64, 3))
+}
@@ -0,0 +1,23 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+	"golang.org/x/exp/constraints"
+)
+
+// CheckedRem calculates the remainder of num divided by v, checking for division by zero and overflow. If any of that happens, shepard.None is returned.
+//
+// Overflow happens when the smallest value of a signed type is divided by -1.
+func CheckedRem[T constraints.Integer](num T, v T) shepard.Option[T] {
+	if v == 0 {
+		return shepard.None[T]()
+	}
+	var zero T
+	if isSigned[T]() && v == zero-1 {
+		if num != 0 && -num == num {
+			return shepard.None[T]()
+		}
+		return shepard.Some[T](0)
+	}
+	return shepard.Some(num % v)
+}
@@ -0,0 +1,19 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedRem(t *testing.T) {
+	assert.Equal(t, shepard.Some(1), num.CheckedRem(5, 2))
+	assert.Equal(t, shepard.Some(-1), num.CheckedRem(-5, 2))
+	assert.Equal(t, shepard.None[int](), num.CheckedRem(5, 0))
+	assert.Equal(t, shepard.None[int32](), num.CheckedRem(int32(math.MinInt32), -1))
+	assert.Equal(t, shepard.Some[int32](0), num.CheckedRem(int32(7), -1))
+	assert.Equal(t, shepard.Some[uint8](0), num.CheckedRem(uint8(math.MaxUint8), math.MaxUint8))
+}
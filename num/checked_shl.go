@@ -0,0 +1,18 @@
+package num
+
+import (
+	"unsafe"
+
+	"github.com/marlaone/shepard"
+	"golang.org/x/exp/constraints"
+)
+
+// CheckedShl shifts num left by n bits, checking if n is larger than or equal to the number of bits in T. If it is, shepard.None is returned.
+//
+// Like Rust's checked_shl this does not check if bits are shifted out of num.
+func CheckedShl[T constraints.Integer](num T, n uint) shepard.Option[T] {
+	if n >= uint(unsafe.Sizeof(num))*8 {
+		return shepard.None[T]()
+	}
+	return shepard.Some(num << n)
+}
@@ -0,0 +1,16 @@
+package num_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedShl(t *testing.T) {
+	assert.Equal(t, shepard.Some[uint8](128), num.CheckedShl(uint8(1), 7))
+	assert.Equal(t, shepard.None[uint8](), num.CheckedShl(uint8(1), 8))
+	assert.Equal(t, shepard.Some[int32](-2147483648), num.CheckedShl(int32(1), 31))
+	assert.Equal(t, shepard.None[int64](), num.CheckedShl(int64(1), 64))
+}
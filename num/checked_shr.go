@@ -0,0 +1,16 @@
+package num
+
+import (
+	"unsafe"
+
+	"github.com/marlaone/shepard"
+	"golang.org/x/exp/constraints"
+)
+
+// CheckedShr shifts num right by n bits, checking if n is larger than or equal to the number of bits in T. If it is, shepard.None is returned.
+func CheckedShr[T constraints.Integer](num T, n uint) shepard.Option[T] {
+	if n >= uint(unsafe.Sizeof(num))*8 {
+		return shepard.None[T]()
+	}
+	return shepard.Some(num >> n)
+}
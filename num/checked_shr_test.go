@@ -0,0 +1,16 @@
+package num_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedShr(t *testing.T) {
+	assert.Equal(t, shepard.Some[uint8](1), num.CheckedShr(uint8(128), 7))
+	assert.Equal(t, shepard.None[uint8](), num.CheckedShr(uint8(128), 8))
+	assert.Equal(t, shepard.Some[int16](-2), num.CheckedShr(int16(-8), 2))
+	assert.Equal(t, shepard.None[uint32](), num.CheckedShr(uint32(1), 32))
+}
@@ -0,0 +1,170 @@
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/marlaone/shepard"
+)
+
+// BigInt is an immutable arbitrary-precision integer with the same checked API as Decimal.
+//
+// Every operation returns a new BigInt, the zero value is 0.
+type BigInt struct {
+	v *big.Int
+}
+
+// NewBigInt creates a BigInt of value.
+func NewBigInt(value int64) BigInt {
+	return BigInt{v: big.NewInt(value)}
+}
+
+// FromBig creates a BigInt holding a copy of v.
+func FromBig(v *big.Int) BigInt {
+	return BigInt{v: new(big.Int).Set(v)}
+}
+
+func (b BigInt) Default() BigInt {
+	return BigInt{}
+}
+
+// ParseBigInt parses a base 10 integer like "-12345678901234567890".
+func ParseBigInt(s string) shepard.Result[BigInt, error] {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return shepard.Err[BigInt, error](fmt.Errorf("decimal: invalid integer %q", s))
+	}
+	return shepard.Ok[BigInt, error](BigInt{v: v})
+}
+
+func (b BigInt) big() *big.Int {
+	if b.v == nil {
+		return new(big.Int)
+	}
+	return b.v
+}
+
+// Big returns a copy of the value as *big.Int.
+func (b BigInt) Big() *big.Int {
+	return new(big.Int).Set(b.big())
+}
+
+func (b BigInt) String() string {
+	return b.big().String()
+}
+
+// Int64 returns the value as int64, or shepard.None if it doesn't fit.
+func (b BigInt) Int64() shepard.Option[int64] {
+	if !b.big().IsInt64() {
+		return shepard.None[int64]()
+	}
+	return shepard.Some(b.big().Int64())
+}
+
+// Uint64 returns the value as uint64, or shepard.None if it doesn't fit.
+func (b BigInt) Uint64() shepard.Option[uint64] {
+	if !b.big().IsUint64() {
+		return shepard.None[uint64]()
+	}
+	return shepard.Some(b.big().Uint64())
+}
+
+// Decimal converts the BigInt to a Decimal with a scale of 0, or shepard.None if it doesn't fit.
+func (b BigInt) Decimal() shepard.Option[Decimal] {
+	return fromBig(b.big(), 0)
+}
+
+// Sign returns -1 if the BigInt is negative, 0 if it is zero and +1 if it is positive.
+func (b BigInt) Sign() int {
+	return b.big().Sign()
+}
+
+// IsZero returns true if the BigInt is zero.
+func (b BigInt) IsZero() bool {
+	return b.Sign() == 0
+}
+
+// Cmp compares two BigInt`s and returns -1 if b < o, 0 if b == o and +1 if b > o.
+func (b BigInt) Cmp(o BigInt) int {
+	return b.big().Cmp(o.big())
+}
+
+// Equal returns true if both BigInt`s hold the same value.
+func (b BigInt) Equal(o BigInt) bool {
+	return b.Cmp(o) == 0
+}
+
+// CheckedAdd adds two BigInt`s. A BigInt can't overflow, so the result is always shepard.Some.
+func (b BigInt) CheckedAdd(o BigInt) shepard.Option[BigInt] {
+	return shepard.Some(BigInt{v: new(big.Int).Add(b.big(), o.big())})
+}
+
+// CheckedSub subtracts two BigInt`s. A BigInt can't overflow, so the result is always shepard.Some.
+func (b BigInt) CheckedSub(o BigInt) shepard.Option[BigInt] {
+	return shepard.Some(BigInt{v: new(big.Int).Sub(b.big(), o.big())})
+}
+
+// CheckedMul multiplies two BigInt`s. A BigInt can't overflow, so the result is always shepard.Some.
+func (b BigInt) CheckedMul(o BigInt) shepard.Option[BigInt] {
+	return shepard.Some(BigInt{v: new(big.Int).Mul(b.big(), o.big())})
+}
+
+// CheckedDiv divides two BigInt`s, truncating towards zero. If o is zero, shepard.None is returned.
+func (b BigInt) CheckedDiv(o BigInt) shepard.Option[BigInt] {
+	if o.IsZero() {
+		return shepard.None[BigInt]()
+	}
+	return shepard.Some(BigInt{v: new(big.Int).Quo(b.big(), o.big())})
+}
+
+// CheckedRem calculates the remainder of b divided by o with the sign of b. If o is zero, shepard.None is returned.
+func (b BigInt) CheckedRem(o BigInt) shepard.Option[BigInt] {
+	if o.IsZero() {
+		return shepard.None[BigInt]()
+	}
+	return shepard.Some(BigInt{v: new(big.Int).Rem(b.big(), o.big())})
+}
+
+// CheckedNeg negates the BigInt. A BigInt can't overflow, so the result is always shepard.Some.
+func (b BigInt) CheckedNeg() shepard.Option[BigInt] {
+	return shepard.Some(BigInt{v: new(big.Int).Neg(b.big())})
+}
+
+// CheckedAbs computes the absolute value of the BigInt. A BigInt can't overflow, so the result is always shepard.Some.
+func (b BigInt) CheckedAbs() shepard.Option[BigInt] {
+	return shepard.Some(BigInt{v: new(big.Int).Abs(b.big())})
+}
+
+// CheckedPow raises the BigInt to the power of exp. A BigInt can't overflow, so the result is always shepard.Some.
+func (b BigInt) CheckedPow(exp uint) shepard.Option[BigInt] {
+	return shepard.Some(BigInt{v: new(big.Int).Exp(b.big(), new(big.Int).SetUint64(uint64(exp)), nil)})
+}
+
+func (b BigInt) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *BigInt) UnmarshalText(text []byte) error {
+	res := ParseBigInt(string(text))
+	if res.IsErr() {
+		return res.UnwrapErr()
+	}
+	*b = res.Unwrap()
+	return nil
+}
+
+// MarshalJSON encodes the BigInt as a JSON number.
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return b.MarshalText()
+}
+
+// UnmarshalJSON decodes a BigInt from a JSON number or string. Like the types of encoding/json, null leaves b unchanged.
+func (b *BigInt) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		return nil
+	}
+	if len(text) > 1 && text[0] == '"' && text[len(text)-1] == '"' {
+		text = text[1 : len(text)-1]
+	}
+	return b.UnmarshalText(text)
+}
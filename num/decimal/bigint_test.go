@@ -0,0 +1,77 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBigInt(t *testing.T) {
+	v := decimal.ParseBigInt("-123456789012345678901234567890").Unwrap()
+	assert.Equal(t, "-123456789012345678901234567890", v.String())
+	assert.Equal(t, -1, v.Sign())
+	assert.True(t, decimal.ParseBigInt("12a").IsErr())
+	assert.True(t, decimal.ParseBigInt("").IsErr())
+}
+
+func TestBigInt_Zero(t *testing.T) {
+	var zero decimal.BigInt
+	assert.True(t, zero.IsZero())
+	assert.Equal(t, "0", zero.String())
+	assert.True(t, zero.CheckedAdd(decimal.NewBigInt(1)).Unwrap().Equal(decimal.NewBigInt(1)))
+}
+
+func TestBigInt_Checked(t *testing.T) {
+	a := decimal.NewBigInt(math.MaxInt64)
+	b := decimal.NewBigInt(2)
+
+	sum := a.CheckedAdd(b).Unwrap()
+	assert.Equal(t, "9223372036854775809", sum.String())
+	assert.True(t, sum.Int64().IsNone())
+	assert.Equal(t, shepard.Some[uint64](9223372036854775809), sum.Uint64())
+
+	assert.Equal(t, "9223372036854775805", a.CheckedSub(b).Unwrap().String())
+	assert.Equal(t, "18446744073709551614", a.CheckedMul(b).Unwrap().String())
+	assert.Equal(t, "4611686018427387903", a.CheckedDiv(b).Unwrap().String())
+	assert.Equal(t, "1", a.CheckedRem(b).Unwrap().String())
+	assert.Equal(t, "-9223372036854775807", a.CheckedNeg().Unwrap().String())
+	assert.Equal(t, "9223372036854775807", a.CheckedNeg().Unwrap().CheckedAbs().Unwrap().String())
+	assert.Equal(t, "1267650600228229401496703205376", b.CheckedPow(100).Unwrap().String())
+
+	assert.True(t, a.CheckedDiv(decimal.BigInt{}).IsNone())
+	assert.True(t, a.CheckedRem(decimal.NewBigInt(0)).IsNone())
+}
+
+func TestBigInt_Conversions(t *testing.T) {
+	v := decimal.FromBig(big.NewInt(42))
+	assert.Equal(t, shepard.Some[int64](42), v.Int64())
+	assert.Equal(t, shepard.Some(decimal.FromInt(42)), v.Decimal())
+	assert.Equal(t, 0, v.Big().Cmp(big.NewInt(42)))
+	assert.Equal(t, -1, decimal.NewBigInt(-1).Cmp(v))
+}
+
+func TestBigInt_JSON(t *testing.T) {
+	b, err := json.Marshal(struct {
+		V decimal.BigInt `json:"v"`
+	}{V: decimal.ParseBigInt("123456789012345678901234567890").Unwrap()})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"v":123456789012345678901234567890}`, string(b))
+
+	var decoded struct {
+		V decimal.BigInt `json:"v"`
+		S decimal.BigInt `json:"s"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"v":123456789012345678901234567890,"s":"-5"}`), &decoded))
+	assert.Equal(t, "123456789012345678901234567890", decoded.V.String())
+	assert.Equal(t, "-5", decoded.S.String())
+
+	// null is a no-op
+	assert.NoError(t, json.Unmarshal([]byte(`{"v":null,"s":null}`), &decoded))
+	assert.Equal(t, "123456789012345678901234567890", decoded.V.String())
+	assert.Equal(t, "-5", decoded.S.String())
+}
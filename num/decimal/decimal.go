@@ -0,0 +1,265 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/marlaone/shepard"
+)
+
+// MaxScale is the maximum number of fractional digits a Decimal can hold.
+const MaxScale = 18
+
+// Decimal is a fixed-point decimal number with up to MaxScale fractional digits.
+//
+// The value is stored as an int64 which is scaled by 10^-scale, so Decimal`s never suffer from the representation errors of floats.
+// Arithmetic is checked, operations that would overflow the int64 return shepard.None.
+type Decimal struct {
+	value int64
+	scale uint8
+}
+
+// New creates a Decimal of value * 10^-scale, e.g. New(1234, 2) is 12.34.
+//
+// Panics if scale is larger than MaxScale.
+func New(value int64, scale uint8) Decimal {
+	if scale > MaxScale {
+		panic(fmt.Errorf("decimal scale %d exceeds maximum scale of %d", scale, MaxScale))
+	}
+	return Decimal{
+		value: value,
+		scale: scale,
+	}
+}
+
+// FromInt creates a Decimal with a scale of 0.
+func FromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// Zero returns a Decimal of 0 with the given scale.
+func Zero(scale uint8) Decimal {
+	return New(0, scale)
+}
+
+func (d Decimal) Default() Decimal {
+	return Decimal{}
+}
+
+// fromBig converts the unscaled value v to a Decimal, returning shepard.None if v doesn't fit.
+func fromBig(v *big.Int, scale uint8) shepard.Option[Decimal] {
+	if !v.IsInt64() || scale > MaxScale {
+		return shepard.None[Decimal]()
+	}
+	return shepard.Some(New(v.Int64(), scale))
+}
+
+func (d Decimal) big() *big.Int {
+	return big.NewInt(d.value)
+}
+
+// Parse parses a decimal number like "-12.340". The scale of the Decimal is the number of fractional digits in s.
+//
+// Parse and String round-trip, Parse(d.String()) returns d including its scale.
+func Parse(s string) shepard.Result[Decimal, error] {
+	if s == "" {
+		return shepard.Err[Decimal, error](errors.New("decimal: empty string"))
+	}
+	digits := s
+	negative := false
+	if digits[0] == '-' || digits[0] == '+' {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+	intPart, fracPart, hasDot := strings.Cut(digits, ".")
+	if intPart == "" && fracPart == "" {
+		return shepard.Err[Decimal, error](fmt.Errorf("decimal: invalid syntax %q", s))
+	}
+	if hasDot && fracPart == "" {
+		return shepard.Err[Decimal, error](fmt.Errorf("decimal: invalid syntax %q", s))
+	}
+	if len(fracPart) > MaxScale {
+		return shepard.Err[Decimal, error](fmt.Errorf("decimal: %q exceeds maximum scale of %d", s, MaxScale))
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return shepard.Err[Decimal, error](fmt.Errorf("decimal: invalid syntax %q", s))
+		}
+	}
+	v, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if negative {
+		v.Neg(v)
+	}
+	res := fromBig(v, uint8(len(fracPart)))
+	if res.IsNone() {
+		return shepard.Err[Decimal, error](fmt.Errorf("decimal: %q out of range", s))
+	}
+	return shepard.Ok[Decimal, error](res.Unwrap())
+}
+
+// String formats the Decimal with exactly Scale fractional digits.
+func (d Decimal) String() string {
+	digits := d.big()
+	sign := ""
+	if digits.Sign() < 0 {
+		sign = "-"
+		digits.Neg(digits)
+	}
+	s := digits.String()
+	if d.scale == 0 {
+		return sign + s
+	}
+	scale := int(d.scale)
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// Scale returns the number of fractional digits.
+func (d Decimal) Scale() uint8 {
+	return d.scale
+}
+
+// Unscaled returns the unscaled integer value, e.g. 1234 for 12.34.
+func (d Decimal) Unscaled() int64 {
+	return d.value
+}
+
+// Sign returns -1 if the Decimal is negative, 0 if it is zero and +1 if it is positive.
+func (d Decimal) Sign() int {
+	switch {
+	case d.value < 0:
+		return -1
+	case d.value > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero returns true if the Decimal is zero, regardless of its scale.
+func (d Decimal) IsZero() bool {
+	return d.value == 0
+}
+
+// Cmp compares two Decimal`s numerically and returns -1 if d < o, 0 if d == o and +1 if d > o.
+func (d Decimal) Cmp(o Decimal) int {
+	scale := maxScale(d.scale, o.scale)
+	a := new(big.Int).Mul(d.big(), pow10(int(scale-d.scale)))
+	b := new(big.Int).Mul(o.big(), pow10(int(scale-o.scale)))
+	return a.Cmp(b)
+}
+
+// Equal returns true if both Decimal`s have the same numeric value, 1.5 and 1.50 are equal.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Rescale changes the scale of the Decimal, rounding according to mode if digits are dropped.
+//
+// Returns shepard.None if the rescaled value overflows or scale is larger than MaxScale.
+func (d Decimal) Rescale(scale uint8, mode RoundingMode) shepard.Option[Decimal] {
+	if scale >= d.scale {
+		return fromBig(new(big.Int).Mul(d.big(), pow10(int(scale-d.scale))), scale)
+	}
+	return fromBig(quo(d.big(), pow10(int(d.scale-scale)), mode), scale)
+}
+
+// CheckedAdd adds two Decimal`s, checking for overflow. If overflow happens, shepard.None is returned.
+//
+// The result has the larger scale of both operands.
+func (d Decimal) CheckedAdd(o Decimal) shepard.Option[Decimal] {
+	scale := maxScale(d.scale, o.scale)
+	a := new(big.Int).Mul(d.big(), pow10(int(scale-d.scale)))
+	b := new(big.Int).Mul(o.big(), pow10(int(scale-o.scale)))
+	return fromBig(a.Add(a, b), scale)
+}
+
+// CheckedSub subtracts two Decimal`s, checking for overflow. If overflow happens, shepard.None is returned.
+//
+// The result has the larger scale of both operands.
+func (d Decimal) CheckedSub(o Decimal) shepard.Option[Decimal] {
+	scale := maxScale(d.scale, o.scale)
+	a := new(big.Int).Mul(d.big(), pow10(int(scale-d.scale)))
+	b := new(big.Int).Mul(o.big(), pow10(int(scale-o.scale)))
+	return fromBig(a.Sub(a, b), scale)
+}
+
+// CheckedMul multiplies two Decimal`s, checking for overflow. If overflow happens, shepard.None is returned.
+//
+// The result has the larger scale of both operands, dropped digits are rounded according to mode.
+func (d Decimal) CheckedMul(o Decimal, mode RoundingMode) shepard.Option[Decimal] {
+	scale := maxScale(d.scale, o.scale)
+	product := new(big.Int).Mul(d.big(), o.big())
+	return fromBig(quo(product, pow10(int(d.scale+o.scale-scale)), mode), scale)
+}
+
+// CheckedDiv divides two Decimal`s, checking for division by zero and overflow. If any of that happens, shepard.None is returned.
+//
+// The result has the given scale, dropped digits are rounded according to mode.
+func (d Decimal) CheckedDiv(o Decimal, scale uint8, mode RoundingMode) shepard.Option[Decimal] {
+	if o.IsZero() || scale > MaxScale {
+		return shepard.None[Decimal]()
+	}
+	n := new(big.Int).Mul(d.big(), pow10(int(scale)+int(o.scale)))
+	q := new(big.Int).Mul(o.big(), pow10(int(d.scale)))
+	return fromBig(quo(n, q, mode), scale)
+}
+
+// CheckedNeg negates the Decimal, checking for overflow. If overflow happens, shepard.None is returned.
+func (d Decimal) CheckedNeg() shepard.Option[Decimal] {
+	return fromBig(new(big.Int).Neg(d.big()), d.scale)
+}
+
+// CheckedAbs computes the absolute value of the Decimal, checking for overflow. If overflow happens, shepard.None is returned.
+func (d Decimal) CheckedAbs() shepard.Option[Decimal] {
+	if d.value < 0 {
+		return d.CheckedNeg()
+	}
+	return shepard.Some(d)
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(b []byte) error {
+	res := Parse(string(b))
+	if res.IsErr() {
+		return res.UnwrapErr()
+	}
+	*d = res.Unwrap()
+	return nil
+}
+
+// MarshalJSON encodes the Decimal as a JSON string to not lose precision in consumers that decode numbers as floats.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a Decimal from a JSON string or number. Like the types of encoding/json, null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(b)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+func maxScale(a uint8, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
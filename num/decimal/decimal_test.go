@@ -0,0 +1,142 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num/decimal"
+	"github.com/marlaone/shepard/shepard_json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	d := decimal.New(1234, 2)
+	assert.Equal(t, int64(1234), d.Unscaled())
+	assert.Equal(t, uint8(2), d.Scale())
+	assert.Equal(t, "12.34", d.String())
+
+	assert.Panics(t, func() { decimal.New(1, decimal.MaxScale+1) })
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected decimal.Decimal
+	}{
+		{"0", decimal.FromInt(0)},
+		{"12.34", decimal.New(1234, 2)},
+		{"-12.340", decimal.New(-12340, 3)},
+		{"+1", decimal.FromInt(1)},
+		{".5", decimal.New(5, 1)},
+		{"-0.05", decimal.New(-5, 2)},
+		{"9223372036854775807", decimal.FromInt(math.MaxInt64)},
+		{"-9.223372036854775808", decimal.New(math.MinInt64, 18)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, shepard.Ok[decimal.Decimal, error](tc.expected), decimal.Parse(tc.input))
+		})
+	}
+
+	for _, input := range []string{"", "-", "1.", "1.2.3", "1e5", "abc", "9223372036854775808", "0.1234567890123456789"} {
+		t.Run(input, func(t *testing.T) {
+			assert.True(t, decimal.Parse(input).IsErr())
+		})
+	}
+}
+
+func TestDecimal_String_RoundTrip(t *testing.T) {
+	for _, input := range []string{"0", "0.00", "12.34", "-12.340", "0.05", "-0.000000000000000001", "100"} {
+		d := decimal.Parse(input).Unwrap()
+		assert.Equal(t, input, d.String())
+		assert.Equal(t, d, decimal.Parse(d.String()).Unwrap())
+	}
+}
+
+func TestDecimal_Cmp(t *testing.T) {
+	assert.True(t, decimal.New(15, 1).Equal(decimal.New(150, 2)))
+	assert.Equal(t, -1, decimal.New(-1, 0).Cmp(decimal.New(1, 5)))
+	assert.Equal(t, 1, decimal.New(101, 2).Cmp(decimal.FromInt(1)))
+	assert.Equal(t, 0, decimal.Zero(2).Sign())
+	assert.True(t, decimal.Zero(3).IsZero())
+}
+
+func TestDecimal_Rescale(t *testing.T) {
+	d := decimal.New(125, 2)
+	assert.Equal(t, shepard.Some(decimal.New(1250, 3)), d.Rescale(3, decimal.RoundDown))
+	assert.Equal(t, shepard.Some(decimal.New(12, 1)), d.Rescale(1, decimal.RoundHalfEven))
+	assert.Equal(t, shepard.Some(decimal.New(13, 1)), d.Rescale(1, decimal.RoundHalfUp))
+	assert.Equal(t, shepard.Some(decimal.New(12, 1)), d.Rescale(1, decimal.RoundDown))
+	assert.Equal(t, shepard.Some(decimal.New(14, 1)), decimal.New(135, 2).Rescale(1, decimal.RoundHalfEven))
+	assert.Equal(t, shepard.Some(decimal.New(-13, 1)), decimal.New(-125, 2).Rescale(1, decimal.RoundHalfUp))
+	assert.Equal(t, shepard.Some(decimal.New(-12, 1)), decimal.New(-125, 2).Rescale(1, decimal.RoundHalfEven))
+	assert.Equal(t, shepard.Some(decimal.New(-12, 1)), decimal.New(-126, 2).Rescale(1, decimal.RoundDown))
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(math.MaxInt64).Rescale(1, decimal.RoundDown))
+	assert.Equal(t, shepard.None[decimal.Decimal](), d.Rescale(decimal.MaxScale+1, decimal.RoundDown))
+}
+
+func TestDecimal_CheckedAdd(t *testing.T) {
+	assert.Equal(t, shepard.Some(decimal.New(1334, 2)), decimal.New(1234, 2).CheckedAdd(decimal.FromInt(1)))
+	assert.Equal(t, shepard.Some(decimal.New(3, 1)), decimal.New(1, 1).CheckedAdd(decimal.New(2, 1)))
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(math.MaxInt64).CheckedAdd(decimal.FromInt(1)))
+}
+
+func TestDecimal_CheckedSub(t *testing.T) {
+	assert.Equal(t, shepard.Some(decimal.New(-66, 2)), decimal.New(1, 1).CheckedSub(decimal.New(76, 2)))
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(math.MinInt64).CheckedSub(decimal.FromInt(1)))
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(0).CheckedSub(decimal.FromInt(math.MinInt64)))
+	// the negation of MinInt64 overflows, the difference doesn't
+	assert.Equal(t, shepard.Some(decimal.New(math.MaxInt64, 0)), decimal.New(-1, 0).CheckedSub(decimal.New(math.MinInt64, 0)))
+	assert.Equal(t, shepard.Some(decimal.New(-5, 1)), decimal.New(math.MinInt64, 1).CheckedSub(decimal.New(math.MinInt64, 1).CheckedAdd(decimal.New(5, 1)).Unwrap()))
+}
+
+func TestDecimal_CheckedMul(t *testing.T) {
+	price := decimal.New(1999, 2)
+	assert.Equal(t, shepard.Some(decimal.New(5997, 2)), price.CheckedMul(decimal.FromInt(3), decimal.RoundHalfEven))
+	// 19.99 * 0.075 = 1.49925
+	assert.Equal(t, shepard.Some(decimal.New(1499, 3)), price.CheckedMul(decimal.New(75, 3), decimal.RoundDown))
+	assert.Equal(t, shepard.Some(decimal.New(1499, 3)), price.CheckedMul(decimal.New(75, 3), decimal.RoundHalfEven))
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(math.MaxInt64).CheckedMul(decimal.FromInt(2), decimal.RoundDown))
+}
+
+func TestDecimal_CheckedDiv(t *testing.T) {
+	assert.Equal(t, shepard.Some(decimal.New(333, 2)), decimal.FromInt(10).CheckedDiv(decimal.FromInt(3), 2, decimal.RoundHalfEven))
+	assert.Equal(t, shepard.Some(decimal.New(667, 2)), decimal.FromInt(20).CheckedDiv(decimal.FromInt(3), 2, decimal.RoundHalfUp))
+	assert.Equal(t, shepard.Some(decimal.New(666, 2)), decimal.FromInt(20).CheckedDiv(decimal.FromInt(3), 2, decimal.RoundDown))
+	assert.Equal(t, shepard.Some(decimal.New(-25, 1)), decimal.New(5, 1).CheckedDiv(decimal.New(-2, 1), 1, decimal.RoundDown))
+	assert.Equal(t, shepard.Some(decimal.New(2, 0)), decimal.New(25, 1).CheckedDiv(decimal.FromInt(1), 0, decimal.RoundHalfEven))
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(1).CheckedDiv(decimal.Zero(2), 2, decimal.RoundDown))
+}
+
+func TestDecimal_CheckedNeg(t *testing.T) {
+	assert.Equal(t, shepard.Some(decimal.New(-5, 1)), decimal.New(5, 1).CheckedNeg())
+	assert.Equal(t, shepard.None[decimal.Decimal](), decimal.FromInt(math.MinInt64).CheckedNeg())
+	assert.Equal(t, shepard.Some(decimal.New(5, 1)), decimal.New(-5, 1).CheckedAbs())
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	type invoice struct {
+		Total    decimal.Decimal                       `json:"total"`
+		Discount *shepard_json.Option[decimal.Decimal] `json:"discount"`
+	}
+
+	b, err := json.Marshal(invoice{
+		Total:    decimal.New(1050, 2),
+		Discount: shepard_json.ParseOption(shepard.Some(decimal.New(5, 1))),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"total":"10.50","discount":"0.5"}`, string(b))
+
+	var decoded invoice
+	assert.NoError(t, json.Unmarshal([]byte(`{"total":10.50,"discount":"0.5"}`), &decoded))
+	assert.Equal(t, decimal.New(1050, 2), decoded.Total)
+	assert.Equal(t, shepard.Some(decimal.New(5, 1)), decoded.Discount.IntoOption())
+
+	// null is a no-op
+	assert.NoError(t, json.Unmarshal([]byte(`{"total":null}`), &decoded))
+	assert.Equal(t, decimal.New(1050, 2), decoded.Total)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"total":"ten"}`), &decoded))
+}
@@ -0,0 +1,70 @@
+package decimal
+
+import (
+	"math/big"
+)
+
+// RoundingMode describes how a value is rounded when digits have to be dropped.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest neighbour and ties to the even neighbour, also known as banker's rounding.
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest neighbour and ties away from zero.
+	RoundHalfUp
+	// RoundDown truncates towards zero.
+	RoundDown
+)
+
+func (m RoundingMode) Default() RoundingMode {
+	return RoundHalfEven
+}
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half-even"
+	case RoundHalfUp:
+		return "half-up"
+	case RoundDown:
+		return "down"
+	default:
+		return "unknown"
+	}
+}
+
+// quo divides n by d and rounds the quotient according to mode.
+func quo(n *big.Int, d *big.Int, mode RoundingMode) *big.Int {
+	if d.Sign() < 0 {
+		n = new(big.Int).Neg(n)
+		d = new(big.Int).Neg(d)
+	}
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 || mode == RoundDown {
+		return q
+	}
+
+	// compare the doubled remainder with the divisor to find out on which side of the half the remainder is
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(d)
+
+	away := cmp > 0
+	if cmp == 0 {
+		switch mode {
+		case RoundHalfUp:
+			away = true
+		case RoundHalfEven:
+			away = q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(n.Sign())))
+	}
+	return q
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
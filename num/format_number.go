@@ -0,0 +1,89 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FormatOptions describes how FormatNumber renders a number.
+type FormatOptions struct {
+	// Radix of integers between 2 and 36, 0 means 10. Floats are always formatted in base 10.
+	Radix int
+	// ThousandsSeparator is inserted between groups of three digits of the integer part, 0 means no grouping.
+	ThousandsSeparator rune
+	// DecimalSeparator separates the fractional part, 0 means '.'.
+	DecimalSeparator rune
+	// Precision is the number of fractional digits. shepard.None formats floats with the smallest number of digits
+	// necessary to represent the value exactly and integers without fractional part.
+	Precision shepard.Option[int]
+}
+
+func (o FormatOptions) Default() FormatOptions {
+	return FormatOptions{
+		Radix:            10,
+		DecimalSeparator: '.',
+		Precision:        shepard.None[int](),
+	}
+}
+
+// FormatNumber formats n according to opts. The output can be parsed by ParseStringWithOptions with matching separators.
+//
+//	num.FormatNumber(1234567.891, num.FormatOptions{ThousandsSeparator: ',', Precision: shepard.Some(2)}) // "1,234,567.89"
+func FormatNumber[T Number](n T, opts FormatOptions) string {
+	radix := opts.Radix
+	if radix == 0 {
+		radix = 10
+	}
+	decimalSeparator := opts.DecimalSeparator
+	if decimalSeparator == 0 {
+		decimalSeparator = '.'
+	}
+
+	var s string
+	v := reflect.ValueOf(n)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(v.Float(), 'f', opts.Precision.UnwrapOr(-1), v.Type().Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(v.Uint(), radix)
+	default:
+		s = strconv.FormatInt(v.Int(), radix)
+	}
+
+	sign := ""
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	// NaN and infinities are returned as is
+	if intPart == "NaN" || intPart == "Inf" {
+		return sign + intPart
+	}
+
+	if !isFloat[T]() && opts.Precision.IsSome() && opts.Precision.Unwrap() > 0 {
+		fracPart, hasFrac = strings.Repeat("0", opts.Precision.Unwrap()), true
+	}
+
+	if opts.ThousandsSeparator != 0 && len(intPart) > 3 {
+		var b strings.Builder
+		head := len(intPart) % 3
+		if head > 0 {
+			b.WriteString(intPart[:head])
+		}
+		for i := head; i < len(intPart); i += 3 {
+			if b.Len() > 0 {
+				b.WriteRune(opts.ThousandsSeparator)
+			}
+			b.WriteString(intPart[i : i+3])
+		}
+		intPart = b.String()
+	}
+
+	if hasFrac {
+		return sign + intPart + string(decimalSeparator) + fracPart
+	}
+	return sign + intPart
+}
@@ -0,0 +1,44 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatNumber(t *testing.T) {
+	en := num.FormatOptions{ThousandsSeparator: ','}
+	de := num.FormatOptions{ThousandsSeparator: '.', DecimalSeparator: ','}
+
+	assert.Equal(t, "1234567", num.FormatNumber(1234567, num.FormatOptions{}))
+	assert.Equal(t, "1,234,567", num.FormatNumber(1234567, en))
+	assert.Equal(t, "-123,456", num.FormatNumber(-123456, en))
+	assert.Equal(t, "123", num.FormatNumber(uint8(123), en))
+	assert.Equal(t, "1.234.567,5", num.FormatNumber(1234567.5, de))
+	assert.Equal(t, "0.1", num.FormatNumber(0.1, en))
+	assert.Equal(t, "ff", num.FormatNumber(255, num.FormatOptions{Radix: 16}))
+	assert.Equal(t, "NaN", num.FormatNumber(math.NaN(), en))
+	assert.Equal(t, "-Inf", num.FormatNumber(math.Inf(-1), en))
+	assert.Equal(t, "18,446,744,073,709,551,615", num.FormatNumber(uint64(math.MaxUint64), en))
+}
+
+func TestFormatNumber_Precision(t *testing.T) {
+	opts := num.FormatOptions{ThousandsSeparator: ',', Precision: shepard.Some(2)}
+
+	assert.Equal(t, "1,234,567.89", num.FormatNumber(1234567.891, opts))
+	assert.Equal(t, "1,234.00", num.FormatNumber(1234, opts))
+	assert.Equal(t, "0.13", num.FormatNumber(float32(0.126), opts))
+	assert.Equal(t, "3", num.FormatNumber(3.14, num.FormatOptions{Precision: shepard.Some(0)}))
+}
+
+func TestFormatNumber_RoundTrip(t *testing.T) {
+	format := num.FormatOptions{ThousandsSeparator: '.', DecimalSeparator: ','}
+	parse := num.ParseOptions{ThousandsSeparator: '.', DecimalSeparator: ','}
+
+	for _, v := range []float64{0, 1234567.25, -0.5, 999, 1000} {
+		assert.Equal(t, shepard.Some(v), num.ParseStringWithOptions[float64](num.FormatNumber(v, format), parse).Ok())
+	}
+}
@@ -0,0 +1,201 @@
+package num
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+)
+
+// Bucket is a bucket of a Histogram holding the number of observed values which are larger than the upper bound
+// of the previous bucket and smaller than or equal to UpperBound.
+//
+// UpperBound is shepard.None for the last bucket which holds all values larger than the largest bound.
+type Bucket[T Number] struct {
+	UpperBound shepard.Option[T]
+	Count      uint64
+}
+
+// Histogram counts observed values in buckets without storing them. It is safe for concurrent use.
+type Histogram[T Number] struct {
+	mutex  sync.Mutex
+	bounds []T
+	counts []uint64
+
+	count    uint64
+	sum      T
+	overflow bool
+	min      shepard.Option[T]
+	max      shepard.Option[T]
+}
+
+// NewHistogram creates a Histogram with a bucket for each of the given upper bounds and one for values above all bounds.
+func NewHistogram[T Number](bounds ...T) *Histogram[T] {
+	b := make([]T, len(bounds))
+	copy(b, bounds)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return &Histogram[T]{
+		bounds: b,
+		counts: make([]uint64, len(b)+1),
+		min:    shepard.None[T](),
+		max:    shepard.None[T](),
+	}
+}
+
+// LinearBounds returns count bounds, starting at start and increasing by width.
+func LinearBounds[T Number](start T, width T, count int) []T {
+	bounds := make([]T, 0, count)
+	for i := 0; i < count; i++ {
+		bounds = append(bounds, start)
+		start += width
+	}
+	return bounds
+}
+
+// ExponentialBounds returns count bounds, starting at start and multiplying each bound by factor.
+func ExponentialBounds[T Number](start T, factor T, count int) []T {
+	bounds := make([]T, 0, count)
+	for i := 0; i < count; i++ {
+		bounds = append(bounds, start)
+		start *= factor
+	}
+	return bounds
+}
+
+// Observe adds v to the Histogram. NaN values are ignored.
+func (h *Histogram[T]) Observe(v T) {
+	if v != v {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	i := sort.Search(len(h.bounds), func(i int) bool { return v <= h.bounds[i] })
+	h.counts[i]++
+	h.count++
+
+	sum, overflow := OverflowingAdd(h.sum, v)
+	h.sum = sum
+	h.overflow = h.overflow || overflow
+
+	if h.min.IsNone() || v < h.min.Unwrap() {
+		h.min = shepard.Some(v)
+	}
+	if h.max.IsNone() || v > h.max.Unwrap() {
+		h.max = shepard.Some(v)
+	}
+}
+
+// ObserveAll adds all elements of an iterator to the Histogram.
+func (h *Histogram[T]) ObserveAll(iterator iter.Iterator[T]) {
+	for {
+		next := iterator.Next()
+		if next.IsNone() {
+			return
+		}
+		h.Observe(next.Unwrap())
+	}
+}
+
+// Count returns the number of observed values.
+func (h *Histogram[T]) Count() uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.count
+}
+
+// Sum returns the sum of all observed values, or ErrOverflow if the sum overflowed.
+func (h *Histogram[T]) Sum() shepard.Result[T, error] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.overflow {
+		return shepard.Err[T, error](ErrOverflow)
+	}
+	return shepard.Ok[T, error](h.sum)
+}
+
+// Min returns the smallest observed value, or shepard.None if nothing was observed.
+func (h *Histogram[T]) Min() shepard.Option[T] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.min
+}
+
+// Max returns the largest observed value, or shepard.None if nothing was observed.
+func (h *Histogram[T]) Max() shepard.Option[T] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.max
+}
+
+// Mean returns the mean of all observed values.
+//
+// Returns shepard.None if nothing was observed and ErrOverflow if the sum overflowed.
+func (h *Histogram[T]) Mean() shepard.Result[shepard.Option[float64], error] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.overflow {
+		return shepard.Err[shepard.Option[float64], error](ErrOverflow)
+	}
+	if h.count == 0 {
+		return shepard.Ok[shepard.Option[float64], error](shepard.None[float64]())
+	}
+	return shepard.Ok[shepard.Option[float64], error](shepard.Some(float64(h.sum) / float64(h.count)))
+}
+
+// Buckets returns the buckets of the Histogram in ascending order.
+func (h *Histogram[T]) Buckets() iter.Iter[Bucket[T]] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	buckets := make([]Bucket[T], 0, len(h.counts))
+	for i, c := range h.counts {
+		upper := shepard.None[T]()
+		if i < len(h.bounds) {
+			upper = shepard.Some(h.bounds[i])
+		}
+		buckets = append(buckets, Bucket[T]{UpperBound: upper, Count: c})
+	}
+	return iter.New(buckets)
+}
+
+// Percentile estimates the p-th percentile of the observed values by interpolating linearly within the bucket containing it.
+// The estimate is bounded by the smallest and largest observed value. p must be between 0 and 100.
+//
+// Returns shepard.None if nothing was observed.
+func (h *Histogram[T]) Percentile(p float64) shepard.Result[shepard.Option[float64], error] {
+	if p < 0 || p > 100 || p != p {
+		return shepard.Err[shepard.Option[float64], error](fmt.Errorf("percentile %v out of range [0, 100]", p))
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.count == 0 {
+		return shepard.Ok[shepard.Option[float64], error](shepard.None[float64]())
+	}
+
+	min, max := float64(h.min.Unwrap()), float64(h.max.Unwrap())
+	rank := p / 100 * float64(h.count)
+
+	var cumulative uint64
+	for i, c := range h.counts {
+		if c == 0 || float64(cumulative+c) < rank {
+			cumulative += c
+			continue
+		}
+		lower, upper := min, max
+		if i > 0 && float64(h.bounds[i-1]) > lower {
+			lower = float64(h.bounds[i-1])
+		}
+		if i < len(h.bounds) && float64(h.bounds[i]) < upper {
+			upper = float64(h.bounds[i])
+		}
+		fraction := (rank - float64(cumulative)) / float64(c)
+		return shepard.Ok[shepard.Option[float64], error](shepard.Some(lower + fraction*(upper-lower)))
+	}
+	return shepard.Ok[shepard.Option[float64], error](shepard.Some(max))
+}
@@ -0,0 +1,83 @@
+package num_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/collections/slice"
+	"github.com/marlaone/shepard/iter"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := num.NewHistogram(100, 10, 50)
+
+	empty := num.NewHistogram[int]()
+	assert.Equal(t, shepard.None[float64](), empty.Percentile(50).Unwrap())
+	assert.Equal(t, shepard.None[float64](), empty.Mean().Unwrap())
+
+	it := iter.New([]int{1, 10, 11, 50, 75, 100, 1000})
+	h.ObserveAll(&it)
+
+	assert.Equal(t, uint64(7), h.Count())
+	assert.Equal(t, 1247, h.Sum().Unwrap())
+	assert.Equal(t, shepard.Some(1), h.Min())
+	assert.Equal(t, shepard.Some(1000), h.Max())
+	assert.Equal(t, shepard.Some(1247.0/7), h.Mean().Unwrap())
+
+	assert.Equal(t, slice.Init(
+		num.Bucket[int]{UpperBound: shepard.Some(10), Count: 2},
+		num.Bucket[int]{UpperBound: shepard.Some(50), Count: 2},
+		num.Bucket[int]{UpperBound: shepard.Some(100), Count: 2},
+		num.Bucket[int]{UpperBound: shepard.None[int](), Count: 1},
+	), slice.Collect(h.Buckets()))
+}
+
+func TestHistogram_Percentile(t *testing.T) {
+	h := num.NewHistogram(num.LinearBounds(10, 10, 10)...)
+	for i := 1; i <= 100; i++ {
+		h.Observe(i)
+	}
+
+	assert.Equal(t, shepard.Some(50.0), h.Percentile(50).Unwrap())
+	assert.Equal(t, shepard.Some(95.0), h.Percentile(95).Unwrap())
+	assert.Equal(t, shepard.Some(100.0), h.Percentile(100).Unwrap())
+	assert.Equal(t, shepard.Some(1.0), h.Percentile(0).Unwrap())
+	assert.True(t, h.Percentile(150).IsErr())
+}
+
+func TestHistogram_Overflow(t *testing.T) {
+	h := num.NewHistogram[uint8](10)
+	h.Observe(200)
+	h.Observe(100)
+	assert.ErrorIs(t, h.Sum().UnwrapErr(), num.ErrOverflow)
+	assert.ErrorIs(t, h.Mean().UnwrapErr(), num.ErrOverflow)
+
+	f := num.NewHistogram[float64]()
+	f.Observe(math.NaN())
+	assert.Equal(t, uint64(0), f.Count())
+}
+
+func TestHistogram_Concurrent(t *testing.T) {
+	h := num.NewHistogram(num.ExponentialBounds(0.001, 2, 10)...)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				h.Observe(0.01)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(1000), h.Count())
+}
+
+func TestBounds(t *testing.T) {
+	assert.Equal(t, []int{0, 5, 10}, num.LinearBounds(0, 5, 3))
+	assert.Equal(t, []float64{1, 2, 4, 8}, num.ExponentialBounds(1.0, 2, 4))
+}
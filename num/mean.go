@@ -0,0 +1,77 @@
+package num
+
+import (
+	"math"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+)
+
+// moments are the running count, mean and sum of squared deviations computed with Welford's algorithm.
+type moments struct {
+	n    float64
+	mean float64
+	m2   float64
+}
+
+func (m *moments) add(v float64) {
+	m.n++
+	delta := v - m.mean
+	m.mean += delta / m.n
+	m.m2 += delta * (v - m.mean)
+}
+
+func (m moments) overflowed() bool {
+	return math.IsInf(m.mean, 0) || math.IsInf(m.m2, 0)
+}
+
+// collectMoments consumes iterator and returns the moments of its elements, or shepard.None if it is empty.
+func collectMoments[T Number](iterator iter.Iterator[T]) shepard.Result[shepard.Option[moments], error] {
+	var m moments
+	for {
+		next := iterator.Next()
+		if next.IsNone() {
+			break
+		}
+		m.add(float64(next.Unwrap()))
+		if m.overflowed() {
+			return shepard.Err[shepard.Option[moments], error](ErrOverflow)
+		}
+	}
+	if m.n == 0 {
+		return shepard.Ok[shepard.Option[moments], error](shepard.None[moments]())
+	}
+	return shepard.Ok[shepard.Option[moments], error](shepard.Some(m))
+}
+
+// Mean computes the arithmetic mean of the elements of an iterator.
+//
+// Returns shepard.None if the iterator is empty and ErrOverflow if the computation overflows.
+func Mean[T Number](iterator iter.Iterator[T]) shepard.Result[shepard.Option[float64], error] {
+	return mapMoments(collectMoments(iterator), func(m moments) float64 { return m.mean })
+}
+
+// Variance computes the population variance of the elements of an iterator.
+//
+// Returns shepard.None if the iterator is empty and ErrOverflow if the computation overflows.
+func Variance[T Number](iterator iter.Iterator[T]) shepard.Result[shepard.Option[float64], error] {
+	return mapMoments(collectMoments(iterator), func(m moments) float64 { return m.m2 / m.n })
+}
+
+// StdDev computes the population standard deviation of the elements of an iterator.
+//
+// Returns shepard.None if the iterator is empty and ErrOverflow if the computation overflows.
+func StdDev[T Number](iterator iter.Iterator[T]) shepard.Result[shepard.Option[float64], error] {
+	return mapMoments(collectMoments(iterator), func(m moments) float64 { return math.Sqrt(m.m2 / m.n) })
+}
+
+func mapMoments(res shepard.Result[shepard.Option[moments], error], f func(m moments) float64) shepard.Result[shepard.Option[float64], error] {
+	if res.IsErr() {
+		return shepard.Err[shepard.Option[float64], error](res.UnwrapErr())
+	}
+	m := res.Unwrap()
+	if m.IsNone() {
+		return shepard.Ok[shepard.Option[float64], error](shepard.None[float64]())
+	}
+	return shepard.Ok[shepard.Option[float64], error](shepard.Some(f(m.Unwrap())))
+}
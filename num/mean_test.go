@@ -0,0 +1,44 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMean(t *testing.T) {
+	it := iter.New([]int{1, 2, 3, 4})
+	assert.Equal(t, shepard.Some(2.5), num.Mean[int](&it).Unwrap())
+
+	large := iter.New([]uint64{math.MaxUint64, math.MaxUint64})
+	assert.Equal(t, shepard.Some(float64(math.MaxUint64)), num.Mean[uint64](&large).Unwrap())
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[float64](), num.Mean[int](&empty).Unwrap())
+
+	overflow := iter.New([]float64{-math.MaxFloat64, math.MaxFloat64})
+	assert.ErrorIs(t, num.Mean[float64](&overflow).UnwrapErr(), num.ErrOverflow)
+}
+
+func TestVariance(t *testing.T) {
+	it := iter.New([]int{2, 4, 4, 4, 5, 5, 7, 9})
+	assert.Equal(t, shepard.Some(4.0), num.Variance[int](&it).Unwrap())
+
+	single := iter.New([]int{5})
+	assert.Equal(t, shepard.Some(0.0), num.Variance[int](&single).Unwrap())
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[float64](), num.Variance[int](&empty).Unwrap())
+}
+
+func TestStdDev(t *testing.T) {
+	it := iter.New([]float32{2, 4, 4, 4, 5, 5, 7, 9})
+	assert.Equal(t, shepard.Some(2.0), num.StdDev[float32](&it).Unwrap())
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[float64](), num.StdDev[int](&empty).Unwrap())
+}
@@ -0,0 +1,38 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+)
+
+// Min returns the smallest element of an iterator, or shepard.None if the iterator is empty.
+//
+// NaN values are skipped.
+func Min[T Number](iterator iter.Iterator[T]) shepard.Option[T] {
+	return reduce(iterator, func(a T, b T) bool { return b < a })
+}
+
+// Max returns the largest element of an iterator, or shepard.None if the iterator is empty.
+//
+// NaN values are skipped.
+func Max[T Number](iterator iter.Iterator[T]) shepard.Option[T] {
+	return reduce(iterator, func(a T, b T) bool { return b > a })
+}
+
+// reduce returns the element of iterator for which replace returned true last.
+func reduce[T Number](iterator iter.Iterator[T], replace func(current T, v T) bool) shepard.Option[T] {
+	result := shepard.None[T]()
+	for {
+		next := iterator.Next()
+		if next.IsNone() {
+			return result
+		}
+		v := next.Unwrap()
+		if v != v {
+			continue
+		}
+		if result.IsNone() || replace(result.Unwrap(), v) {
+			result = next
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMin(t *testing.T) {
+	it := iter.New([]int{3, -1, 2})
+	assert.Equal(t, shepard.Some(-1), num.Min[int](&it))
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[int](), num.Min[int](&empty))
+
+	floats := iter.New([]float64{math.NaN(), 2.5, 1.5})
+	assert.Equal(t, shepard.Some(1.5), num.Min[float64](&floats))
+}
+
+func TestMax(t *testing.T) {
+	it := iter.New([]uint8{3, 255, 2})
+	assert.Equal(t, shepard.Some[uint8](255), num.Max[uint8](&it))
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[int](), num.Max[int](&empty))
+}
@@ -1,9 +1,55 @@
 package num
 
 import (
+	"math"
+	"unsafe"
+
 	"golang.org/x/exp/constraints"
 )
 
 type Number interface {
 	constraints.Integer | constraints.Float
 }
+
+// isSigned returns true if T can hold negative values.
+func isSigned[T Number]() bool {
+	var zero T
+	return zero-1 < zero
+}
+
+// isFloat returns true if T is a floating point type.
+func isFloat[T Number]() bool {
+	var one T = 1
+	return one/2 != 0
+}
+
+// maxValue returns the largest value T can hold.
+func maxValue[T Number]() T {
+	var zero T
+	bits := unsafe.Sizeof(zero) * 8
+	if isFloat[T]() {
+		max := math.MaxFloat64
+		if bits == 32 {
+			max = math.MaxFloat32
+		}
+		return T(max)
+	}
+	if isSigned[T]() {
+		max := uint64(1)<<(bits-1) - 1
+		return T(max)
+	}
+	return zero - 1
+}
+
+// minValue returns the smallest value T can hold. For floating point types this is the negated maxValue.
+func minValue[T Number]() T {
+	var zero T
+	if isFloat[T]() {
+		return -maxValue[T]()
+	}
+	if isSigned[T]() {
+		min := int64(-1) << (unsafe.Sizeof(zero)*8 - 1)
+		return T(min)
+	}
+	return zero
+}
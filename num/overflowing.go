@@ -0,0 +1,43 @@
+package num
+
+import (
+	"math"
+)
+
+// isInfOverflow returns true if c became infinite although neither operand was infinite.
+func isInfOverflow[T Number](num T, v T, c T) bool {
+	return math.IsInf(float64(c), 0) && !math.IsInf(float64(num), 0) && !math.IsInf(float64(v), 0)
+}
+
+// OverflowingAdd adds two Number`s and returns the result together with a boolean indicating whether an arithmetic overflow would occur.
+// If an overflow would have occurred then the wrapped value is returned, for floating point types this is an infinity.
+func OverflowingAdd[T Number](num T, v T) (T, bool) {
+	c := num + v
+	if isFloat[T]() {
+		return c, isInfOverflow(num, v, c)
+	}
+	return c, (v > 0 && c < num) || (v < 0 && c > num)
+}
+
+// OverflowingSub subtracts two Number`s and returns the result together with a boolean indicating whether an arithmetic overflow would occur.
+// If an overflow would have occurred then the wrapped value is returned, for floating point types this is an infinity.
+func OverflowingSub[T Number](num T, v T) (T, bool) {
+	c := num - v
+	if isFloat[T]() {
+		return c, isInfOverflow(num, v, c)
+	}
+	return c, (v > 0 && c > num) || (v < 0 && c < num)
+}
+
+// OverflowingMul multiplies two Number`s and returns the result together with a boolean indicating whether an arithmetic overflow would occur.
+// If an overflow would have occurred then the wrapped value is returned, for floating point types this is an infinity.
+func OverflowingMul[T Number](num T, v T) (T, bool) {
+	c := num * v
+	if isFloat[T]() {
+		return c, isInfOverflow(num, v, c)
+	}
+	if num == 0 || v == 0 {
+		return c, false
+	}
+	return c, c/v != num || (c < 0) != ((num < 0) != (v < 0))
+}
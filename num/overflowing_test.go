@@ -0,0 +1,67 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverflowingAdd(t *testing.T) {
+	c, overflow := num.OverflowingAdd(int8(100), 27)
+	assert.Equal(t, int8(127), c)
+	assert.False(t, overflow)
+
+	c, overflow = num.OverflowingAdd(int8(100), 28)
+	assert.Equal(t, int8(-128), c)
+	assert.True(t, overflow)
+
+	c2, overflow := num.OverflowingAdd(uint8(255), 1)
+	assert.Equal(t, uint8(0), c2)
+	assert.True(t, overflow)
+
+	c3, overflow := num.OverflowingAdd(1e20, 1.0)
+	assert.Equal(t, 1e20, c3)
+	assert.False(t, overflow)
+
+	c3, overflow = num.OverflowingAdd(math.MaxFloat64, math.MaxFloat64)
+	assert.True(t, math.IsInf(c3, 1))
+	assert.True(t, overflow)
+}
+
+func TestOverflowingSub(t *testing.T) {
+	c, overflow := num.OverflowingSub(uint8(0), 1)
+	assert.Equal(t, uint8(255), c)
+	assert.True(t, overflow)
+
+	c2, overflow := num.OverflowingSub(int32(math.MinInt32), -1)
+	assert.Equal(t, int32(math.MinInt32+1), c2)
+	assert.False(t, overflow)
+
+	c2, overflow = num.OverflowingSub(int32(math.MinInt32), 1)
+	assert.Equal(t, int32(math.MaxInt32), c2)
+	assert.True(t, overflow)
+}
+
+func TestOverflowingMul(t *testing.T) {
+	c, overflow := num.OverflowingMul(int8(-128), -1)
+	assert.Equal(t, int8(-128), c)
+	assert.True(t, overflow)
+
+	c, overflow = num.OverflowingMul(int8(-1), -128)
+	assert.Equal(t, int8(-128), c)
+	assert.True(t, overflow)
+
+	c, overflow = num.OverflowingMul(int8(-64), 2)
+	assert.Equal(t, int8(-128), c)
+	assert.False(t, overflow)
+
+	c2, overflow := num.OverflowingMul(uint16(256), 256)
+	assert.Equal(t, uint16(0), c2)
+	assert.True(t, overflow)
+
+	c3, overflow := num.OverflowingMul(float32(math.MaxFloat32), 2)
+	assert.True(t, math.IsInf(float64(c3), 1))
+	assert.True(t, overflow)
+}
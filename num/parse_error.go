@@ -0,0 +1,59 @@
+package num
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrEmpty is returned when parsing an empty string.
+	ErrEmpty = errors.New("empty input")
+	// ErrSyntax is returned when the input is not a valid number.
+	ErrSyntax = errors.New("invalid syntax")
+	// ErrOverflow is returned when the number doesn't fit into the target type.
+	ErrOverflow = errors.New("value out of range")
+)
+
+// ParseError records a failed parse of a number.
+//
+// Err is one of ErrEmpty, ErrSyntax or ErrOverflow, so callers can check the reason with errors.Is.
+type ParseError struct {
+	Func  string
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return "num." + e.Func + ": parsing " + strconv.Quote(e.Input) + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// IsEmpty returns true if the input was empty.
+func (e *ParseError) IsEmpty() bool {
+	return errors.Is(e.Err, ErrEmpty)
+}
+
+// IsSyntax returns true if the input was not a valid number.
+func (e *ParseError) IsSyntax() bool {
+	return errors.Is(e.Err, ErrSyntax)
+}
+
+// IsOverflow returns true if the number didn't fit into the target type.
+func (e *ParseError) IsOverflow() bool {
+	return errors.Is(e.Err, ErrOverflow)
+}
+
+// fromStrconvError converts errors of the strconv package to a ParseError, other errors are returned unchanged.
+func fromStrconvError(fn string, input string, err error) error {
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		return err
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return &ParseError{Func: fn, Input: input, Err: ErrOverflow}
+	}
+	return &ParseError{Func: fn, Input: input, Err: ErrSyntax}
+}
@@ -0,0 +1,30 @@
+package num_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError(t *testing.T) {
+	var parseErr *num.ParseError
+
+	err := num.ParseString[int]("").UnwrapErr()
+	assert.True(t, errors.As(err, &parseErr))
+	assert.True(t, parseErr.IsEmpty())
+	assert.ErrorIs(t, err, num.ErrEmpty)
+
+	err = num.ParseString[int]("one").UnwrapErr()
+	assert.True(t, errors.As(err, &parseErr))
+	assert.True(t, parseErr.IsSyntax())
+	assert.ErrorIs(t, err, num.ErrSyntax)
+	assert.Equal(t, `num.ParseString: parsing "one": invalid syntax`, err.Error())
+
+	err = num.ParseString[uint8]("256").UnwrapErr()
+	assert.True(t, errors.As(err, &parseErr))
+	assert.True(t, parseErr.IsOverflow())
+	assert.ErrorIs(t, err, num.ErrOverflow)
+	assert.Equal(t, "256", parseErr.Input)
+}
@@ -0,0 +1,194 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// ParseMode controls how forgiving ParseStringWithOptions is.
+type ParseMode int
+
+const (
+	// ParseModeStrict only accepts the exact format described by ParseOptions.
+	// Thousands separators must group the integer part in blocks of three digits.
+	ParseModeStrict ParseMode = iota
+	// ParseModeLenient trims surrounding whitespace, ignores thousands separators and underscores between digits
+	// and detects the "0x", "0o" and "0b" prefixes of integers.
+	ParseModeLenient
+)
+
+func (m ParseMode) Default() ParseMode {
+	return ParseModeStrict
+}
+
+// ParseOptions describes the format of numbers parsed by ParseStringWithOptions.
+type ParseOptions struct {
+	// Radix of integers between 2 and 36, 0 means 10. Floats are always parsed in base 10.
+	Radix int
+	// ThousandsSeparator separates groups of digits in the integer part, 0 means no separator is allowed.
+	ThousandsSeparator rune
+	// DecimalSeparator separates the fractional part of floats, 0 means '.'.
+	DecimalSeparator rune
+	Mode             ParseMode
+}
+
+func (o ParseOptions) Default() ParseOptions {
+	return ParseOptions{
+		Radix:            10,
+		DecimalSeparator: '.',
+		Mode:             o.Mode.Default(),
+	}
+}
+
+// ParseStringWithOptions parses n into T using the separators, radix and mode given in opts.
+//
+//	num.ParseStringWithOptions[float64]("1.234,5", num.ParseOptions{ThousandsSeparator: '.', DecimalSeparator: ','}) // Ok(1234.5)
+func ParseStringWithOptions[T Number](n string, opts ParseOptions) shepard.Result[T, error] {
+	const fn = "ParseStringWithOptions"
+
+	var target T
+	v := reflect.ValueOf(&target).Elem()
+
+	normalized, radix, err := normalize(n, opts, isFloat[T]())
+	if err != nil {
+		return shepard.Err[T, error](&ParseError{Func: fn, Input: n, Err: err})
+	}
+	if err := parseInto(v, normalized, radix); err != nil {
+		return shepard.Err[T, error](fromStrconvError(fn, n, err))
+	}
+	return shepard.Ok[T, error](target)
+}
+
+// normalize converts n into the syntax understood by the strconv package and returns the radix to parse it with.
+func normalize(n string, opts ParseOptions, float bool) (string, int, error) {
+	radix := opts.Radix
+	if radix == 0 {
+		radix = 10
+	}
+	decimalSeparator := opts.DecimalSeparator
+	if decimalSeparator == 0 {
+		decimalSeparator = '.'
+	}
+	lenient := opts.Mode == ParseModeLenient
+
+	if lenient {
+		n = strings.TrimSpace(n)
+	}
+	if n == "" {
+		return "", 0, ErrEmpty
+	}
+
+	sign := ""
+	switch n[0] {
+	case '-':
+		sign, n = "-", n[1:]
+	case '+':
+		n = n[1:]
+	}
+
+	if lenient && !float && len(n) > 2 && n[0] == '0' {
+		switch unicode.ToLower(rune(n[1])) {
+		case 'x':
+			radix, n = 16, n[2:]
+		case 'o':
+			radix, n = 8, n[2:]
+		case 'b':
+			radix, n = 2, n[2:]
+		}
+	}
+	// strconv would accept a second sign after the one removed above, or after a base prefix
+	if n != "" && (n[0] == '-' || n[0] == '+') {
+		return "", 0, ErrSyntax
+	}
+
+	exponent := ""
+	if float {
+		if i := strings.IndexAny(n, "eE"); i >= 0 {
+			n, exponent = n[:i], n[i+1:]
+			digits := strings.TrimLeft(exponent, "+-")
+			if len(exponent)-len(digits) > 1 || !isDigits(digits) {
+				return "", 0, ErrSyntax
+			}
+			exponent = "e" + exponent
+		}
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(n, string(decimalSeparator))
+	if hasFrac && !float {
+		return "", 0, ErrSyntax
+	}
+
+	if opts.ThousandsSeparator != 0 {
+		groups := strings.Split(intPart, string(opts.ThousandsSeparator))
+		if !lenient && len(groups) > 1 {
+			for i, g := range groups {
+				if (i == 0 && (len(g) == 0 || len(g) > 3)) || (i > 0 && len(g) != 3) {
+					return "", 0, ErrSyntax
+				}
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	if lenient {
+		intPart = strings.ReplaceAll(intPart, "_", "")
+		fracPart = strings.ReplaceAll(fracPart, "_", "")
+	}
+
+	if intPart == "" && fracPart == "" {
+		return "", 0, ErrSyntax
+	}
+
+	if float {
+		// only plain decimal digits are accepted, strconv would also accept hex floats and "Inf"
+		if !isDigits(intPart + fracPart) {
+			return "", 0, ErrSyntax
+		}
+		if hasFrac {
+			return sign + intPart + "." + fracPart + exponent, 10, nil
+		}
+		return sign + intPart + exponent, 10, nil
+	}
+
+	// strconv would accept base prefixes and underscores with a radix of 0, which is never passed here
+	if !isRadixDigits(intPart, radix) {
+		return "", 0, ErrSyntax
+	}
+	return sign + intPart, radix, nil
+}
+
+// isRadixDigits returns true if s consists of digits of radix only, letters in any case.
+func isRadixDigits(s string, radix int) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		digit := radix
+		switch {
+		case c >= '0' && c <= '9':
+			digit = int(c - '0')
+		case c >= 'a' && c <= 'z':
+			digit = int(c-'a') + 10
+		case c >= 'A' && c <= 'Z':
+			digit = int(c-'A') + 10
+		}
+		if digit >= radix {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package num_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStringWithOptions_Strict(t *testing.T) {
+	en := num.ParseOptions{ThousandsSeparator: ',', DecimalSeparator: '.'}
+	de := num.ParseOptions{ThousandsSeparator: '.', DecimalSeparator: ','}
+
+	assert.Equal(t, shepard.Some(1234.5), num.ParseStringWithOptions[float64]("1,234.5", en).Ok())
+	assert.Equal(t, shepard.Some(1234.5), num.ParseStringWithOptions[float64]("1.234,5", de).Ok())
+	assert.Equal(t, shepard.Some(-1234567), num.ParseStringWithOptions[int]("-1,234,567", en).Ok())
+	assert.Equal(t, shepard.Some(1234567), num.ParseStringWithOptions[int]("1234567", en).Ok())
+	assert.Equal(t, shepard.Some(1.5e3), num.ParseStringWithOptions[float64]("1,5e3", de).Ok())
+	assert.Equal(t, shepard.Some(0.25), num.ParseStringWithOptions[float64](".25", num.ParseOptions{}).Ok())
+	assert.Equal(t, shepard.Some(255), num.ParseStringWithOptions[int]("ff", num.ParseOptions{Radix: 16}).Ok())
+
+	for _, input := range []string{"12,34", "1,2345", ",123", "1,234,", " 1", "1_000", "0x1F", "1.5", "1e5"} {
+		assert.ErrorIs(t, num.ParseStringWithOptions[int](input, en).UnwrapErr(), num.ErrSyntax, input)
+	}
+	for _, input := range []string{"1,5", "Inf", "NaN", "1.2.3", "1e", "1e+-5", "0x1p-2"} {
+		assert.ErrorIs(t, num.ParseStringWithOptions[float64](input, en).UnwrapErr(), num.ErrSyntax, input)
+	}
+	// a single sign is allowed
+	for _, input := range []string{"+-5", "-+5", "--5", "++5", "-", "+"} {
+		assert.ErrorIs(t, num.ParseStringWithOptions[int](input, en).UnwrapErr(), num.ErrSyntax, input)
+		assert.ErrorIs(t, num.ParseStringWithOptions[float64](input, en).UnwrapErr(), num.ErrSyntax, input)
+	}
+	assert.ErrorIs(t, num.ParseStringWithOptions[int8]("1,000", en).UnwrapErr(), num.ErrOverflow)
+	assert.ErrorIs(t, num.ParseStringWithOptions[int]("", en).UnwrapErr(), num.ErrEmpty)
+}
+
+func TestParseStringWithOptions_Lenient(t *testing.T) {
+	opts := num.ParseOptions{ThousandsSeparator: ',', Mode: num.ParseModeLenient}
+
+	assert.Equal(t, shepard.Some(1234), num.ParseStringWithOptions[int](" 12,34 ", opts).Ok())
+	assert.Equal(t, shepard.Some(1000), num.ParseStringWithOptions[int]("1_000", opts).Ok())
+	assert.Equal(t, shepard.Some(31), num.ParseStringWithOptions[int]("0x1F", opts).Ok())
+	assert.Equal(t, shepard.Some(-5), num.ParseStringWithOptions[int]("-0b101", opts).Ok())
+	assert.Equal(t, shepard.Some[uint16](8), num.ParseStringWithOptions[uint16]("+0o10", opts).Ok())
+	assert.Equal(t, shepard.Some(1234.5), num.ParseStringWithOptions[float64]("1,23,4.5", opts).Ok())
+	assert.ErrorIs(t, num.ParseStringWithOptions[int]("   ", opts).UnwrapErr(), num.ErrEmpty)
+	assert.ErrorIs(t, num.ParseStringWithOptions[int]("one", opts).UnwrapErr(), num.ErrSyntax)
+	assert.ErrorIs(t, num.ParseStringWithOptions[int](" +-5", opts).UnwrapErr(), num.ErrSyntax)
+	// signs go before the base prefix
+	assert.Equal(t, shepard.Some(-1), num.ParseStringWithOptions[int]("-0x1", opts).Ok())
+	for _, input := range []string{"0x-1", "0x+1", "0b-1", "0o+7", "0x1g"} {
+		assert.ErrorIs(t, num.ParseStringWithOptions[int](input, opts).UnwrapErr(), num.ErrSyntax, input)
+	}
+}
@@ -3,32 +3,74 @@ package num
 import (
 	"fmt"
 	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num/decimal"
 	"reflect"
 	"strconv"
 )
 
-func ParseString[T Number](n string) shepard.Result[T, error] {
+// Parsable is the set of types ParseString can parse into.
+type Parsable interface {
+	Number | decimal.Decimal | decimal.BigInt
+}
+
+// ParseString parses n into T.
+//
+// Numbers are parsed in base 10, decimal.Decimal and decimal.BigInt are parsed with decimal.Parse and decimal.ParseBigInt.
+// Failures to parse a Number are reported as *ParseError.
+func ParseString[T Parsable](n string) shepard.Result[T, error] {
 	var target T
-	numType := reflect.TypeOf(target)
-	switch numType.Kind() {
+
+	if n == "" {
+		return shepard.Err[T, error](&ParseError{Func: "ParseString", Input: n, Err: ErrEmpty})
+	}
+
+	switch t := any(&target).(type) {
+	case *decimal.Decimal:
+		res := decimal.Parse(n)
+		if res.IsErr() {
+			return shepard.Err[T, error](res.UnwrapErr())
+		}
+		*t = res.Unwrap()
+		return shepard.Ok[T, error](target)
+	case *decimal.BigInt:
+		res := decimal.ParseBigInt(n)
+		if res.IsErr() {
+			return shepard.Err[T, error](res.UnwrapErr())
+		}
+		*t = res.Unwrap()
+		return shepard.Ok[T, error](target)
+	}
+
+	if err := parseInto(reflect.ValueOf(&target).Elem(), n, 10); err != nil {
+		return shepard.Err[T, error](fromStrconvError("ParseString", n, err))
+	}
+	return shepard.Ok[T, error](target)
+}
+
+// parseInto parses n in the given base into the numeric value v. Floats are always parsed in base 10.
+func parseInto(v reflect.Value, n string, base int) error {
+	switch v.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := strconv.ParseInt(n, 10, numType.Bits())
+		i, err := strconv.ParseInt(n, base, v.Type().Bits())
 		if err != nil {
-			return shepard.Err[T, error](err)
+			return err
 		}
-		return shepard.Ok[T, error](T(v))
+		v.SetInt(i)
+		return nil
 	case reflect.Float32, reflect.Float64:
-		v, err := strconv.ParseFloat(n, numType.Bits())
+		f, err := strconv.ParseFloat(n, v.Type().Bits())
 		if err != nil {
-			return shepard.Err[T, error](err)
+			return err
 		}
-		return shepard.Ok[T, error](T(v))
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		v, err := strconv.ParseUint(n, 10, numType.Bits())
+		v.SetFloat(f)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(n, base, v.Type().Bits())
 		if err != nil {
-			return shepard.Err[T, error](err)
+			return err
 		}
-		return shepard.Ok[T, error](T(v))
+		v.SetUint(u)
+		return nil
 	}
-	return shepard.Err[T, error](fmt.Errorf("unknown numeric type: %T", target))
+	return fmt.Errorf("unknown numeric type: %s", v.Type())
 }
@@ -0,0 +1,22 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+	"golang.org/x/exp/constraints"
+	"reflect"
+)
+
+// ParseStringRadix parses the integer n in the given radix, which must be between 2 and 36.
+//
+// A radix of 0 detects the base from the prefix of n like Go integer literals do, "0x" for 16, "0o" or "0" for 8 and "0b" for 2, otherwise 10.
+// With a radix of 0, underscores are permitted between digits, e.g. "1_000".
+func ParseStringRadix[T constraints.Integer](n string, radix int) shepard.Result[T, error] {
+	if n == "" {
+		return shepard.Err[T, error](&ParseError{Func: "ParseStringRadix", Input: n, Err: ErrEmpty})
+	}
+	var target T
+	if err := parseInto(reflect.ValueOf(&target).Elem(), n, radix); err != nil {
+		return shepard.Err[T, error](fromStrconvError("ParseStringRadix", n, err))
+	}
+	return shepard.Ok[T, error](target)
+}
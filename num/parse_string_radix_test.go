@@ -0,0 +1,27 @@
+package num_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStringRadix(t *testing.T) {
+	assert.Equal(t, shepard.Some(31), num.ParseStringRadix[int]("1f", 16).Ok())
+	assert.Equal(t, shepard.Some[uint8](5), num.ParseStringRadix[uint8]("101", 2).Ok())
+	assert.Equal(t, shepard.Some[int64](-35), num.ParseStringRadix[int64]("-z", 36).Ok())
+	assert.ErrorIs(t, num.ParseStringRadix[int]("0x1F", 16).UnwrapErr(), num.ErrSyntax)
+	assert.ErrorIs(t, num.ParseStringRadix[uint8]("100", 16).UnwrapErr(), num.ErrOverflow)
+	assert.ErrorIs(t, num.ParseStringRadix[int]("", 16).UnwrapErr(), num.ErrEmpty)
+	assert.ErrorIs(t, num.ParseStringRadix[int]("1", 37).UnwrapErr(), num.ErrSyntax)
+}
+
+func TestParseStringRadix_Detect(t *testing.T) {
+	assert.Equal(t, shepard.Some(31), num.ParseStringRadix[int]("0x1F", 0).Ok())
+	assert.Equal(t, shepard.Some(8), num.ParseStringRadix[int]("0o10", 0).Ok())
+	assert.Equal(t, shepard.Some(3), num.ParseStringRadix[int]("0b11", 0).Ok())
+	assert.Equal(t, shepard.Some(1000), num.ParseStringRadix[int]("1_000", 0).Ok())
+	assert.Equal(t, shepard.Some(10), num.ParseStringRadix[int]("10", 0).Ok())
+}
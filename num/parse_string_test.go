@@ -3,6 +3,7 @@ package num_test
 import (
 	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -35,3 +36,14 @@ func TestParseString_Uint(t *testing.T) {
 	assert.True(t, num.ParseString[uint8]("-1").Ok().Equal(shepard.None[uint8]()))
 	assert.True(t, num.ParseString[uint8]("one").Ok().Equal(shepard.None[uint8]()))
 }
+
+func TestParseString_Decimal(t *testing.T) {
+	assert.Equal(t, shepard.Some(decimal.New(-1234, 2)), num.ParseString[decimal.Decimal]("-12.34").Ok())
+	assert.True(t, num.ParseString[decimal.Decimal]("one").IsErr())
+}
+
+func TestParseString_BigInt(t *testing.T) {
+	v := num.ParseString[decimal.BigInt]("123456789012345678901234567890").Unwrap()
+	assert.Equal(t, "123456789012345678901234567890", v.String())
+	assert.True(t, num.ParseString[decimal.BigInt]("1.5").IsErr())
+}
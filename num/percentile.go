@@ -0,0 +1,63 @@
+package num
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+)
+
+// sorted collects the elements of iterator into an ascending slice. NaN values are skipped.
+func sorted[T Number](iterator iter.Iterator[T]) []float64 {
+	var values []float64
+	for {
+		next := iterator.Next()
+		if next.IsNone() {
+			break
+		}
+		v := float64(next.Unwrap())
+		if v == v {
+			values = append(values, v)
+		}
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// percentile returns the p-th percentile of the ascending values, interpolating linearly between the closest ranks.
+func percentile(values []float64, p float64) float64 {
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(rank)
+	if lower+1 >= len(values) {
+		return values[len(values)-1]
+	}
+	fraction := rank - float64(lower)
+	return values[lower] + fraction*(values[lower+1]-values[lower])
+}
+
+// Median returns the middle element of an iterator, or the mean of the two middle elements if the number of elements is even.
+//
+// Returns shepard.None if the iterator is empty. The elements are buffered to sort them, NaN values are skipped.
+func Median[T Number](iterator iter.Iterator[T]) shepard.Option[float64] {
+	values := sorted(iterator)
+	if len(values) == 0 {
+		return shepard.None[float64]()
+	}
+	return shepard.Some(percentile(values, 50))
+}
+
+// Percentile returns the p-th percentile of the elements of an iterator, interpolating linearly between the closest ranks.
+// p must be between 0 and 100.
+//
+// Returns shepard.None if the iterator is empty. The elements are buffered to sort them, NaN values are skipped.
+func Percentile[T Number](iterator iter.Iterator[T], p float64) shepard.Result[shepard.Option[float64], error] {
+	if p < 0 || p > 100 || p != p {
+		return shepard.Err[shepard.Option[float64], error](fmt.Errorf("percentile %v out of range [0, 100]", p))
+	}
+	values := sorted(iterator)
+	if len(values) == 0 {
+		return shepard.Ok[shepard.Option[float64], error](shepard.None[float64]())
+	}
+	return shepard.Ok[shepard.Option[float64], error](shepard.Some(percentile(values, p)))
+}
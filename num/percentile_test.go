@@ -0,0 +1,37 @@
+package num_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedian(t *testing.T) {
+	odd := iter.New([]int{5, 1, 3})
+	assert.Equal(t, shepard.Some(3.0), num.Median[int](&odd))
+
+	even := iter.New([]int{4, 1, 3, 2})
+	assert.Equal(t, shepard.Some(2.5), num.Median[int](&even))
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[float64](), num.Median[int](&empty))
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int{15, 20, 35, 40, 50}
+
+	for p, expected := range map[float64]float64{0: 15, 25: 20, 50: 35, 90: 46, 100: 50} {
+		it := iter.New(values)
+		assert.Equal(t, shepard.Some(expected), num.Percentile[int](&it, p).Unwrap(), p)
+	}
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[float64](), num.Percentile[int](&empty, 50).Unwrap())
+
+	it := iter.New(values)
+	assert.True(t, num.Percentile[int](&it, 101).IsErr())
+	assert.True(t, num.Percentile[int](&it, -1).IsErr())
+}
@@ -0,0 +1,28 @@
+package num
+
+import (
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+)
+
+// Product multiplies all elements of an iterator, checking for overflow.
+//
+// Returns shepard.None if the iterator is empty and ErrOverflow if the computation overflows.
+func Product[T Number](iterator iter.Iterator[T]) shepard.Result[shepard.Option[T], error] {
+	product := shepard.None[T]()
+	for {
+		next := iterator.Next()
+		if next.IsNone() {
+			return shepard.Ok[shepard.Option[T], error](product)
+		}
+		if product.IsNone() {
+			product = next
+			continue
+		}
+		c, overflow := OverflowingMul(product.Unwrap(), next.Unwrap())
+		if overflow {
+			return shepard.Err[shepard.Option[T], error](ErrOverflow)
+		}
+		product = shepard.Some(c)
+	}
+}
@@ -0,0 +1,24 @@
+package num_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/iter"
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProduct(t *testing.T) {
+	it := iter.New([]int{1, 2, 3, 4})
+	assert.Equal(t, shepard.Some(24), num.Product[int](&it).Unwrap())
+
+	empty := iter.New([]int{})
+	assert.Equal(t, shepard.None[int](), num.Product[int](&empty).Unwrap())
+
+	overflow := iter.New([]uint8{16, 16})
+	assert.ErrorIs(t, num.Product[uint8](&overflow).UnwrapErr(), num.ErrOverflow)
+
+	floats := iter.New([]float64{0.5, 3})
+	assert.Equal(t, shepard.Some(1.5), num.Product[float64](&floats).Unwrap())
+}
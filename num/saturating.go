@@ -0,0 +1,36 @@
+package num
+
+// saturate returns the bound of T which is closest to the true result of an overflowing operation.
+func saturate[T Number](positive bool) T {
+	if positive {
+		return maxValue[T]()
+	}
+	return minValue[T]()
+}
+
+// SaturatingAdd adds two Number`s, saturating at the numeric bounds instead of overflowing.
+func SaturatingAdd[T Number](num T, v T) T {
+	c, overflow := OverflowingAdd(num, v)
+	if overflow {
+		return saturate[T](v > 0)
+	}
+	return c
+}
+
+// SaturatingSub subtracts two Number`s, saturating at the numeric bounds instead of overflowing.
+func SaturatingSub[T Number](num T, v T) T {
+	c, overflow := OverflowingSub(num, v)
+	if overflow {
+		return saturate[T](v < 0)
+	}
+	return c
+}
+
+// SaturatingMul multiplies two Number`s, saturating at the numeric bounds instead of overflowing.
+func SaturatingMul[T Number](num T, v T) T {
+	c, overflow := OverflowingMul(num, v)
+	if overflow {
+		return saturate[T]((num < 0) == (v < 0))
+	}
+	return c
+}
@@ -0,0 +1,34 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaturatingAdd(t *testing.T) {
+	assert.Equal(t, uint8(255), num.SaturatingAdd(uint8(250), 10))
+	assert.Equal(t, uint8(20), num.SaturatingAdd(uint8(10), 10))
+	assert.Equal(t, int8(127), num.SaturatingAdd(int8(100), 100))
+	assert.Equal(t, int8(-128), num.SaturatingAdd(int8(-100), -100))
+	assert.Equal(t, int64(math.MaxInt64), num.SaturatingAdd(int64(math.MaxInt64), 1))
+	assert.Equal(t, math.MaxFloat64, num.SaturatingAdd(math.MaxFloat64, math.MaxFloat64))
+	assert.Equal(t, float32(-math.MaxFloat32), num.SaturatingAdd(float32(-math.MaxFloat32), -math.MaxFloat32))
+}
+
+func TestSaturatingSub(t *testing.T) {
+	assert.Equal(t, uint8(0), num.SaturatingSub(uint8(5), 10))
+	assert.Equal(t, int8(-128), num.SaturatingSub(int8(-100), 100))
+	assert.Equal(t, int8(127), num.SaturatingSub(int8(100), -100))
+	assert.Equal(t, uint(math.MaxUint), num.SaturatingSub(uint(math.MaxUint), 0))
+}
+
+func TestSaturatingMul(t *testing.T) {
+	assert.Equal(t, uint8(255), num.SaturatingMul(uint8(16), 16))
+	assert.Equal(t, int8(127), num.SaturatingMul(int8(-128), -1))
+	assert.Equal(t, int8(-128), num.SaturatingMul(int8(100), -2))
+	assert.Equal(t, int8(-100), num.SaturatingMul(int8(50), -2))
+	assert.Equal(t, -math.MaxFloat64, num.SaturatingMul(math.MaxFloat64, -2))
+}
@@ -2,6 +2,7 @@ package num
 
 import (
 	"errors"
+	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/iter"
 )
 
@@ -26,3 +27,37 @@ func Sum[T Number](iterator iter.Iter[T]) T {
 	}
 	return sum
 }
+
+// CheckedSum sums the elements of an iterator, checking for overflow. If overflow happens, shepard.None is returned.
+// An empty iterator returns the zero value of the type.
+func CheckedSum[T Number](iterator iter.Iter[T]) shepard.Option[T] {
+	var sum T
+	for {
+		v := iterator.Next()
+		if v.IsNone() {
+			break
+		}
+		c, overflow := OverflowingAdd(sum, v.Unwrap())
+		if overflow {
+			return shepard.None[T]()
+		}
+		sum = c
+	}
+	return shepard.Some(sum)
+}
+
+// SaturatingSum sums the elements of an iterator, saturating at the numeric bounds instead of overflowing.
+// An empty iterator returns the zero value of the type.
+//
+// Once the sum saturated, following elements are still added, so a sum that saturated at the maximum may decrease again.
+func SaturatingSum[T Number](iterator iter.Iter[T]) T {
+	var sum T
+	for {
+		v := iterator.Next()
+		if v.IsNone() {
+			break
+		}
+		sum = SaturatingAdd(sum, v.Unwrap())
+	}
+	return sum
+}
@@ -1,6 +1,7 @@
 package num_test
 
 import (
+	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/iter"
 	"github.com/marlaone/shepard/num"
 	"github.com/stretchr/testify/assert"
@@ -17,3 +18,17 @@ func TestSum(t *testing.T) {
 		num.Sum(iter.New([]uint8{math.MaxUint8, 2, 3, 4}))
 	})
 }
+
+func TestCheckedSum(t *testing.T) {
+	assert.Equal(t, shepard.Some(10), num.CheckedSum(iter.New([]int{1, 2, 3, 4})))
+	assert.Equal(t, shepard.Some(0), num.CheckedSum(iter.New([]int{})))
+	assert.Equal(t, shepard.None[uint8](), num.CheckedSum(iter.New([]uint8{math.MaxUint8, 2, 3, 4})))
+	assert.Equal(t, shepard.Some(1e20+2), num.CheckedSum(iter.New([]float64{1e20, 1, 1})))
+}
+
+func TestSaturatingSum(t *testing.T) {
+	assert.Equal(t, 10, num.SaturatingSum(iter.New([]int{1, 2, 3, 4})))
+	assert.Equal(t, 0, num.SaturatingSum(iter.New([]int{})))
+	assert.Equal(t, uint8(math.MaxUint8), num.SaturatingSum(iter.New([]uint8{math.MaxUint8, 2, 3, 4})))
+	assert.Equal(t, int8(math.MinInt8), num.SaturatingSum(iter.New([]int8{-100, -100})))
+}
@@ -0,0 +1,177 @@
+package unit
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+)
+
+// ByteSize is a number of bytes.
+type ByteSize uint64
+
+const (
+	Byte ByteSize = 1
+
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+	EiB = 1024 * PiB
+
+	KB = 1000 * Byte
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+	PB = 1000 * TB
+	EB = 1000 * PB
+)
+
+var binaryByteUnits = []struct {
+	symbol string
+	size   ByteSize
+}{
+	{"EiB", EiB},
+	{"PiB", PiB},
+	{"TiB", TiB},
+	{"GiB", GiB},
+	{"MiB", MiB},
+	{"KiB", KiB},
+}
+
+var byteUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+	"pib": PiB,
+	"eib": EiB,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"pb":  PB,
+	"eb":  EB,
+}
+
+// Bytes converts n of the given unit to a ByteSize, e.g. Bytes(1.5, MiB).
+// Fractions of a byte are truncated.
+//
+// Returns shepard.None if n is negative or the ByteSize overflows.
+func Bytes[T num.Number](n T, unit ByteSize) shepard.Option[ByteSize] {
+	if n < 0 || n != n {
+		return shepard.None[ByteSize]()
+	}
+	if n == T(uint64(n)) {
+		return num.CheckedMul(ByteSize(n), unit)
+	}
+	r, ok := new(big.Rat).SetString(num.FormatNumber(n, num.FormatOptions{}))
+	if !ok {
+		return shepard.None[ByteSize]()
+	}
+	return fromRat(r.Mul(r, new(big.Rat).SetInt64(int64(unit))))
+}
+
+// fromRat truncates r to a ByteSize, returning shepard.None if it doesn't fit.
+func fromRat(r *big.Rat) shepard.Option[ByteSize] {
+	i := new(big.Int).Quo(r.Num(), r.Denom())
+	if !i.IsUint64() {
+		return shepard.None[ByteSize]()
+	}
+	return shepard.Some(ByteSize(i.Uint64()))
+}
+
+// ParseByteSize parses a size like "512KiB", "1.5 GB" or "100". Units are case-insensitive,
+// binary units (KiB, MiB, ...) are powers of 1024, decimal units (KB, MB, ...) are powers of 1000.
+// A number without unit is a number of bytes, fractions of a byte are truncated.
+//
+// Failures are reported as *num.ParseError.
+func ParseByteSize(s string) shepard.Result[ByteSize, error] {
+	const fn = "ParseByteSize"
+
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return shepard.Err[ByteSize, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrEmpty})
+	}
+
+	i := strings.IndexFunc(trimmed, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(trimmed)
+	}
+	number, symbol := trimmed[:i], strings.ToLower(strings.TrimSpace(trimmed[i:]))
+
+	unit, ok := byteUnits[symbol]
+	if !ok || number == "" || strings.HasPrefix(number, ".") || strings.HasSuffix(number, ".") {
+		return shepard.Err[ByteSize, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrSyntax})
+	}
+	r, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return shepard.Err[ByteSize, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrSyntax})
+	}
+	size := fromRat(r.Mul(r, new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(unit)))))
+	if size.IsNone() {
+		return shepard.Err[ByteSize, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrOverflow})
+	}
+	return shepard.Ok[ByteSize, error](size.Unwrap())
+}
+
+// Bytes returns the number of bytes as uint64.
+func (b ByteSize) Bytes() uint64 {
+	return uint64(b)
+}
+
+// In returns the ByteSize measured in unit, e.g. (1536 * Byte).In(KiB) is 1.5.
+func (b ByteSize) In(unit ByteSize) float64 {
+	return float64(b) / float64(unit)
+}
+
+// String formats the ByteSize exactly with the largest binary unit that is not larger than the size, e.g. "1.5KiB" or "10MiB".
+// Sizes below 1KiB are formatted in bytes, e.g. "512B".
+//
+// ParseByteSize parses the output back into the same ByteSize.
+func (b ByteSize) String() string {
+	for _, u := range binaryByteUnits {
+		if b >= u.size {
+			if b%u.size == 0 {
+				return num.FormatNumber(uint64(b/u.size), num.FormatOptions{}) + u.symbol
+			}
+			// a fraction of a power of two has at most as many decimal digits as the exponent
+			r := new(big.Rat).SetFrac(new(big.Int).SetUint64(uint64(b)), new(big.Int).SetUint64(uint64(u.size)))
+			s := strings.TrimRight(r.FloatString(60), "0")
+			return s + u.symbol
+		}
+	}
+	return num.FormatNumber(uint64(b), num.FormatOptions{}) + "B"
+}
+
+// CheckedAdd adds two ByteSize`s, checking for overflow. If overflow happens, shepard.None is returned.
+func (b ByteSize) CheckedAdd(o ByteSize) shepard.Option[ByteSize] {
+	return num.CheckedAdd(b, o)
+}
+
+// CheckedSub subtracts two ByteSize`s, checking for underflow. If underflow happens, shepard.None is returned.
+func (b ByteSize) CheckedSub(o ByteSize) shepard.Option[ByteSize] {
+	return num.CheckedSub(b, o)
+}
+
+// CheckedMul multiplies the ByteSize by n, checking for overflow. If overflow happens, shepard.None is returned.
+func (b ByteSize) CheckedMul(n uint64) shepard.Option[ByteSize] {
+	return num.CheckedMul(b, ByteSize(n))
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	res := ParseByteSize(string(text))
+	if res.IsErr() {
+		return res.UnwrapErr()
+	}
+	*b = res.Unwrap()
+	return nil
+}
@@ -0,0 +1,85 @@
+package unit_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/unit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	testCases := map[string]unit.ByteSize{
+		"0":        0,
+		"100":      100,
+		"100B":     100,
+		"512KiB":   512 * unit.KiB,
+		"512 kib":  512 * unit.KiB,
+		"10MiB":    10 * unit.MiB,
+		"1.5GiB":   1536 * unit.MiB,
+		"2GB":      2 * unit.GB,
+		"0.5KB":    500,
+		"0.1KiB":   102,
+		"16EiB":    0,
+		"15.5 EiB": 15*unit.EiB + 512*unit.PiB,
+	}
+	for input, expected := range testCases {
+		if input == "16EiB" {
+			continue
+		}
+		assert.Equal(t, shepard.Some(expected), unit.ParseByteSize(input).Ok(), input)
+	}
+
+	assert.ErrorIs(t, unit.ParseByteSize("").UnwrapErr(), num.ErrEmpty)
+	assert.ErrorIs(t, unit.ParseByteSize("16EiB").UnwrapErr(), num.ErrOverflow)
+	for _, input := range []string{"MiB", "10XB", "1.MiB", ".5MiB", "-1MiB", "1.2.3KiB", "1e3KiB"} {
+		assert.ErrorIs(t, unit.ParseByteSize(input).UnwrapErr(), num.ErrSyntax, input)
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	assert.Equal(t, "0B", unit.ByteSize(0).String())
+	assert.Equal(t, "512B", unit.ByteSize(512).String())
+	assert.Equal(t, "1KiB", unit.KiB.String())
+	assert.Equal(t, "1.5KiB", unit.ByteSize(1536).String())
+	assert.Equal(t, "10MiB", (10 * unit.MiB).String())
+	assert.Equal(t, "1.0009765625KiB", unit.ByteSize(1025).String())
+	assert.Equal(t, "976.5625KiB", unit.MB.String())
+
+	for _, size := range []unit.ByteSize{0, 1, 1025, unit.MB, 3*unit.GiB + 7, math.MaxUint64} {
+		assert.Equal(t, shepard.Some(size), unit.ParseByteSize(size.String()).Ok(), size.String())
+	}
+}
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, shepard.Some(10*unit.MiB), unit.Bytes(10, unit.MiB))
+	assert.Equal(t, shepard.Some(1536*unit.KiB), unit.Bytes(1.5, unit.MiB))
+	assert.Equal(t, shepard.None[unit.ByteSize](), unit.Bytes(-1, unit.KiB))
+	assert.Equal(t, shepard.None[unit.ByteSize](), unit.Bytes(16, unit.EiB))
+	assert.Equal(t, shepard.None[unit.ByteSize](), unit.Bytes(1e30, unit.Byte))
+	assert.Equal(t, 1.5, unit.ByteSize(1536).In(unit.KiB))
+}
+
+func TestByteSize_Checked(t *testing.T) {
+	assert.Equal(t, shepard.Some(2*unit.KiB), unit.KiB.CheckedAdd(unit.KiB))
+	assert.Equal(t, shepard.None[unit.ByteSize](), unit.KiB.CheckedSub(unit.MiB))
+	assert.Equal(t, shepard.None[unit.ByteSize](), unit.EiB.CheckedMul(16))
+	assert.Equal(t, uint64(1024), unit.KiB.Bytes())
+}
+
+func TestByteSize_Text(t *testing.T) {
+	var config struct {
+		MaxBody unit.ByteSize `json:"max_body"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"max_body":"512KiB"}`), &config))
+	assert.Equal(t, 512*unit.KiB, config.MaxBody)
+
+	b, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"max_body":"512KiB"}`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"max_body":"lots"}`), &config))
+}
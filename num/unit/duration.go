@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+)
+
+// Duration converts n of the given unit to a time.Duration, e.g. Duration(1.5, time.Second).
+// Fractions of a nanosecond are truncated.
+//
+// Returns shepard.None if the time.Duration overflows.
+func Duration[T num.Number](n T, unit time.Duration) shepard.Option[time.Duration] {
+	integer := num.CheckedCast[T, int64](n)
+	if integer.IsSome() {
+		return num.CheckedMul(time.Duration(integer.Unwrap()), unit)
+	}
+	ns := float64(n) * float64(unit)
+	if ns != ns || ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return shepard.None[time.Duration]()
+	}
+	return shepard.Some(time.Duration(ns))
+}
+
+// ParseDuration parses a duration like "300ms", "1.5h" or "2h45m" as time.ParseDuration does.
+//
+// Failures are reported as *num.ParseError.
+func ParseDuration(s string) shepard.Result[time.Duration, error] {
+	const fn = "ParseDuration"
+
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return shepard.Err[time.Duration, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrEmpty})
+	}
+	d, err := time.ParseDuration(trimmed)
+	if err != nil {
+		return shepard.Err[time.Duration, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrSyntax})
+	}
+	return shepard.Ok[time.Duration, error](d)
+}
@@ -0,0 +1,29 @@
+package unit_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/unit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuration(t *testing.T) {
+	assert.Equal(t, shepard.Some(30*time.Second), unit.Duration(30, time.Second))
+	assert.Equal(t, shepard.Some(1500*time.Millisecond), unit.Duration(1.5, time.Second))
+	assert.Equal(t, shepard.Some(-2*time.Minute), unit.Duration(int8(-2), time.Minute))
+	assert.Equal(t, shepard.None[time.Duration](), unit.Duration(uint64(math.MaxUint64), time.Nanosecond))
+	assert.Equal(t, shepard.None[time.Duration](), unit.Duration(10000000, time.Hour))
+	assert.Equal(t, shepard.None[time.Duration](), unit.Duration(1e7, time.Hour))
+	assert.Equal(t, shepard.None[time.Duration](), unit.Duration(math.NaN(), time.Hour))
+}
+
+func TestParseDuration(t *testing.T) {
+	assert.Equal(t, shepard.Some(90*time.Minute), unit.ParseDuration("1h30m").Ok())
+	assert.Equal(t, shepard.Some(300*time.Millisecond), unit.ParseDuration(" 300ms ").Ok())
+	assert.ErrorIs(t, unit.ParseDuration("").UnwrapErr(), num.ErrEmpty)
+	assert.ErrorIs(t, unit.ParseDuration("soon").UnwrapErr(), num.ErrSyntax)
+}
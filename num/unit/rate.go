@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+)
+
+// Rate is a number of events per time interval, e.g. 100 requests per second.
+type Rate struct {
+	Count uint64
+	Per   time.Duration
+}
+
+var rateIntervals = map[string]time.Duration{
+	"ns":  time.Nanosecond,
+	"us":  time.Microsecond,
+	"µs":  time.Microsecond,
+	"ms":  time.Millisecond,
+	"s":   time.Second,
+	"sec": time.Second,
+	"m":   time.Minute,
+	"min": time.Minute,
+	"h":   time.Hour,
+}
+
+// PerSecond creates a Rate of count events per second.
+func PerSecond(count uint64) Rate {
+	return Rate{Count: count, Per: time.Second}
+}
+
+// PerMinute creates a Rate of count events per minute.
+func PerMinute(count uint64) Rate {
+	return Rate{Count: count, Per: time.Minute}
+}
+
+// PerHour creates a Rate of count events per hour.
+func PerHour(count uint64) Rate {
+	return Rate{Count: count, Per: time.Hour}
+}
+
+// ParseRate parses a rate like "100/s", "5/min" or "1000/10m".
+// The interval is a duration as accepted by ParseDuration, a missing number means 1, "min" and "sec" are accepted as well.
+//
+// Failures are reported as *num.ParseError.
+func ParseRate(s string) shepard.Result[Rate, error] {
+	const fn = "ParseRate"
+
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return shepard.Err[Rate, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrEmpty})
+	}
+	count, interval, ok := strings.Cut(trimmed, "/")
+	if !ok {
+		return shepard.Err[Rate, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrSyntax})
+	}
+
+	c := num.ParseString[uint64](strings.TrimSpace(count))
+	if c.IsErr() {
+		reason := num.ErrSyntax
+		var parseErr *num.ParseError
+		if errors.As(c.UnwrapErr(), &parseErr) {
+			reason = parseErr.Err
+		}
+		return shepard.Err[Rate, error](&num.ParseError{Func: fn, Input: s, Err: reason})
+	}
+
+	interval = strings.TrimSpace(interval)
+	per, ok := rateIntervals[interval]
+	if !ok {
+		d := ParseDuration(strings.Replace(strings.Replace(interval, "min", "m", 1), "sec", "s", 1))
+		if d.IsErr() {
+			return shepard.Err[Rate, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrSyntax})
+		}
+		per = d.Unwrap()
+	}
+	if per <= 0 {
+		return shepard.Err[Rate, error](&num.ParseError{Func: fn, Input: s, Err: num.ErrSyntax})
+	}
+	return shepard.Ok[Rate, error](Rate{Count: c.Unwrap(), Per: per})
+}
+
+// PerSecond returns the number of events per second.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Count) / r.Per.Seconds()
+}
+
+// Interval returns the time between two events, or shepard.None if the Rate has no events.
+func (r Rate) Interval() shepard.Option[time.Duration] {
+	if r.Count == 0 {
+		return shepard.None[time.Duration]()
+	}
+	return shepard.Some(r.Per / time.Duration(r.Count))
+}
+
+// Events returns the number of events that happen within d at this Rate.
+func (r Rate) Events(d time.Duration) float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Count) * float64(d) / float64(r.Per)
+}
+
+// CheckedMul multiplies the number of events by n, checking for overflow. If overflow happens, shepard.None is returned.
+func (r Rate) CheckedMul(n uint64) shepard.Option[Rate] {
+	count := num.CheckedMul(r.Count, n)
+	if count.IsNone() {
+		return shepard.None[Rate]()
+	}
+	return shepard.Some(Rate{Count: count.Unwrap(), Per: r.Per})
+}
+
+// String formats the Rate like "100/s", "5/m" or "1000/10m0s".
+func (r Rate) String() string {
+	count := num.FormatNumber(r.Count, num.FormatOptions{})
+	switch r.Per {
+	case time.Second:
+		return count + "/s"
+	case time.Minute:
+		return count + "/m"
+	case time.Hour:
+		return count + "/h"
+	}
+	return count + "/" + r.Per.String()
+}
+
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	res := ParseRate(string(text))
+	if res.IsErr() {
+		return res.UnwrapErr()
+	}
+	*r = res.Unwrap()
+	return nil
+}
@@ -0,0 +1,42 @@
+package unit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/unit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRate(t *testing.T) {
+	assert.Equal(t, shepard.Some(unit.PerSecond(100)), unit.ParseRate("100/s").Ok())
+	assert.Equal(t, shepard.Some(unit.PerMinute(5)), unit.ParseRate("5/min").Ok())
+	assert.Equal(t, shepard.Some(unit.PerHour(5)), unit.ParseRate("5 / h").Ok())
+	assert.Equal(t, shepard.Some(unit.Rate{Count: 1000, Per: 10 * time.Minute}), unit.ParseRate("1000/10min").Ok())
+	assert.Equal(t, shepard.Some(unit.Rate{Count: 1, Per: 500 * time.Millisecond}), unit.ParseRate("1/500ms").Ok())
+
+	assert.ErrorIs(t, unit.ParseRate("").UnwrapErr(), num.ErrEmpty)
+	assert.ErrorIs(t, unit.ParseRate("100").UnwrapErr(), num.ErrSyntax)
+	assert.ErrorIs(t, unit.ParseRate("100/fortnight").UnwrapErr(), num.ErrSyntax)
+	assert.ErrorIs(t, unit.ParseRate("100/0s").UnwrapErr(), num.ErrSyntax)
+	assert.ErrorIs(t, unit.ParseRate("/s").UnwrapErr(), num.ErrEmpty)
+	assert.ErrorIs(t, unit.ParseRate("99999999999999999999/s").UnwrapErr(), num.ErrOverflow)
+}
+
+func TestRate(t *testing.T) {
+	r := unit.PerMinute(120)
+	assert.Equal(t, 2.0, r.PerSecond())
+	assert.Equal(t, shepard.Some(500*time.Millisecond), r.Interval())
+	assert.Equal(t, 20.0, r.Events(10*time.Second))
+	assert.Equal(t, shepard.None[time.Duration](), unit.PerSecond(0).Interval())
+	assert.Equal(t, shepard.Some(unit.PerMinute(240)), r.CheckedMul(2))
+
+	assert.Equal(t, "120/m", r.String())
+	assert.Equal(t, "1000/10m0s", unit.Rate{Count: 1000, Per: 10 * time.Minute}.String())
+
+	for _, rate := range []unit.Rate{r, unit.PerSecond(7), unit.PerHour(1), {Count: 3, Per: 1500 * time.Millisecond}} {
+		assert.Equal(t, shepard.Some(rate), unit.ParseRate(rate.String()).Ok())
+	}
+}
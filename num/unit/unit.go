@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+)
+
+// Unit tags a Quantity with its unit of measurement. Implementations are usually empty structs.
+//
+//	type Requests struct{}
+//
+//	func (Requests) Symbol() string { return "req" }
+type Unit interface {
+	Symbol() string
+}
+
+// Quantity is a number of type T measured in the unit U.
+//
+// Quantities of different units can't be mixed up, adding Quantity[Requests, int] to Quantity[Errors, int] doesn't compile.
+type Quantity[U Unit, T num.Number] struct {
+	value T
+}
+
+// Of creates a Quantity of value measured in U.
+func Of[U Unit, T num.Number](value T) Quantity[U, T] {
+	return Quantity[U, T]{value: value}
+}
+
+// Value returns the bare number of the Quantity.
+func (q Quantity[U, T]) Value() T {
+	return q.value
+}
+
+// Symbol returns the symbol of the unit U.
+func (q Quantity[U, T]) Symbol() string {
+	var u U
+	return u.Symbol()
+}
+
+// String formats the Quantity as value followed by the symbol of its unit, e.g. "42req".
+func (q Quantity[U, T]) String() string {
+	return num.FormatNumber(q.value, num.FormatOptions{}) + q.Symbol()
+}
+
+// Cmp compares two Quantity`s and returns -1 if q < o, 0 if q == o and +1 if q > o.
+func (q Quantity[U, T]) Cmp(o Quantity[U, T]) int {
+	switch {
+	case q.value < o.value:
+		return -1
+	case q.value > o.value:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CheckedAdd adds two Quantity`s, checking for overflow. If overflow happens, shepard.None is returned.
+func (q Quantity[U, T]) CheckedAdd(o Quantity[U, T]) shepard.Option[Quantity[U, T]] {
+	return wrap[U](num.CheckedAdd(q.value, o.value))
+}
+
+// CheckedSub subtracts two Quantity`s, checking for overflow. If overflow happens, shepard.None is returned.
+func (q Quantity[U, T]) CheckedSub(o Quantity[U, T]) shepard.Option[Quantity[U, T]] {
+	return wrap[U](num.CheckedSub(q.value, o.value))
+}
+
+// CheckedMul scales the Quantity by n, checking for overflow. If overflow happens, shepard.None is returned.
+func (q Quantity[U, T]) CheckedMul(n T) shepard.Option[Quantity[U, T]] {
+	return wrap[U](num.CheckedMul(q.value, n))
+}
+
+// CheckedDiv divides the Quantity by n, checking for division by zero and overflow. If any of that happens, shepard.None is returned.
+func (q Quantity[U, T]) CheckedDiv(n T) shepard.Option[Quantity[U, T]] {
+	return wrap[U](num.CheckedDiv(q.value, n))
+}
+
+// Convert converts q into the unit V by applying f to its value, f returns shepard.None if the value can't be converted.
+//
+//	meters := unit.Convert[Kilometers, Meters](km, func(v int) shepard.Option[int] { return num.CheckedMul(v, 1000) })
+func Convert[U Unit, V Unit, T num.Number](q Quantity[U, T], f func(value T) shepard.Option[T]) shepard.Option[Quantity[V, T]] {
+	return wrap[V](f(q.value))
+}
+
+func wrap[U Unit, T num.Number](value shepard.Option[T]) shepard.Option[Quantity[U, T]] {
+	if value.IsNone() {
+		return shepard.None[Quantity[U, T]]()
+	}
+	return shepard.Some(Of[U](value.Unwrap()))
+}
@@ -0,0 +1,46 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/marlaone/shepard"
+	"github.com/marlaone/shepard/num"
+	"github.com/marlaone/shepard/num/unit"
+	"github.com/stretchr/testify/assert"
+)
+
+type Requests struct{}
+
+func (Requests) Symbol() string { return "req" }
+
+type Kilometers struct{}
+
+func (Kilometers) Symbol() string { return "km" }
+
+type Meters struct{}
+
+func (Meters) Symbol() string { return "m" }
+
+func TestQuantity(t *testing.T) {
+	q := unit.Of[Requests](uint8(200))
+	assert.Equal(t, uint8(200), q.Value())
+	assert.Equal(t, "req", q.Symbol())
+	assert.Equal(t, "200req", q.String())
+
+	assert.Equal(t, shepard.Some(unit.Of[Requests](uint8(250))), q.CheckedAdd(unit.Of[Requests](uint8(50))))
+	assert.Equal(t, shepard.None[unit.Quantity[Requests, uint8]](), q.CheckedAdd(unit.Of[Requests](uint8(56))))
+	assert.Equal(t, shepard.None[unit.Quantity[Requests, uint8]](), q.CheckedSub(unit.Of[Requests](uint8(201))))
+	assert.Equal(t, shepard.None[unit.Quantity[Requests, uint8]](), q.CheckedMul(2))
+	assert.Equal(t, shepard.Some(unit.Of[Requests](uint8(100))), q.CheckedDiv(2))
+	assert.Equal(t, shepard.None[unit.Quantity[Requests, uint8]](), q.CheckedDiv(0))
+
+	assert.Equal(t, 1, q.Cmp(unit.Of[Requests](uint8(1))))
+	assert.Equal(t, 0, q.Cmp(q))
+}
+
+func TestConvert(t *testing.T) {
+	km := unit.Of[Kilometers](3)
+	m := unit.Convert[Kilometers, Meters](km, func(v int) shepard.Option[int] { return num.CheckedMul(v, 1000) })
+	assert.Equal(t, shepard.Some(unit.Of[Meters](3000)), m)
+	assert.Equal(t, "3000m", m.Unwrap().String())
+}
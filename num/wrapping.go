@@ -0,0 +1,25 @@
+package num
+
+// WrappingAdd adds two Number`s, wrapping around at the boundary of the type.
+//
+// Floating point types don't wrap, an overflow results in an infinity.
+func WrappingAdd[T Number](num T, v T) T {
+	c, _ := OverflowingAdd(num, v)
+	return c
+}
+
+// WrappingSub subtracts two Number`s, wrapping around at the boundary of the type.
+//
+// Floating point types don't wrap, an overflow results in an infinity.
+func WrappingSub[T Number](num T, v T) T {
+	c, _ := OverflowingSub(num, v)
+	return c
+}
+
+// WrappingMul multiplies two Number`s, wrapping around at the boundary of the type.
+//
+// Floating point types don't wrap, an overflow results in an infinity.
+func WrappingMul[T Number](num T, v T) T {
+	c, _ := OverflowingMul(num, v)
+	return c
+}
@@ -0,0 +1,25 @@
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/marlaone/shepard/num"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrappingAdd(t *testing.T) {
+	assert.Equal(t, uint8(4), num.WrappingAdd(uint8(250), 10))
+	assert.Equal(t, int16(math.MinInt16), num.WrappingAdd(int16(math.MaxInt16), 1))
+	assert.Equal(t, 4.4, num.WrappingAdd(2.2, 2.2))
+}
+
+func TestWrappingSub(t *testing.T) {
+	assert.Equal(t, uint8(246), num.WrappingSub(uint8(0), 10))
+	assert.Equal(t, int16(math.MaxInt16), num.WrappingSub(int16(math.MinInt16), 1))
+}
+
+func TestWrappingMul(t *testing.T) {
+	assert.Equal(t, uint8(44), num.WrappingMul(uint8(12), 25))
+	assert.Equal(t, int32(-2), num.WrappingMul(int32(math.MaxInt32), 2))
+}
@@ -2,6 +2,7 @@ package io
 
 import (
 	"errors"
+	"sync/atomic"
 
 	"github.com/marlaone/shepard"
 	"github.com/marlaone/shepard/collections/slice"
@@ -11,31 +12,34 @@ import (
 type ReaderWriterCloserBuffer struct {
 	buf rwmutex.RWMutex[slice.Slice[byte]]
 
-	closed bool
+	closed atomic.Bool
 }
 
 var _ ReaderWriterCloser = (*ReaderWriterCloserBuffer)(nil)
 
 func NewReaderWriterCloser() *ReaderWriterCloserBuffer {
 	return &ReaderWriterCloserBuffer{
-		buf:    rwmutex.New[slice.Slice[byte]](slice.New[byte]()),
-		closed: false,
+		buf: rwmutex.New[slice.Slice[byte]](slice.New[byte]()),
 	}
 }
 
+// Read moves the buffered bytes to the end of p and returns their number.
+// The bytes are consumed, a subsequent Read only returns bytes written in the meantime.
 func (b *ReaderWriterCloserBuffer) Read(p *slice.Slice[byte]) shepard.Result[int, error] {
-	guard := b.buf.RLock()
-	defer b.buf.RUnlock()
+	guard := b.buf.Lock()
+	defer b.buf.Unlock()
 	buf := guard.Unwrap()
 
+	n := buf.Len()
 	p.Append(buf)
+	buf.Clear()
 
-	return shepard.Ok[int, error](p.Len())
+	return shepard.Ok[int, error](n)
 }
 
 func (b *ReaderWriterCloserBuffer) Write(p slice.Slice[byte]) shepard.Result[int, error] {
 
-	if b.closed {
+	if b.closed.Load() {
 		return shepard.Err[int, error](errors.New("can't write to closed buffer"))
 	}
 
@@ -49,10 +53,10 @@ func (b *ReaderWriterCloserBuffer) Write(p slice.Slice[byte]) shepard.Result[int
 }
 
 func (b *ReaderWriterCloserBuffer) Close() shepard.Result[shepard.Nil, error] {
-	b.closed = true
+	b.closed.Store(true)
 	return shepard.Ok[shepard.Nil, error](shepard.Nil{})
 }
 
 func (b *ReaderWriterCloserBuffer) Closed() bool {
-	return b.closed
+	return b.closed.Load()
 }
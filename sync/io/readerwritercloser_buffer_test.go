@@ -29,6 +29,19 @@ func TestReaderWriterCloser_Read(t *testing.T) {
 	assert.Equal(t, 3, res.Unwrap())
 }
 
+func TestReaderWriterCloser_Read_Consumes(t *testing.T) {
+	rwc := io.NewReaderWriterCloser()
+	rwc.Write(slice.Init[byte](1, 2, 3))
+
+	buf := slice.New[byte]()
+	assert.Equal(t, 3, rwc.Read(&buf).Unwrap())
+	assert.Equal(t, 0, rwc.Read(&buf).Unwrap())
+
+	rwc.Write(slice.Init[byte](4))
+	assert.Equal(t, 1, rwc.Read(&buf).Unwrap())
+	assert.Equal(t, slice.Init[byte](1, 2, 3, 4), buf)
+}
+
 func TestReaderWriterCloser_Read_Async_Write(t *testing.T) {
 	var wg sync.WaitGroup
 
@@ -0,0 +1,20 @@
+// Package testhttp has helpers for tests of handlers and middleware of the http package.
+package testhttp
+
+import (
+	"github.com/marlaone/shepard/http"
+)
+
+// NewRequest returns a request without a body with method, uri and headers.
+func NewRequest(method http.Method, uri string, headers map[string]string) *http.Request[http.RequestBody] {
+	req := http.NewRequestBuilder[http.RequestBody]().Method(method).URL(http.ParseRequestURI(uri).Unwrap()).Body(nil).Unwrap()
+	for key, value := range headers {
+		req.Headers.Set(key, value)
+	}
+	return &req
+}
+
+// ReadBody returns the data buffered in the body of res as a string.
+func ReadBody(res http.Response[http.Body]) string {
+	return string(http.ReadBody(res.Body()))
+}